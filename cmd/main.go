@@ -1,64 +1,36 @@
 package main
 
 import (
-	"archive/zip"
+	"context"
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
+	"log/slog"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
-	amqp "github.com/rabbitmq/amqp091-go"
-	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/infrastructure"
+	"github.com/vitovidale/video-processor-service/infrastructure/migrations"
+	"github.com/vitovidale/video-processor-service/logging"
+	"github.com/vitovidale/video-processor-service/usecase"
 )
 
 var db *sql.DB
-var rabbitMQConn *amqp.Connection
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+var messageQueue *infrastructure.RabbitMQMessageQueue
 
-func init() {
-    if len(jwtSecret) == 0 {
-        log.Println("WARNING: JWT_SECRET environment variable not set. Using a default secret for development. THIS IS INSECURE FOR PRODUCTION!")
-        jwtSecret = []byte("supersecretjwtkeythatshouldbeverylongandrandominproduction")
-    }
-}
-
-type Claims struct {
-	Username string `json:"username"`
-	UserID   int    `json:"user_id"`
-	jwt.RegisteredClaims
-}
-
-type VideoProcessingMessage struct {
-	UserID            int    `json:"user_id"`
-	VideoPath         string `json:"video_path"`
-	OriginalFilename  string `json:"original_filename"`
-	ProcessingStarted time.Time `json:"processing_started"`
-	VideoStatusID     int    `json:"video_status_id"`
-}
+// devJWTSecret is only accepted when AUTH_DEV_MODE=true.
+const devJWTSecret = "supersecretjwtkeythatshouldbeverylongandrandominproduction"
 
-type VideoStatusResponse struct {
-    ID               int       `json:"id"`
-    OriginalFilename string    `json:"original_filename"`
-    Status           string    `json:"status"`
-    ProcessedFilePath string   `json:"processed_file_path,omitempty"`
-    ErrorMessage     string    `json:"error_message,omitempty"`
-    CreatedAt        time.Time `json:"created_at"`
-    UpdatedAt        time.Time `json:"updated_at"`
-}
+// devShareLinkSecret signs share URLs when AUTH_DEV_MODE=true and
+// SHARE_LINK_SECRET is unset.
+const devShareLinkSecret = "development-share-link-secret-do-not-use"
 
-func failOnError(err error, msg string) {
-	if err != nil {
-		log.Fatalf("%s: %s", msg, err)
-	}
+func init() {
+	slog.SetDefault(logging.New(os.Stdout, logging.ParseLevel(os.Getenv("LOG_LEVEL"))))
 }
 
 func initDB() {
@@ -92,14 +64,14 @@ func initDB() {
 		if err == nil {
 			err = db.Ping()
 			if err == nil {
-				fmt.Println("Conexão com PostgreSQL estabelecida com sucesso!")
+				slog.Info("connected to PostgreSQL")
 				return
 			}
 		}
-		log.Printf("Tentando conectar ao DB novamente em 5s... (%d/5)", i+1)
+		slog.Warn("database not reachable, retrying in 5s", "attempt", i+1, "max_attempts", 5, "error", err)
 		time.Sleep(5 * time.Second)
 	}
-	log.Fatalf("Falha crítica: Não foi possível conectar ao banco de dados após várias tentativas: %v", err)
+	fatal("could not connect to the database", err)
 }
 
 func initRabbitMQ() {
@@ -121,437 +93,595 @@ func initRabbitMQ() {
 	}
 
 	connString := fmt.Sprintf("amqp://%s:%s@%s:%s/", rabbitMQUser, rabbitMQPass, rabbitMQHost, rabbitMQPort)
+	messageQueue = infrastructure.NewRabbitMQMessageQueue(connString)
 
 	var err error
 	for i := 0; i < 5; i++ {
-		rabbitMQConn, err = amqp.Dial(connString)
+		err = messageQueue.Connect()
 		if err == nil {
-			fmt.Println("Conexão com RabbitMQ estabelecida com sucesso!")
+			slog.Info("connected to RabbitMQ")
 			return
 		}
-		log.Printf("Tentando conectar ao RabbitMQ novamente em 5s... (%d/5)", i+1)
+		slog.Warn("RabbitMQ not reachable, retrying in 5s", "attempt", i+1, "max_attempts", 5, "error", err)
 		time.Sleep(5 * time.Second)
 	}
-	log.Fatalf("Falha crítica: Não foi possível conectar ao RabbitMQ após várias tentativas: %v", err)
+	fatal("could not connect to RabbitMQ", err)
 }
 
-func authMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
-		if tokenString == "" {
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
+// fatal logs msg with err and exits; slog has no Fatal level.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
 
-		if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
-			tokenString = tokenString[7:]
-		} else {
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
+// jwtVerifier builds the token verifier from the environment:
+//
+//	JWT_KEYS      comma-separated kid:secret HS256 keys, for rotation
+//	JWT_SECRET    HS256 key for tokens without a kid header
+//	JWT_JWKS_URL  JWKS endpoint holding RS256/ES256 public keys
+//	JWT_ISSUER    required iss claim
+//	JWT_AUDIENCE  required aud claim (default video-processor-service)
+//
+// Without any key or issuer the service refuses to start, unless
+// AUTH_DEV_MODE=true, which falls back to a well-known development secret.
+func jwtVerifier() (*infrastructure.JWTVerifier, error) {
+	devMode := os.Getenv("AUTH_DEV_MODE") == "true"
+
+	keys, err := infrastructure.ParseHMACKeys(os.Getenv("JWT_KEYS"))
+	if err != nil {
+		return nil, fmt.Errorf("JWT_KEYS: %w", err)
+	}
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		keys[""] = []byte(secret)
+	}
+
+	var jwks *infrastructure.JWKSKeySet
+	if url := os.Getenv("JWT_JWKS_URL"); url != "" {
+		jwks = infrastructure.NewJWKSKeySet(url)
+		if err := jwks.Refresh(context.Background()); err != nil {
+			// Not fatal: keys are fetched again on the first token.
+			slog.Warn("initial JWKS fetch failed", "error", err)
 		}
+	}
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
+	issuer := os.Getenv("JWT_ISSUER")
+	audience := os.Getenv("JWT_AUDIENCE")
+	if audience == "" {
+		audience = "video-processor-service"
+	}
 
-		if err != nil || !token.Valid {
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
+	if devMode {
+		slog.Warn("AUTH_DEV_MODE is on; never enable it in production")
+		if len(keys) == 0 && jwks == nil {
+			keys[""] = []byte(devJWTSecret)
+		}
+		if issuer == "" {
+			issuer = "video-processor-dev"
+		}
+	} else {
+		for kid, key := range keys {
+			if len(key) < infrastructure.MinHMACKeyLength || string(key) == devJWTSecret {
+				return nil, fmt.Errorf("HMAC key %q must be a random secret of at least %d bytes", kid, infrastructure.MinHMACKeyLength)
+			}
+		}
+		if len(keys) == 0 && jwks == nil {
+			return nil, errors.New("set JWT_SECRET, JWT_KEYS or JWT_JWKS_URL (or AUTH_DEV_MODE=true for local development)")
+		}
+		if issuer == "" {
+			return nil, errors.New("JWT_ISSUER is required")
 		}
-
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Next()
 	}
+	return infrastructure.NewJWTVerifier(issuer, audience, keys, jwks)
 }
 
-func uploadVideo(c *gin.Context) {
-	userID := c.MustGet("user_id").(int)
-
-	file, err := c.FormFile("video")
+// refreshTokenHandlers offers refresh tokens when REFRESH_TOKENS=true. Access
+// tokens are then minted by this service with the HMAC key named by
+// JWT_SIGNING_KID (empty means JWT_SECRET), valid for ACCESS_TOKEN_TTL
+// (default 15m); a session lasts REFRESH_TOKEN_TTL (default 720h). Returns
+// nil when refresh tokens are off.
+func refreshTokenHandlers(verifier *infrastructure.JWTVerifier) (*infrastructure.RefreshTokenHandlers, error) {
+	if os.Getenv("REFRESH_TOKENS") != "true" {
+		return nil, nil
+	}
+	signer, err := infrastructure.NewJWTSigner(verifier, os.Getenv("JWT_SIGNING_KID"), durationFromEnv("ACCESS_TOKEN_TTL", infrastructure.DefaultAccessTokenTTL))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to get video file: %v", err)})
-		return
+		return nil, err
 	}
+	repo := infrastructure.NewPostgresRefreshTokenRepository(db)
+	return infrastructure.NewRefreshTokenHandlers(
+		&usecase.IssueRefreshTokenUseCase{Repo: repo, TTL: durationFromEnv("REFRESH_TOKEN_TTL", usecase.DefaultRefreshTokenTTL)},
+		&usecase.RefreshAccessTokenUseCase{Repo: repo, Signer: signer},
+		&usecase.RevokeRefreshTokenUseCase{Repo: repo},
+		&usecase.RevokeUserRefreshTokensUseCase{Repo: repo},
+	), nil
+}
 
-	uploadDir := "./uploads"
-	if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
-		os.Mkdir(uploadDir, 0755)
+// shareLinkSigner reads SHARE_LINK_SECRET, the HMAC key for share URLs. All
+// replicas must share it, and changing it invalidates every issued link.
+// AUTH_DEV_MODE=true falls back to a development secret.
+func shareLinkSigner() (*usecase.ShareLinkSigner, error) {
+	secret := os.Getenv("SHARE_LINK_SECRET")
+	if secret == "" && os.Getenv("AUTH_DEV_MODE") == "true" {
+		secret = devShareLinkSecret
+	} else if len(secret) < infrastructure.MinHMACKeyLength || secret == devShareLinkSecret {
+		return nil, fmt.Errorf("SHARE_LINK_SECRET must be a random secret of at least %d bytes", infrastructure.MinHMACKeyLength)
 	}
+	return &usecase.ShareLinkSigner{Secret: []byte(secret)}, nil
+}
 
-	uniqueFilename := fmt.Sprintf("%d_%s_%d%s", userID, time.Now().Format("20060102150405"), time.Now().UnixNano(), filepath.Ext(file.Filename))
-	filePath := filepath.Join(uploadDir, uniqueFilename)
-
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to save video file: %v", err)})
-		return
+// durationFromEnv parses a time.Duration such as "90s" or "24h" from the
+// named variable, falling back to def when unset or invalid.
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
 	}
-
-	query := `INSERT INTO video_processing_statuses (user_id, video_original_filename, status) VALUES ($1, $2, $3) RETURNING id`
-	var videoStatusID int
-	err = db.QueryRow(query, userID, file.Filename, "PENDING").Scan(&videoStatusID)
+	d, err := time.ParseDuration(value)
 	if err != nil {
-		log.Printf("Error inserting initial video status: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record video status"})
-		return
+		slog.Warn("invalid duration, using default", "variable", name, "value", value, "default", def.String())
+		return def
 	}
+	return d
+}
 
-	message := VideoProcessingMessage{
-		UserID:            userID,
-		VideoPath:         filePath,
-		OriginalFilename:  file.Filename,
-		ProcessingStarted: time.Now(),
-		VideoStatusID:     videoStatusID,
+// dedupScope reads DEDUP_SCOPE ("off", "user" or "global"); reuse within the
+// same user is the default.
+func dedupScope() usecase.DedupScope {
+	switch scope := usecase.DedupScope(os.Getenv("DEDUP_SCOPE")); scope {
+	case "":
+		return usecase.DedupScopeUser
+	case usecase.DedupScopeOff, usecase.DedupScopeUser, usecase.DedupScopeGlobal:
+		return scope
+	default:
+		slog.Warn("invalid DEDUP_SCOPE, using default", "value", string(scope), "default", string(usecase.DedupScopeUser))
+		return usecase.DedupScopeUser
 	}
+}
 
-	body, err := json.Marshal(message)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to marshal message: %v", err)})
-		return
+// artifactLayout reads ARTIFACT_LAYOUT ("zip" or "frames"); workers zip
+// frames unless told to keep them for on-the-fly archives.
+func artifactLayout() domain.ArtifactLayout {
+	switch layout := domain.ArtifactLayout(os.Getenv("ARTIFACT_LAYOUT")); layout {
+	case "":
+		return domain.ArtifactLayoutZip
+	case domain.ArtifactLayoutZip, domain.ArtifactLayoutFrames:
+		return layout
+	default:
+		slog.Warn("invalid ARTIFACT_LAYOUT, using default", "value", string(layout), "default", string(domain.ArtifactLayoutZip))
+		return domain.ArtifactLayoutZip
 	}
-
-	ch, err := rabbitMQConn.Channel()
-	failOnError(err, "Failed to open a channel")
-	defer ch.Close()
-
-	q, err := ch.QueueDeclare(
-		"video_processing_queue",
-		true,   // durable
-		false,  // delete when unused
-		false,  // exclusive
-		false,  // no-wait
-		nil,    // arguments
-	)
-	failOnError(err, "Failed to declare a queue")
-
-	err = ch.Publish(
-		"",
-		q.Name,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		})
-	failOnError(err, "Failed to publish a message")
-	log.Printf(" [x] Sent message for video: %s (Status ID: %d)", file.Filename, videoStatusID)
-
-	c.JSON(http.StatusOK, gin.H{"message": "Video uploaded and queued for processing", "filename": file.Filename, "video_status_id": videoStatusID})
 }
 
-func listVideosStatus(c *gin.Context) {
-    userID := c.MustGet("user_id").(int)
-
-    rows, err := db.Query(`SELECT id, video_original_filename, status, processed_file_path, error_message, created_at, updated_at FROM video_processing_statuses WHERE user_id = $1 ORDER BY created_at DESC`, userID)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to query video statuses: %v", err)})
-        return
-    }
-    defer rows.Close()
-
-    var statuses []VideoStatusResponse
-    for rows.Next() {
-        var s VideoStatusResponse
-        var processedFilePath, errorMessage sql.NullString
-        err := rows.Scan(&s.ID, &s.OriginalFilename, &s.Status, &processedFilePath, &errorMessage, &s.CreatedAt, &s.UpdatedAt)
-        if err != nil {
-            log.Printf("Error scanning video status row: %v", err)
-            continue
-        }
-        s.ProcessedFilePath = processedFilePath.String
-        s.ErrorMessage = errorMessage.String
-        statuses = append(statuses, s)
-    }
-
-    if err = rows.Err(); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error iterating over video statuses: %v", err)})
-        return
-    }
-
-    c.JSON(http.StatusOK, statuses)
+// minFreeDisk reads HEALTH_MIN_FREE_DISK_MB, the free space below which the
+// instance reports itself unready (512 MiB by default).
+func minFreeDisk() uint64 {
+	const def = 512
+	mb := uint64(def)
+	if value := os.Getenv("HEALTH_MIN_FREE_DISK_MB"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			slog.Warn("invalid HEALTH_MIN_FREE_DISK_MB, using default", "value", value, "default", def)
+		} else {
+			mb = parsed
+		}
+	}
+	return mb << 20
 }
 
-func downloadProcessedVideo(c *gin.Context) {
-    userID := c.MustGet("user_id").(int)
-    videoIDStr := c.Param("id")
-
-    videoID, err := strconv.Atoi(videoIDStr)
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
-        return
-    }
-
-    var processedFilePath sql.NullString
-    var videoUserID int
-    query := `SELECT processed_file_path, user_id FROM video_processing_statuses WHERE id = $1 AND status = 'COMPLETED'`
-    err = db.QueryRow(query, videoID).Scan(&processedFilePath, &videoUserID)
-    if err != nil {
-        if err == sql.ErrNoRows {
-            c.JSON(http.StatusNotFound, gin.H{"error": "Processed video not found or not completed"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to query video path: %v", err)})
-        return
-    }
-
-    if videoUserID != userID {
-        c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: Video does not belong to this user"})
-        return
-    }
-
-    if !processedFilePath.Valid || processedFilePath.String == "" {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Processed file path not found or invalid"})
-        return
-    }
-
-    filePathToServe := filepath.Join("/app", processedFilePath.String)
-
-    if _, err := os.Stat(filePathToServe); os.IsNotExist(err) {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Processed file not found on server storage"})
-        return
-    }
-
-    c.FileAttachment(filePathToServe, filepath.Base(processedFilePath.String))
+// spriteSettings reads SPRITE_SHEETS=true, which enables sprite sheets, and
+// their shape: SPRITE_GRID ("5x5"), SPRITE_THUMBNAIL_SIZE ("160x90", or just
+// a width to keep the aspect ratio) and SPRITE_INTERVAL ("2s"; every frame
+// by default).
+func spriteSettings() *domain.SpriteSettings {
+	if os.Getenv("SPRITE_SHEETS") != "true" {
+		return nil
+	}
+	settings := domain.DefaultSpriteSettings
+	if value := os.Getenv("SPRITE_GRID"); value != "" {
+		if _, err := fmt.Sscanf(value, "%dx%d", &settings.Columns, &settings.Rows); err != nil || settings.Columns < 1 || settings.Rows < 1 {
+			slog.Warn("invalid SPRITE_GRID, using default", "value", value, "default", "5x5")
+			settings.Columns, settings.Rows = domain.DefaultSpriteSettings.Columns, domain.DefaultSpriteSettings.Rows
+		}
+	}
+	if value := os.Getenv("SPRITE_THUMBNAIL_SIZE"); value != "" {
+		width, height, hasHeight := strings.Cut(value, "x")
+		w, err := strconv.Atoi(width)
+		h := 0
+		if err == nil && hasHeight {
+			h, err = strconv.Atoi(height)
+		}
+		if err != nil || w < 1 || h < 0 {
+			slog.Warn("invalid SPRITE_THUMBNAIL_SIZE, using default", "value", value, "default", domain.DefaultSpriteSettings.Width)
+		} else {
+			settings.Width, settings.Height = w, h
+		}
+	}
+	settings.Interval = durationFromEnv("SPRITE_INTERVAL", 0)
+	return &settings
 }
 
+// intFromEnv parses a positive integer from the named variable, falling
+// back to def when unset or invalid.
+func intFromEnv(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		slog.Warn("invalid number, using default", "variable", name, "value", value, "default", def)
+		return def
+	}
+	return n
+}
 
-func updateVideoStatus(videoStatusID int, status string, processedFilePath, errorMessage string) {
-	query := `UPDATE video_processing_statuses SET status = $1, processed_file_path = $2, error_message = $3, updated_at = NOW() WHERE id = $4`
-	_, err := db.Exec(query, status, processedFilePath, errorMessage, videoStatusID)
+// previewSettings reads PREVIEW_FORMAT ("gif" or "webp"), which enables
+// animated previews, and their shape: PREVIEW_SEGMENTS, PREVIEW_SEGMENT_LENGTH,
+// PREVIEW_WIDTH, PREVIEW_FPS and PREVIEW_MAX_KB.
+func previewSettings() *domain.PreviewSettings {
+	value := os.Getenv("PREVIEW_FORMAT")
+	if value == "" {
+		return nil
+	}
+	format, err := domain.ParsePreviewFormat(value)
 	if err != nil {
-		log.Printf("ERROR: Failed to update video status for ID %d: %v", videoStatusID, err)
-	} else {
-		log.Printf("Video status ID %d updated to: %s", videoStatusID, status)
+		slog.Warn("invalid PREVIEW_FORMAT, previews disabled", "value", value)
+		return nil
+	}
+	def := domain.DefaultPreviewSettings
+	return &domain.PreviewSettings{
+		Format:        format,
+		Segments:      intFromEnv("PREVIEW_SEGMENTS", def.Segments),
+		SegmentLength: durationFromEnv("PREVIEW_SEGMENT_LENGTH", def.SegmentLength),
+		Width:         intFromEnv("PREVIEW_WIDTH", def.Width),
+		FPS:           float64(intFromEnv("PREVIEW_FPS", int(def.FPS))),
+		MaxBytes:      int64(intFromEnv("PREVIEW_MAX_KB", int(def.MaxBytes>>10))) << 10,
 	}
 }
 
-func sendNotification(userID int, originalFilename, status, message string) {
-	log.Printf("NOTIFICAÇÃO para User ID %d - Vídeo '%s' Status: %s. Mensagem: %s", userID, originalFilename, status, message)
+// audioSettings reads AUDIO_FORMAT ("wav", "flac", "mp3" or "opus"), which
+// enables audio extraction and loudness analysis, and AUDIO_WAVEFORM_SIZE
+// ("1200x240").
+func audioSettings() *domain.AudioSettings {
+	value := os.Getenv("AUDIO_FORMAT")
+	if value == "" {
+		return nil
+	}
+	format, err := domain.ParseAudioFormat(value)
+	if err != nil {
+		slog.Warn("invalid AUDIO_FORMAT, audio outputs disabled", "value", value)
+		return nil
+	}
+	settings := domain.DefaultAudioSettings
+	settings.Format = format
+	if value := os.Getenv("AUDIO_WAVEFORM_SIZE"); value != "" {
+		if _, err := fmt.Sscanf(value, "%dx%d", &settings.WaveformWidth, &settings.WaveformHeight); err != nil || settings.WaveformWidth < 1 || settings.WaveformHeight < 1 {
+			slog.Warn("invalid AUDIO_WAVEFORM_SIZE, using default", "value", value, "default", "1200x240")
+			settings.WaveformWidth, settings.WaveformHeight = domain.DefaultAudioSettings.WaveformWidth, domain.DefaultAudioSettings.WaveformHeight
+		}
+	}
+	return &settings
 }
 
+// subtitleFormat reads SUBTITLE_FORMAT ("srt" or "vtt"), which enables
+// extracting subtitle streams; they are always probed.
+func subtitleFormat() domain.SubtitleFormat {
+	value := os.Getenv("SUBTITLE_FORMAT")
+	if value == "" {
+		return ""
+	}
+	format, err := domain.ParseSubtitleFormat(value)
+	if err != nil {
+		slog.Warn("invalid SUBTITLE_FORMAT, subtitle extraction disabled", "value", value)
+		return ""
+	}
+	return format
+}
 
-func startConsumer() {
-	ch, err := rabbitMQConn.Channel()
-	failOnError(err, "Failed to open a channel for consumer")
-	defer ch.Close()
-
-	q, err := ch.QueueDeclare(
-		"video_processing_queue",
-		true,   // durable
-		false,  // delete when unused
-		false,  // exclusive
-		false,  // no-wait
-		nil,    // arguments
-	)
-	failOnError(err, "Failed to declare a queue for consumer")
-
-	msgs, err := ch.Consume(
-		q.Name, // queue
-		"",     // consumer
-		true,   // auto-ack
-		false,  // exclusive
-		false,  // no-local
-		false,  // no-wait
-		nil,    // args
-	)
-	failOnError(err, "Failed to register a consumer")
-
-	log.Println(" [*] Waiting for messages. To exit press CTRL+C")
-	forever := make(chan bool)
-
-	go func() {
-		for d := range msgs {
-			log.Printf(" [x] Received a message: %s", d.Body)
-
-			var msg VideoProcessingMessage
-			err := json.Unmarshal(d.Body, &msg)
-			if err != nil {
-				log.Printf("ERROR: Failed to unmarshal message: %v", err)
-				continue
-			}
+// proxySettings reads PROXY_RENDITION=true, which enables the MP4 proxy,
+// and its limits: PROXY_MAX_SIZE ("1280x720"), PROXY_VIDEO_KBPS and
+// PROXY_AUDIO_KBPS.
+func proxySettings() *domain.ProxySettings {
+	if os.Getenv("PROXY_RENDITION") != "true" {
+		return nil
+	}
+	def := domain.DefaultProxySettings
+	settings := domain.ProxySettings{
+		MaxWidth:  def.MaxWidth,
+		MaxHeight: def.MaxHeight,
+		VideoKbps: intFromEnv("PROXY_VIDEO_KBPS", def.VideoKbps),
+		AudioKbps: intFromEnv("PROXY_AUDIO_KBPS", def.AudioKbps),
+	}
+	if value := os.Getenv("PROXY_MAX_SIZE"); value != "" {
+		if _, err := fmt.Sscanf(value, "%dx%d", &settings.MaxWidth, &settings.MaxHeight); err != nil || settings.MaxWidth < 2 || settings.MaxHeight < 2 {
+			slog.Warn("invalid PROXY_MAX_SIZE, using default", "value", value, "default", "1280x720")
+			settings.MaxWidth, settings.MaxHeight = def.MaxWidth, def.MaxHeight
+		}
+	}
+	return &settings
+}
 
-			updateVideoStatus(msg.VideoStatusID, "PROCESSING", "", "")
-			sendNotification(msg.UserID, msg.OriginalFilename, "PROCESSING", "Seu vídeo está sendo processado.")
+// defaultQuota reads DEFAULT_MAX_ACTIVE_JOBS and DEFAULT_MAX_UPLOAD_MB, the
+// limits for users without a quota of their own. Zero or unset means
+// unlimited.
+func defaultQuota() domain.UserQuota {
+	var quota domain.UserQuota
+	if value := os.Getenv("DEFAULT_MAX_ACTIVE_JOBS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			slog.Warn("invalid DEFAULT_MAX_ACTIVE_JOBS, using unlimited", "value", value)
+		} else {
+			quota.MaxActiveJobs = n
+		}
+	}
+	if value := os.Getenv("DEFAULT_MAX_UPLOAD_MB"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			slog.Warn("invalid DEFAULT_MAX_UPLOAD_MB, using unlimited", "value", value)
+		} else {
+			quota.MaxUploadBytes = n << 20
+		}
+	}
+	return quota
+}
 
-			outputDir := fmt.Sprintf("./processed_videos/%d", msg.UserID)
-			if _, err := os.Stat(outputDir); os.IsNotExist(err) {
-				os.MkdirAll(outputDir, 0755)
-			}
+// workerID identifies this process in the video event history. WORKER_ID
+// takes precedence; otherwise hostname and PID are used, which is unique per
+// container.
+func workerID() string {
+	if id := os.Getenv("WORKER_ID"); id != "" {
+		return id
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
 
-			framePattern := filepath.Join(outputDir, fmt.Sprintf("%s_%%04d.png", filepath.Base(msg.OriginalFilename)))
-			cmd := exec.Command("ffmpeg", "-i", msg.VideoPath, "-vf", "fps=1", framePattern)
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-
-			err = cmd.Run()
-			if err != nil {
-				errorMessage := fmt.Sprintf("FFmpeg failed to extract frames: %v", err)
-				log.Printf("ERROR processing video '%s': %s", msg.OriginalFilename, errorMessage)
-				updateVideoStatus(msg.VideoStatusID, "FAILED", "", errorMessage)
-				sendNotification(msg.UserID, msg.OriginalFilename, "FAILED", fmt.Sprintf("Falha ao processar vídeo: %s", errorMessage))
-				continue
-			}
+// runMigrations implements the "migrate" subcommand:
+//
+//	migrate [up]      apply all pending migrations
+//	migrate down [n]  revert the last n migrations (default 1)
+//	migrate status    list migrations and whether they are applied
+func runMigrations(args []string) error {
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
 
-			zipFilename := fmt.Sprintf("%d_%s_processed.zip", msg.UserID, filepath.Base(msg.OriginalFilename))
-			zipFilePath := filepath.Join(outputDir, zipFilename)
-			newZipFile, err := os.Create(zipFilePath)
-			if err != nil {
-				errorMessage := fmt.Sprintf("Failed to create zip file: %v", err)
-				log.Printf("ERROR zipping frames for '%s': %s", msg.OriginalFilename, errorMessage)
-				updateVideoStatus(msg.VideoStatusID, "FAILED", "", errorMessage)
-				sendNotification(msg.UserID, msg.OriginalFilename, "FAILED", fmt.Sprintf("Falha ao compactar frames: %s", errorMessage))
-				continue
-			}
-            // Removed defer newZipFile.Close() here
-
-			zipWriter := zip.NewWriter(newZipFile)
-            // Removed defer zipWriter.Close() here
-
-			frames, err := filepath.Glob(filepath.Join(outputDir, fmt.Sprintf("%s_*.png", filepath.Base(msg.OriginalFilename))))
-            if err != nil {
-                errorMessage := fmt.Sprintf("Failed to list frames for zipping: %v", err)
-                log.Printf("ERROR: %s", errorMessage)
-                updateVideoStatus(msg.VideoStatusID, "FAILED", "", errorMessage)
-                sendNotification(msg.UserID, msg.OriginalFilename, "FAILED", "Falha ao listar frames para compactar.")
-                continue
-            }
-			if len(frames) == 0 {
-				errorMessage := "No frames extracted to zip."
-				log.Printf("WARNING: %s", errorMessage)
-				updateVideoStatus(msg.VideoStatusID, "FAILED", "", errorMessage)
-				sendNotification(msg.UserID, msg.OriginalFilename, "FAILED", "Nenhum frame extraído para compactar.")
-				continue
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	switch command {
+	case "up":
+		n, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("migrations applied", "count", n)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %q", args[1])
 			}
-
-			for _, framePath := range frames {
-				frameFile, err := os.Open(framePath)
-				if err != nil {
-					log.Printf("WARNING: Could not open frame %s: %v", framePath, err)
-					continue
-				}
-                // Removed defer frameFile.Close() here
-
-                fileInfo, err := frameFile.Stat() // Obtenha FileInfo e erro
-                if err != nil {
-                    log.Printf("WARNING: Could not get file info for %s: %v", framePath, err)
-                    frameFile.Close() // Close file on error
-                    continue
-                }
-                header, err := zip.FileInfoHeader(fileInfo) // Passe apenas fileInfo
-                if err != nil {
-                    log.Printf("WARNING: Could not get FileInfoHeader for %s: %v", framePath, err)
-                    frameFile.Close() // Close file on error
-                    continue
-                }
-                header.Name = filepath.Base(framePath)
-                header.Method = zip.Deflate
-
-				writer, err := zipWriter.CreateHeader(header)
-				if err != nil {
-					log.Printf("WARNING: Could not create entry for %s in zip: %v", framePath, err)
-                    frameFile.Close() // Close file on error
-					continue
-				}
-				_, err = io.Copy(writer, frameFile)
-				if err != nil {
-					log.Printf("WARNING: Could not copy %s to zip: %v", framePath, err)
-                    frameFile.Close() // Close file on error
-					continue
-				}
-                frameFile.Close() // Close file after successful copy
+		}
+		n, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		slog.Info("migrations reverted", "count", n)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, mig := range migrator.Migrations {
+			state := "pending"
+			if status[mig.Version] {
+				state = "applied"
 			}
+			fmt.Printf("%04d_%s\t%s\n", mig.Version, mig.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q (want up, down or status)", command)
+	}
+	return nil
+}
 
-            // Correct order to close zip writer and file
-            if err := zipWriter.Close(); err != nil {
-                errorMessage := fmt.Sprintf("Failed to close zip writer: %v", err)
-                log.Printf("ERROR: %s", errorMessage)
-                updateVideoStatus(msg.VideoStatusID, "FAILED", "", errorMessage)
-                sendNotification(msg.UserID, msg.OriginalFilename, "FAILED", "Falha ao finalizar compactação (zipWriter).")
-                continue
-            }
-            if err := newZipFile.Close(); err != nil {
-                errorMessage := fmt.Sprintf("Failed to close zip file: %v", err)
-                log.Printf("ERROR: %s", errorMessage)
-                updateVideoStatus(msg.VideoStatusID, "FAILED", "", errorMessage)
-                sendNotification(msg.UserID, msg.OriginalFilename, "FAILED", "Falha ao fechar arquivo compactado (newZipFile).")
-                continue
-            }
-
-			os.Remove(msg.VideoPath)
-			for _, framePath := range frames {
-				os.Remove(framePath)
-			}
+func main() {
+	initDB()
+	defer db.Close()
 
-			updateVideoStatus(msg.VideoStatusID, "COMPLETED", zipFilePath, "")
-			sendNotification(msg.UserID, msg.OriginalFilename, "COMPLETED", fmt.Sprintf("Seu vídeo '%s' foi processado com sucesso! Arquivo ZIP disponível em: %s", msg.OriginalFilename, zipFilePath))
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrations(os.Args[2:]); err != nil {
+			fatal("migration failed", err)
 		}
-	}()
-
-	<-forever
-}
+		return
+	}
 
-func healthCheck(c *gin.Context) {
-	dbStatus := "connected"
-	if err := db.Ping(); err != nil {
-		dbStatus = fmt.Sprintf("error: %v", err)
+	verifier, err := jwtVerifier()
+	if err != nil {
+		fatal("invalid authentication configuration", err)
+	}
+	signer, err := shareLinkSigner()
+	if err != nil {
+		fatal("invalid share link configuration", err)
+	}
+	refreshTokens, err := refreshTokenHandlers(verifier)
+	if err != nil {
+		fatal("invalid refresh token configuration", err)
 	}
 
-	rabbitMQStatus := "connected"
-	if rabbitMQConn == nil || rabbitMQConn.IsClosed() {
-		rabbitMQStatus = "disconnected"
-	} else {
-		ch, err := rabbitMQConn.Channel()
-		if err != nil {
-			rabbitMQStatus = fmt.Sprintf("error: %v", err)
-		} else {
-			ch.Close()
+	if os.Getenv("AUTO_MIGRATE") != "false" {
+		if err := runMigrations(nil); err != nil {
+			fatal("migration failed", err)
 		}
 	}
 
-	if dbStatus != "connected" || rabbitMQStatus != "connected" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status": "DOWN",
-			"database": dbStatus,
-			"rabbitmq": rabbitMQStatus,
-		})
-		return
+	shutdownTracing, err := infrastructure.InitTracing(context.Background(), "video-processor-service")
+	if err != nil {
+		fatal("failed to initialise tracing", err)
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"status": "UP",
-		"database": dbStatus,
-		"rabbitmq": rabbitMQStatus,
-	})
-}
+	defer shutdownTracing(context.Background())
 
-func main() {
-	initDB()
-	defer db.Close()
+	metrics := infrastructure.NewPrometheusMetrics()
 
 	initRabbitMQ()
-	defer rabbitMQConn.Close()
+	defer messageQueue.Close()
+	messageQueue.Metrics = metrics
+
+	videoRepo := infrastructure.NewPostgresVideoRepository(db)
+	eventRepo := infrastructure.NewPostgresVideoEventRepository(db)
+	fileStorage := infrastructure.NewLocalFileStorage("./uploads", "./processed_videos")
+	notification := infrastructure.NewLogNotificationService()
+	frameRepo := infrastructure.NewPostgresFrameRepository(db)
+	outputRepo := infrastructure.NewPostgresOutputRepository(db)
+	ffmpeg := infrastructure.NewFFmpegVideoProcessor()
+
+	processUC := &usecase.ProcessVideoUseCase{
+		VideoRepo:       videoRepo,
+		EventRepo:       eventRepo,
+		FileStorage:     fileStorage,
+		Processor:       ffmpeg,
+		Notification:    notification,
+		Metrics:         metrics,
+		WorkerID:        workerID(),
+		ArtifactLayout:  artifactLayout(),
+		Frames:          frameRepo,
+		Outputs:         outputRepo,
+		Sprites:         spriteSettings(),
+		Preview:         previewSettings(),
+		PreviewRenderer: ffmpeg,
+		Audio:           audioSettings(),
+		AudioProcessor:  ffmpeg,
+		Subtitles:       ffmpeg,
+		SubtitleFormat:  subtitleFormat(),
+		Proxy:           proxySettings(),
+		Transcoder:      ffmpeg,
+		KeepFrames:      os.Getenv("KEEP_FRAMES") == "true",
+		KeepSource:      os.Getenv("KEEP_SOURCE_VIDEOS") == "true",
+	}
+	go func() {
+		if err := messageQueue.ConsumeVideoProcessing(processUC.Execute); err != nil {
+			fatal("consumer stopped", err)
+		}
+	}()
 
-	go startConsumer()
+	quotaRepo := infrastructure.NewPostgresQuotaRepository(db)
+	workspaceRepo := infrastructure.NewPostgresWorkspaceRepository(db)
+	quota := defaultQuota()
+	requeueUC := &usecase.RequeueVideoUseCase{VideoRepo: videoRepo, EventRepo: eventRepo, MessageQueue: messageQueue, QuotaRepo: quotaRepo, DefaultQuota: quota}
+
+	videoHandlers := infrastructure.NewVideoHandlers(
+		&usecase.UploadVideoUseCase{
+			VideoRepo:               videoRepo,
+			EventRepo:               eventRepo,
+			IdempotencyRepo:         infrastructure.NewPostgresIdempotencyRepository(db),
+			MessageQueue:            messageQueue,
+			FileStorage:             fileStorage,
+			IdempotencyWindow:       durationFromEnv("IDEMPOTENCY_WINDOW", usecase.DefaultIdempotencyWindow),
+			IdempotencyClaimTimeout: durationFromEnv("IDEMPOTENCY_CLAIM_TIMEOUT", usecase.DefaultIdempotencyClaimTimeout),
+			DedupScope:              dedupScope(),
+			Metrics:                 metrics,
+			QuotaRepo:               quotaRepo,
+			DefaultQuota:            quota,
+		},
+		&usecase.ListVideoStatusUseCase{VideoRepo: videoRepo},
+		&usecase.DownloadVideoUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, FileStorage: fileStorage},
+		&usecase.ListVideoEventsUseCase{VideoRepo: videoRepo, EventRepo: eventRepo, Workspaces: workspaceRepo},
+		&usecase.ReprocessVideoUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, Requeue: requeueUC},
+	)
 
-	router := gin.Default()
+	apiKeyRepo := infrastructure.NewPostgresAPIKeyRepository(db)
+	apiKeyHandlers := infrastructure.NewAPIKeyHandlers(
+		&usecase.CreateAPIKeyUseCase{Repo: apiKeyRepo},
+		&usecase.ListAPIKeysUseCase{Repo: apiKeyRepo},
+		&usecase.RevokeAPIKeyUseCase{Repo: apiKeyRepo},
+	)
 
-	router.GET("/health", healthCheck)
-	router.GET("/", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"message": "Video Processor Service is running!"})
-	})
+	adminHandlers := infrastructure.NewAdminHandlers(
+		&usecase.ListAllVideosUseCase{VideoRepo: videoRepo},
+		&usecase.ForceFailVideoUseCase{VideoRepo: videoRepo, EventRepo: eventRepo},
+		requeueUC,
+		&usecase.DeleteVideoUseCase{VideoRepo: videoRepo, FileStorage: fileStorage, Frames: frameRepo, Outputs: outputRepo},
+		&usecase.GetQueueStatsUseCase{Queue: messageQueue},
+		&usecase.GetUserQuotaUseCase{QuotaRepo: quotaRepo, DefaultQuota: quota},
+		&usecase.SetUserQuotaUseCase{QuotaRepo: quotaRepo},
+	)
 
-	authRoutes := router.Group("/")
-	authRoutes.Use(authMiddleware())
-	{
-		authRoutes.POST("/upload", uploadVideo)
-        authRoutes.GET("/videos/status", listVideosStatus)
-        authRoutes.GET("/videos/:id/download", downloadProcessedVideo)
-	}
+	shareLinkRepo := infrastructure.NewPostgresShareLinkRepository(db)
+	shareHandlers := infrastructure.NewShareHandlers(
+		&usecase.CreateShareLinkUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, Repo: shareLinkRepo, Signer: signer},
+		&usecase.ListShareLinksUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, Repo: shareLinkRepo},
+		&usecase.ListShareAccessesUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, Repo: shareLinkRepo},
+		&usecase.RevokeShareLinkUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, Repo: shareLinkRepo},
+		&usecase.DownloadSharedVideoUseCase{Repo: shareLinkRepo, VideoRepo: videoRepo, FileStorage: fileStorage, Signer: signer},
+		os.Getenv("PUBLIC_BASE_URL"),
+	)
+
+	frameHandlers := infrastructure.NewFrameHandlers(
+		&usecase.ListFramesUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, FileStorage: fileStorage, Frames: frameRepo},
+		&usecase.GetFrameUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, FileStorage: fileStorage, Frames: frameRepo},
+		&usecase.DownloadFramesUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, FileStorage: fileStorage, Frames: frameRepo},
+		&usecase.GrabFrameUseCase{
+			VideoRepo:   videoRepo,
+			Workspaces:  workspaceRepo,
+			FileStorage: fileStorage,
+			Grabber:     ffmpeg,
+			Outputs:     outputRepo,
+			Timeout:     durationFromEnv("FRAME_GRAB_TIMEOUT", 10*time.Second),
+			MaxPerUser:  intFromEnv("FRAME_GRABS_PER_USER", 2),
+			CacheBytes:  int64(intFromEnv("FRAME_GRAB_CACHE_MB", 64)) << 20,
+		},
+		&usecase.GetManifestUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, FileStorage: fileStorage},
+	)
+
+	outputHandlers := infrastructure.NewOutputHandlers(
+		&usecase.ListOutputsUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, Outputs: outputRepo},
+		&usecase.GetOutputUseCase{VideoRepo: videoRepo, Workspaces: workspaceRepo, FileStorage: fileStorage, Outputs: outputRepo},
+	)
+
+	workspaceHandlers := infrastructure.NewWorkspaceHandlers(
+		&usecase.CreateWorkspaceUseCase{Repo: workspaceRepo},
+		&usecase.ListWorkspacesUseCase{Repo: workspaceRepo},
+		&usecase.ListWorkspaceMembersUseCase{Repo: workspaceRepo},
+		&usecase.SetWorkspaceMemberUseCase{Repo: workspaceRepo},
+		&usecase.RemoveWorkspaceMemberUseCase{Repo: workspaceRepo},
+	)
+
+	router := infrastructure.NewRouter(infrastructure.RouterConfig{
+		VideoHandlers:        videoHandlers,
+		APIKeyHandlers:       apiKeyHandlers,
+		AdminHandlers:        adminHandlers,
+		WorkspaceHandlers:    workspaceHandlers,
+		ShareHandlers:        shareHandlers,
+		FrameHandlers:        frameHandlers,
+		OutputHandlers:       outputHandlers,
+		RefreshTokenHandlers: refreshTokens,
+		Health: infrastructure.NewHealthChecker(
+			durationFromEnv("HEALTH_CACHE_TTL", infrastructure.DefaultHealthCacheTTL),
+			infrastructure.DatabaseHealthCheck(db),
+			infrastructure.MessageQueueHealthCheck(messageQueue),
+			infrastructure.ConsumerHealthCheck(messageQueue),
+			infrastructure.ExecutableHealthCheck("ffmpeg"),
+			infrastructure.ExecutableHealthCheck("ffprobe"),
+			infrastructure.WritableDirHealthCheck("upload_dir", fileStorage.UploadDir),
+			infrastructure.WritableDirHealthCheck("processed_dir", fileStorage.ProcessedDir),
+			infrastructure.FreeDiskHealthCheck(fileStorage.ProcessedDir, minFreeDisk()),
+		),
+		AuthMiddleware:      infrastructure.AuthMiddleware(verifier, &usecase.AuthenticateAPIKeyUseCase{Repo: apiKeyRepo}),
+		WorkspaceMiddleware: infrastructure.WorkspaceMiddleware(&usecase.ResolveWorkspaceUseCase{Repo: workspaceRepo}),
+		Metrics:             metrics,
+	})
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "5001"
 	}
-	log.Printf("Video Processor Service escutando na porta :%s...", port)
-	log.Fatal(router.Run(":" + port))
-}
\ No newline at end of file
+	slog.Info("video processor service listening", "port", port)
+	fatal("server stopped", router.Run(":"+port))
+}
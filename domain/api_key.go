@@ -0,0 +1,68 @@
+// domain/api_key.go
+package domain
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// Scope limits what a credential may do. User tokens carry every scope; API
+// keys carry only the ones granted when they were created.
+type Scope string
+
+const (
+	ScopeUpload Scope = "upload"
+	ScopeRead   Scope = "read"
+	// ScopeShare covers creating and revoking public share links, which
+	// hand a video to anyone holding the URL.
+	ScopeShare Scope = "share"
+	// ScopeManageAPIKeys is never granted to API keys, so a leaked key can't
+	// be used to mint more.
+	ScopeManageAPIKeys Scope = "api_keys"
+	// ScopeManageWorkspaces covers creating workspaces and changing their
+	// members. Like ScopeManageAPIKeys it is never granted to API keys.
+	ScopeManageWorkspaces Scope = "workspaces"
+	// ScopeSessions covers issuing and revoking refresh tokens. It is never
+	// granted to API keys either.
+	ScopeSessions Scope = "sessions"
+)
+
+// UserScopes are held by interactive users authenticated with a JWT.
+var UserScopes = []Scope{ScopeUpload, ScopeRead, ScopeShare, ScopeManageAPIKeys, ScopeManageWorkspaces, ScopeSessions}
+
+// GrantableScopes can be given to an API key.
+var GrantableScopes = []Scope{ScopeUpload, ScopeRead, ScopeShare}
+
+var (
+	ErrAPIKeyNotFound       = errors.New("api key not found")
+	ErrInvalidAPIKey        = errors.New("invalid, expired or revoked api key")
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+)
+
+// APIKey is a long-lived credential for machine clients. Only a hash of the
+// secret is kept; Prefix identifies the key in listings.
+type APIKey struct {
+	ID         int
+	UserID     int
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []Scope
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// Usable reports whether the key is neither revoked nor expired at now.
+func (k *APIKey) Usable(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+func (k *APIKey) HasScope(scope Scope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
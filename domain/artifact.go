@@ -0,0 +1,61 @@
+// domain/artifact.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnsupportedArchiveFormat = errors.New("unsupported archive format")
+
+// ArtifactLayout is how a completed job's output is kept in storage.
+type ArtifactLayout string
+
+const (
+	// ArtifactLayoutZip keeps a single ZIP at Video.ProcessedFilePath.
+	ArtifactLayoutZip ArtifactLayout = "zip"
+	// ArtifactLayoutFrames keeps the extracted frames in the directory at
+	// Video.ProcessedFilePath; archives are built while they are downloaded.
+	ArtifactLayoutFrames ArtifactLayout = "frames"
+)
+
+// OrDefault treats an unset layout as ArtifactLayoutZip, the layout of every
+// artifact stored before layouts were recorded.
+func (l ArtifactLayout) OrDefault() ArtifactLayout {
+	if l == "" {
+		return ArtifactLayoutZip
+	}
+	return l
+}
+
+// ArchiveFormat is the container a download is packed in.
+type ArchiveFormat string
+
+const (
+	ArchiveFormatZip   ArchiveFormat = "zip"
+	ArchiveFormatTar   ArchiveFormat = "tar"
+	ArchiveFormatTarGz ArchiveFormat = "tar.gz"
+)
+
+// ParseArchiveFormat accepts the formats above; an empty string means ZIP.
+func ParseArchiveFormat(s string) (ArchiveFormat, error) {
+	switch f := ArchiveFormat(s); f {
+	case "":
+		return ArchiveFormatZip, nil
+	case ArchiveFormatZip, ArchiveFormatTar, ArchiveFormatTarGz:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedArchiveFormat, s)
+	}
+}
+
+func (f ArchiveFormat) ContentType() string {
+	switch f {
+	case ArchiveFormatTar:
+		return "application/x-tar"
+	case ArchiveFormatTarGz:
+		return "application/gzip"
+	default:
+		return "application/zip"
+	}
+}
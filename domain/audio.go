@@ -0,0 +1,88 @@
+// domain/audio.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoAudioStream          = errors.New("video has no audio stream")
+	ErrUnsupportedAudioFormat = errors.New("unsupported audio format")
+)
+
+// AudioFormat is the encoding of an extracted audio track.
+type AudioFormat string
+
+const (
+	AudioFormatWAV  AudioFormat = "wav"
+	AudioFormatFLAC AudioFormat = "flac"
+	AudioFormatMP3  AudioFormat = "mp3"
+	// AudioFormatOpus is Opus in an Ogg container.
+	AudioFormatOpus AudioFormat = "opus"
+)
+
+func ParseAudioFormat(s string) (AudioFormat, error) {
+	switch f := AudioFormat(s); f {
+	case AudioFormatWAV, AudioFormatFLAC, AudioFormatMP3, AudioFormatOpus:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedAudioFormat, s)
+	}
+}
+
+func (f AudioFormat) ContentType() string {
+	switch f {
+	case AudioFormatMP3:
+		return "audio/mpeg"
+	case AudioFormatOpus:
+		return "audio/ogg"
+	default:
+		return "audio/" + string(f)
+	}
+}
+
+// AudioSettings shape the audio outputs: the track itself and a waveform
+// image of WaveformWidth x WaveformHeight pixels.
+type AudioSettings struct {
+	Format         AudioFormat
+	WaveformWidth  int
+	WaveformHeight int
+}
+
+var DefaultAudioSettings = AudioSettings{Format: AudioFormatFLAC, WaveformWidth: 1200, WaveformHeight: 240}
+
+// OrDefault fills in zero values from DefaultAudioSettings.
+func (s AudioSettings) OrDefault() AudioSettings {
+	if s.Format == "" {
+		s.Format = DefaultAudioSettings.Format
+	}
+	if s.WaveformWidth <= 0 {
+		s.WaveformWidth = DefaultAudioSettings.WaveformWidth
+	}
+	if s.WaveformHeight <= 0 {
+		s.WaveformHeight = DefaultAudioSettings.WaveformHeight
+	}
+	return s
+}
+
+// Loudness is an EBU R128 measurement of an audio track.
+type Loudness struct {
+	IntegratedLUFS float64 `json:"integrated_lufs"`
+	// TruePeakDBTP is the highest inter-sample peak. Silent tracks, which
+	// have none, report SilentTruePeak.
+	TruePeakDBTP float64 `json:"true_peak_dbtp"`
+	RangeLU      float64 `json:"loudness_range_lu"`
+}
+
+// SilentTruePeak stands in for the true peak of a track with no signal, the
+// noise floor of 24-bit audio.
+const SilentTruePeak = -144.0
+
+// AudioAnalysis is what a job learned about the audio of a video.
+type AudioAnalysis struct {
+	HasAudio bool `json:"has_audio"`
+	// Loudness is nil for videos without audio or when it could not be
+	// measured.
+	Loudness *Loudness `json:"loudness,omitempty"`
+}
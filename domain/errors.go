@@ -0,0 +1,15 @@
+// domain/errors.go
+package domain
+
+import "errors"
+
+var (
+	ErrVideoNotFound      = errors.New("video not found")
+	ErrVideoNotCompleted  = errors.New("video not completed")
+	ErrAccessDenied       = errors.New("access denied")
+	ErrProcessedFileEmpty = errors.New("processed file path not found or invalid")
+	ErrFileNotFound       = errors.New("file not found on storage")
+	ErrVideoProcessing    = errors.New("video is being processed")
+	ErrSourceUnavailable  = errors.New("original upload is no longer available")
+	ErrClaimLost          = errors.New("video was taken from this job or claimed again")
+)
@@ -0,0 +1,20 @@
+// domain/extraction_settings.go
+package domain
+
+// ExtractionSettings are the parameters that determine which frames a job
+// produces. Two jobs over the same content with equal settings yield the same
+// artifact.
+type ExtractionSettings struct {
+	FPS float64 `json:"fps"`
+}
+
+var DefaultExtractionSettings = ExtractionSettings{FPS: 1}
+
+// OrDefault fills in zero values, e.g. for messages queued before settings
+// were part of the payload.
+func (s ExtractionSettings) OrDefault() ExtractionSettings {
+	if s.FPS <= 0 {
+		s.FPS = DefaultExtractionSettings.FPS
+	}
+	return s
+}
@@ -0,0 +1,42 @@
+// domain/frame.go
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrFrameNotFound         = errors.New("frame not found")
+	ErrInvalidFrameSelection = errors.New("invalid frame selection")
+)
+
+// Frame is one extracted frame of a processed artifact.
+type Frame struct {
+	// Index is the 1-based position of the frame, as in its filename.
+	Index int
+	// Timestamp is the presentation time of the frame in the video, as
+	// reported by the extractor. Frames of artifacts processed before it
+	// was reported carry the nominal time derived from the extraction
+	// rate instead.
+	Timestamp time.Duration
+	// Name is the frame's filename, also its entry name in archives.
+	Name string
+	// Path is where the frame is kept in storage. It is empty when the
+	// frame only exists inside a ZIP artifact.
+	Path   string
+	Size   int64
+	Width  int
+	Height int
+	// Checksum is the hex SHA-256 of the frame. Frames indexed before
+	// checksums were recorded have none.
+	Checksum string
+}
+
+// FrameSelection picks frames for a partial archive: either the inclusive
+// range From..To or the listed Indices.
+type FrameSelection struct {
+	From    int
+	To      int
+	Indices []int
+}
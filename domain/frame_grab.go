@@ -0,0 +1,39 @@
+// domain/frame_grab.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrFrameTimeout         = errors.New("frame extraction timed out")
+	ErrTooManyFrameRequests = errors.New("too many frame extractions in progress")
+	ErrUnsupportedImageType = errors.New("unsupported image format")
+)
+
+// ImageFormat is the encoding of a frame extracted on demand.
+type ImageFormat string
+
+const (
+	ImageFormatJPEG ImageFormat = "jpeg"
+	ImageFormatPNG  ImageFormat = "png"
+	ImageFormatWebP ImageFormat = "webp"
+)
+
+// ParseImageFormat accepts the formats above and "jpg"; an empty string
+// means JPEG.
+func ParseImageFormat(s string) (ImageFormat, error) {
+	switch f := ImageFormat(s); f {
+	case "", "jpg":
+		return ImageFormatJPEG, nil
+	case ImageFormatJPEG, ImageFormatPNG, ImageFormatWebP:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedImageType, s)
+	}
+}
+
+func (f ImageFormat) ContentType() string {
+	return "image/" + string(f)
+}
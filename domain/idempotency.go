@@ -0,0 +1,27 @@
+// domain/idempotency.go
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key already used with a different payload")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// IdempotencyRecord remembers the outcome of an upload made with an
+// Idempotency-Key so that retries can be answered with the same response.
+// VideoStatusID is zero while the original request is still running;
+// Message and Deduplicated complete its response once it succeeded.
+type IdempotencyRecord struct {
+	UserID        int
+	Key           string
+	RequestHash   string
+	VideoStatusID int
+	Filename      string
+	Message       string
+	Deduplicated  bool
+	CreatedAt     time.Time
+}
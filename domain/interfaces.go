@@ -1,32 +1,304 @@
 // domain/interfaces.go
 package domain
 
+import (
+	"context"
+	"io"
+	"time"
+)
+
 type VideoRepository interface {
-    Save(video *Video) error
-    UpdateStatus(videoID int, status VideoStatus, processedFilePath, errorMessage string) error
-    FindByID(videoID int) (*Video, error)
-    FindByUserID(userID int) ([]Video, error)
+	Save(video *Video) error
+	// SaveWithinQuota saves a new unfinished video unless its user already
+	// has maxActive videos waiting or processing, in which case it returns
+	// ErrQuotaExceeded. The count and the insert are atomic with respect to
+	// other uploads of the user. Zero maxActive means no limit.
+	SaveWithinQuota(video *Video, maxActive int) error
+	// RequeueWithinQuota moves a FAILED video back to PENDING under the same
+	// limit and locking as SaveWithinQuota.
+	RequeueWithinQuota(videoID, maxActive int) error
+	// TransitionStatus moves a video from one status to another atomically.
+	// It fails with an *InvalidTransitionError when the transition is not
+	// allowed or the stored status is no longer from. Moving to any status
+	// but COMPLETED clears the artifact checksum, and moving out of
+	// PROCESSING takes the video from its worker by changing the claim.
+	TransitionStatus(videoID int, from, to VideoStatus, processedFilePath, errorMessage string) error
+	// ClaimVideo moves a PENDING video to PROCESSING for a worker and returns
+	// the new claim. It fails with an *InvalidTransitionError when the video
+	// is not PENDING.
+	ClaimVideo(videoID int) (int, error)
+	// FinishClaim moves a PROCESSING video to to like TransitionStatus, but
+	// only while claim is still the video's claim; otherwise it returns
+	// ErrClaimLost.
+	FinishClaim(videoID, claim int, to VideoStatus, processedFilePath, errorMessage string) error
+	FindByID(videoID int) (*Video, error)
+	// FindByUserID lists the user's personal videos, those outside any
+	// workspace, newest first.
+	FindByUserID(userID int) ([]Video, error)
+	FindByWorkspaceID(workspaceID int) ([]Video, error)
+	// FindCompletedByContentHash returns the most recent COMPLETED video with
+	// the given content hash and settings, restricted to userID unless it is
+	// zero. It returns ErrVideoNotFound when there is none.
+	FindCompletedByContentHash(contentHash string, settings ExtractionSettings, userID int) (*Video, error)
+	// FindAll lists videos of every user, newest first.
+	FindAll(filter VideoFilter) ([]Video, error)
+	CountActiveByUserID(userID int) (int, error)
+	// ProcessedFileInUse reports whether any video other than exceptVideoID
+	// points at path, as deduplicated videos share artifacts.
+	ProcessedFileInUse(path string, exceptVideoID int) (bool, error)
+	Delete(videoID int) error
+	// The setters below write what a job found out about the video. They
+	// return ErrClaimLost unless claim is still the video's claim.
+
+	// SetProcessedFileChecksum records the checksum of the artifact the video
+	// points at.
+	SetProcessedFileChecksum(videoID, claim int, checksum string) error
+	SetArtifactLayout(videoID, claim int, layout ArtifactLayout) error
+	SetAudioAnalysis(videoID, claim int, analysis AudioAnalysis) error
+	SetSubtitleStreams(videoID, claim int, streams []SubtitleStream) error
+}
+
+// VideoFilter narrows VideoRepository.FindAll. Zero fields don't filter.
+type VideoFilter struct {
+	UserID int
+	Status VideoStatus
+	Limit  int
+	Offset int
+}
+
+// FrameRepository indexes the frames of artifacts. Frames are keyed by the
+// artifact path, which deduplicated videos share.
+type FrameRepository interface {
+	// ReplaceFrames records the frames of an artifact, dropping any recorded
+	// for it before.
+	ReplaceFrames(artifactPath string, frames []Frame) error
+	// FindByArtifact lists the frames of an artifact in index order.
+	FindByArtifact(artifactPath string) ([]Frame, error)
+	DeleteByArtifact(artifactPath string) error
+}
+
+// OutputRepository records the outputs of artifacts, keyed like frames by
+// the artifact path.
+type OutputRepository interface {
+	// ReplaceOutputs records the outputs of an artifact, dropping any
+	// recorded for it before.
+	ReplaceOutputs(artifactPath string, outputs []Output) error
+	// FindByArtifact lists the outputs of an artifact by name.
+	FindByArtifact(artifactPath string) ([]Output, error)
+	DeleteByArtifact(artifactPath string) error
+}
+
+type WorkspaceRepository interface {
+	// Create stores workspace and makes ownerID its first owner.
+	Create(workspace *Workspace, ownerID int) error
+	// FindByUserID lists the workspaces userID belongs to.
+	FindByUserID(userID int) ([]WorkspaceMembership, error)
+	// FindMember returns ErrNotWorkspaceMember when userID is not in
+	// workspaceID, including when the workspace doesn't exist.
+	FindMember(workspaceID, userID int) (*WorkspaceMember, error)
+	ListMembers(workspaceID int) ([]WorkspaceMember, error)
+	// SetMember adds a member or changes an existing member's role.
+	SetMember(member *WorkspaceMember) error
+	// RemoveMember returns ErrNotWorkspaceMember when there is no such
+	// member.
+	RemoveMember(workspaceID, userID int) error
+}
+
+type ShareLinkRepository interface {
+	Create(link *ShareLink) error
+	// FindByID returns ErrShareLinkNotFound when there is no such link.
+	FindByID(linkID int) (*ShareLink, error)
+	FindByVideoID(videoID int) ([]ShareLink, error)
+	// Revoke returns ErrShareLinkNotFound unless videoID has such a link.
+	Revoke(videoID, linkID int, at time.Time) error
+	// ConsumeDownload counts a download through the link if it is usable at
+	// now, checking and counting atomically. Otherwise it returns the error
+	// from ShareLink.Check.
+	ConsumeDownload(linkID int, now time.Time) (*ShareLink, error)
+	// CreateTransfer records a counted download so that it can be resumed.
+	CreateTransfer(transfer *ShareTransfer) error
+	// ResumeTransfer charges a resume from start to the transfer when
+	// ShareTransfer.CanResume allows it, checking and charging atomically.
+	// Otherwise it returns ErrShareTransferNotFound.
+	ResumeTransfer(linkID int, token, checksum string, start int64) (*ShareTransfer, error)
+	// AdvanceTransfer records that the bytes from start to end were sent,
+	// moving Sent forward when they leave no gap.
+	AdvanceTransfer(linkID int, token string, start, end int64) error
+	LogAccess(access *ShareAccess) error
+	// FindAccesses lists the accesses through a link, newest first.
+	FindAccesses(linkID int) ([]ShareAccess, error)
+}
+
+type QuotaRepository interface {
+	// Get returns the stored quota of userID, or nil when none was set.
+	Get(userID int) (*UserQuota, error)
+	Set(quota *UserQuota) error
+}
+
+type VideoEventRepository interface {
+	Append(event *VideoEvent) error
+	FindByVideoID(videoID int) ([]VideoEvent, error)
+}
+
+type IdempotencyRepository interface {
+	// Reserve claims record.Key for record.UserID. If a record younger than
+	// window already holds the key it is returned and nothing is written,
+	// unless that record never got a video and is older than claimTimeout:
+	// its request is taken to have died and the key is taken over.
+	Reserve(record *IdempotencyRecord, window, claimTimeout time.Duration) (*IdempotencyRecord, error)
+	// Complete stores the response of the request holding record.Key:
+	// its VideoStatusID, Message and Deduplicated.
+	Complete(record *IdempotencyRecord) error
+	Release(userID int, key string) error
+}
+
+type APIKeyRepository interface {
+	Create(key *APIKey) error
+	// FindByHash returns ErrAPIKeyNotFound when no key has the hash.
+	FindByHash(keyHash string) (*APIKey, error)
+	FindByUserID(userID int) ([]APIKey, error)
+	// Revoke marks a key owned by userID as revoked. It returns
+	// ErrAPIKeyNotFound when the user has no such key.
+	Revoke(userID, keyID int) error
+	TouchLastUsed(keyID int, at time.Time) error
+}
+
+type RefreshTokenRepository interface {
+	Create(token *RefreshToken) error
+	// FindByHash returns ErrRefreshTokenNotFound when no token has the hash.
+	FindByHash(tokenHash string) (*RefreshToken, error)
+	// Rotate marks token tokenID as exchanged and stores next in its place.
+	// Only one exchange of a token succeeds; the others, and exchanges of
+	// revoked tokens, fail with ErrInvalidRefreshToken.
+	Rotate(tokenID int, next *RefreshToken) error
+	// RevokeFamily revokes every token of the family.
+	RevokeFamily(familyID string) error
+	// RevokeByUserID revokes every token of the user.
+	RevokeByUserID(userID int) error
+}
+
+// AccessTokenSigner mints the access tokens handed out when a refresh token
+// is exchanged. sessionID names the refresh token family they come from.
+type AccessTokenSigner interface {
+	SignAccessToken(subject TokenSubject, sessionID string) (token string, expiresAt time.Time, err error)
 }
 
 type MessageQueueService interface {
-    PublishVideoProcessing(message VideoProcessingMessage) error
-    ConsumeVideoProcessing(handler func(VideoProcessingMessage)) error
+	// PublishVideoProcessing carries the trace context in ctx along with the
+	// message; ConsumeVideoProcessing restores it into the handler's ctx.
+	PublishVideoProcessing(ctx context.Context, message VideoProcessingMessage) error
+	ConsumeVideoProcessing(handler func(ctx context.Context, message VideoProcessingMessage)) error
+}
+
+// QueueInspector is implemented by message queues that can report their
+// backlog.
+type QueueInspector interface {
+	QueueStats(ctx context.Context) (QueueStats, error)
 }
 
 type NotificationService interface {
-    SendNotification(userID int, originalFilename, status, message string)
+	SendNotification(ctx context.Context, userID int, originalFilename, status, message string)
+}
+
+// StoredFile is an open handle on a file kept by a FileStorageService.
+type StoredFile struct {
+	io.ReadSeekCloser
+	Name    string
+	Size    int64
+	ModTime time.Time
+	// Checksum is the hex SHA-256 of the contents when known. Storage
+	// services leave it empty; the use cases fill it from the video record.
+	Checksum string
 }
 
 type FileStorageService interface {
-    SaveUploadedFile(src io.Reader, filename string) (string, error)
-    GenerateProcessedFileName(userID int, originalFilename string) string
-    GenerateFramePattern(outputDir, originalFilename string) string
-    DeleteFile(filePath string) error
-    DeleteFrames(outputDir, originalFilename string) error
-    ZipFrames(outputDir, originalFilename, zipFilePath string) (string, error)
-    GetProcessedFilePath(userID int, originalFilename string) string
+	SaveUploadedFile(src io.Reader, filename string) (string, error)
+	// OutputDir returns a directory private to one attempt at a job, named by
+	// the claim the attempt holds, creating it if needed. A superseded
+	// attempt still running never shares it with the next one.
+	OutputDir(userID, videoID, claim int) (string, error)
+	// DeleteOutputDir removes an attempt's output directory with everything
+	// in it.
+	DeleteOutputDir(outputDir string) error
+	GenerateProcessedFileName(userID int, originalFilename string) string
+	GenerateFramePattern(outputDir, originalFilename string) string
+	DeleteFile(filePath string) error
+	DeleteFrames(outputDir, originalFilename string) error
+	// SaveFile stores data at filePath, replacing any previous content.
+	SaveFile(filePath string, data []byte) error
+	// ZipFrames packs the frames in outputDir, preceded by the manifest when
+	// one was saved there, into zipFilePath and returns how many frames were
+	// written.
+	ZipFrames(outputDir, originalFilename, zipFilePath string) (int, error)
+	GetProcessedFilePath(outputDir string, userID int, originalFilename string) string
+	// GetManifestPath returns where the manifest of a job's frames is saved.
+	GetManifestPath(outputDir string) string
+	// GetOutputPath returns where a job's output called name is saved, apart
+	// from its frames.
+	GetOutputPath(outputDir, name string) string
+	// ListFrames returns the paths of the frames in a job's output directory,
+	// in frame order.
+	ListFrames(outputDir string) ([]string, error)
+	OpenFile(filePath string) (*StoredFile, error)
 }
 
 type VideoProcessor interface {
-    ExtractFrames(videoPath, framePattern string) error
-}
\ No newline at end of file
+	// ExtractFrames writes frames numbered from 1 through framePattern and
+	// returns the presentation time of each in the source, in order.
+	ExtractFrames(videoPath, framePattern string, settings ExtractionSettings) ([]time.Duration, error)
+}
+
+type PreviewRenderer interface {
+	// RenderPreview encodes a looping preview of the video stored at
+	// videoPath as settings describe. Videos shorter than the segments
+	// together are used whole.
+	RenderPreview(ctx context.Context, videoPath string, settings PreviewSettings) ([]byte, error)
+}
+
+type AudioProcessor interface {
+	// HasAudio reports whether the video stored at videoPath has an audio
+	// stream. The methods below return ErrNoAudioStream when it has not.
+	HasAudio(ctx context.Context, videoPath string) (bool, error)
+	// ExtractAudio writes the first audio stream of the video to
+	// outputPath, encoded as format.
+	ExtractAudio(ctx context.Context, videoPath, outputPath string, format AudioFormat) error
+	// RenderWaveform draws the first audio stream as a PNG of width x height
+	// pixels.
+	RenderWaveform(ctx context.Context, videoPath string, width, height int) ([]byte, error)
+	MeasureLoudness(ctx context.Context, videoPath string) (Loudness, error)
+}
+
+type SubtitleExtractor interface {
+	// ProbeSubtitles lists the subtitle streams of the video stored at
+	// videoPath in stream order.
+	ProbeSubtitles(ctx context.Context, videoPath string) ([]SubtitleStream, error)
+	// ExtractSubtitles converts the subtitle stream at index to format. It
+	// returns ErrBitmapSubtitles for streams that are not text.
+	ExtractSubtitles(ctx context.Context, videoPath string, index int, format SubtitleFormat) ([]byte, error)
+}
+
+type Transcoder interface {
+	// TranscodeProxy writes an MP4 rendition of the video stored at
+	// videoPath to outputPath, as settings describe. Videos without audio
+	// get a rendition without audio.
+	TranscodeProxy(ctx context.Context, videoPath, outputPath string, settings ProxySettings) error
+}
+
+type FrameGrabber interface {
+	// GrabFrame encodes the frame shown at `at` in the video stored at
+	// videoPath, scaled to width pixels wide unless width is zero. It
+	// returns ErrFrameNotFound when the video ends before at.
+	GrabFrame(ctx context.Context, videoPath string, at time.Duration, width int, format ImageFormat) ([]byte, error)
+}
+
+// MetricsRecorder receives operational measurements from the use cases and
+// adapters.
+type MetricsRecorder interface {
+	UploadReceived(bytes int64)
+	JobStarted(queueLag time.Duration)
+	JobFinished(status VideoStatus)
+	FFmpegDuration(d time.Duration)
+	ZipDuration(d time.Duration)
+	FramesExtracted(count int)
+	QueueReconnected()
+}
@@ -0,0 +1,67 @@
+// domain/manifest.go
+package domain
+
+import "time"
+
+// ManifestName is the name of the manifest inside an artifact, next to the
+// frames.
+const ManifestName = "manifest.json"
+
+// ManifestVersion is bumped whenever a field of Manifest changes meaning.
+const ManifestVersion = 1
+
+// Manifest ties the frames of an artifact back to the video they were taken
+// from.
+type Manifest struct {
+	Version    int                `json:"version"`
+	Source     ManifestSource     `json:"source"`
+	Extraction ExtractionSettings `json:"extraction"`
+	FrameCount int                `json:"frame_count"`
+	Frames     []ManifestFrame    `json:"frames"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// ManifestSource describes the uploaded video. Fields that were not known
+// when the manifest was written are left out.
+type ManifestSource struct {
+	Filename  string `json:"filename"`
+	SizeBytes int64  `json:"size_bytes,omitempty"`
+	SHA256    string `json:"sha256,omitempty"`
+}
+
+type ManifestFrame struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	// PTSSeconds is the presentation time of the frame in the source, as
+	// reported by the extractor.
+	PTSSeconds float64 `json:"pts_seconds"`
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+	SizeBytes  int64   `json:"size_bytes"`
+	SHA256     string  `json:"sha256"`
+}
+
+// NewManifest describes frames, in the order given, taken from source with
+// settings.
+func NewManifest(source ManifestSource, settings ExtractionSettings, frames []Frame, createdAt time.Time) *Manifest {
+	manifest := &Manifest{
+		Version:    ManifestVersion,
+		Source:     source,
+		Extraction: settings.OrDefault(),
+		FrameCount: len(frames),
+		Frames:     make([]ManifestFrame, 0, len(frames)),
+		CreatedAt:  createdAt.UTC(),
+	}
+	for _, f := range frames {
+		manifest.Frames = append(manifest.Frames, ManifestFrame{
+			Index:      f.Index,
+			Name:       f.Name,
+			PTSSeconds: f.Timestamp.Seconds(),
+			Width:      f.Width,
+			Height:     f.Height,
+			SizeBytes:  f.Size,
+			SHA256:     f.Checksum,
+		})
+	}
+	return manifest
+}
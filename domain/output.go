@@ -0,0 +1,76 @@
+// domain/output.go
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrOutputNotFound = errors.New("output not found")
+
+// OutputKind tells what an output holds.
+type OutputKind string
+
+const (
+	OutputKindSpriteSheet    OutputKind = "sprite_sheet"
+	OutputKindThumbnailTrack OutputKind = "thumbnail_track"
+	OutputKindPreview        OutputKind = "preview"
+	OutputKindAudio          OutputKind = "audio"
+	OutputKindWaveform       OutputKind = "waveform"
+	OutputKindSubtitles      OutputKind = "subtitles"
+	OutputKindProxy          OutputKind = "proxy"
+)
+
+// Output is a file a job produces next to its artifact, such as a sprite
+// sheet. Outputs belong to the artifact, so deduplicated videos share them.
+type Output struct {
+	Kind OutputKind
+	// Name identifies the output among those of its artifact and is its
+	// download filename.
+	Name        string
+	Path        string
+	ContentType string
+	Size        int64
+	// Checksum is the hex SHA-256 of the output.
+	Checksum string
+	// Language is the language tag of outputs in a language, such as
+	// subtitles.
+	Language  string
+	CreatedAt time.Time
+}
+
+// SpriteSettings shape the sprite sheets used for hover previews: each
+// sheet tiles up to Columns x Rows thumbnails.
+type SpriteSettings struct {
+	Columns int
+	Rows    int
+	// Width and Height are the size of each thumbnail; a zero Height keeps
+	// the aspect ratio of the frames.
+	Width  int
+	Height int
+	// Interval is the time between thumbnails; zero uses every extracted
+	// frame.
+	Interval time.Duration
+}
+
+var DefaultSpriteSettings = SpriteSettings{Columns: 5, Rows: 5, Width: 160}
+
+// OrDefault fills in zero or negative sizes from DefaultSpriteSettings.
+func (s SpriteSettings) OrDefault() SpriteSettings {
+	if s.Columns <= 0 {
+		s.Columns = DefaultSpriteSettings.Columns
+	}
+	if s.Rows <= 0 {
+		s.Rows = DefaultSpriteSettings.Rows
+	}
+	if s.Width <= 0 {
+		s.Width = DefaultSpriteSettings.Width
+	}
+	if s.Height < 0 {
+		s.Height = 0
+	}
+	if s.Interval < 0 {
+		s.Interval = 0
+	}
+	return s
+}
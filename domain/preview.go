@@ -0,0 +1,80 @@
+// domain/preview.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrUnsupportedPreviewFormat = errors.New("unsupported preview format")
+	ErrPreviewTooLarge          = errors.New("preview does not fit its byte budget")
+)
+
+// PreviewFormat is the encoding of an animated preview.
+type PreviewFormat string
+
+const (
+	PreviewFormatGIF  PreviewFormat = "gif"
+	PreviewFormatWebP PreviewFormat = "webp"
+)
+
+func ParsePreviewFormat(s string) (PreviewFormat, error) {
+	switch f := PreviewFormat(s); f {
+	case PreviewFormatGIF, PreviewFormatWebP:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedPreviewFormat, s)
+	}
+}
+
+func (f PreviewFormat) ContentType() string {
+	return "image/" + string(f)
+}
+
+// PreviewSettings shape a looping preview made of Segments short clips
+// spread evenly over the video.
+type PreviewSettings struct {
+	Format        PreviewFormat
+	Segments      int
+	SegmentLength time.Duration
+	// Width of the preview in pixels; the height keeps the aspect ratio.
+	Width int
+	FPS   float64
+	// MaxBytes is the largest preview accepted; larger renders are retried
+	// smaller. Zero means no budget.
+	MaxBytes int64
+}
+
+var DefaultPreviewSettings = PreviewSettings{
+	Format:        PreviewFormatGIF,
+	Segments:      5,
+	SegmentLength: time.Second,
+	Width:         320,
+	FPS:           10,
+	MaxBytes:      2 << 20,
+}
+
+// OrDefault fills in zero values from DefaultPreviewSettings.
+func (s PreviewSettings) OrDefault() PreviewSettings {
+	if s.Format == "" {
+		s.Format = DefaultPreviewSettings.Format
+	}
+	if s.Segments <= 0 {
+		s.Segments = DefaultPreviewSettings.Segments
+	}
+	if s.SegmentLength <= 0 {
+		s.SegmentLength = DefaultPreviewSettings.SegmentLength
+	}
+	if s.Width <= 0 {
+		s.Width = DefaultPreviewSettings.Width
+	}
+	if s.FPS <= 0 {
+		s.FPS = DefaultPreviewSettings.FPS
+	}
+	if s.MaxBytes < 0 {
+		s.MaxBytes = 0
+	}
+	return s
+}
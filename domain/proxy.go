@@ -0,0 +1,37 @@
+// domain/proxy.go
+package domain
+
+// ProxyName is the web-playable rendition among a job's outputs.
+const ProxyName = "proxy.mp4"
+
+// ProxySettings shape the proxy rendition: H.264 video and AAC audio in an
+// MP4 with its index up front, so browsers can start playing and seek
+// before the download ends.
+type ProxySettings struct {
+	// MaxWidth and MaxHeight bound the picture, which keeps its aspect
+	// ratio and is never upscaled.
+	MaxWidth  int
+	MaxHeight int
+	// VideoKbps caps the video bitrate and AudioKbps sets the audio one.
+	VideoKbps int
+	AudioKbps int
+}
+
+var DefaultProxySettings = ProxySettings{MaxWidth: 1280, MaxHeight: 720, VideoKbps: 2500, AudioKbps: 128}
+
+// OrDefault fills in zero values from DefaultProxySettings.
+func (s ProxySettings) OrDefault() ProxySettings {
+	if s.MaxWidth <= 0 {
+		s.MaxWidth = DefaultProxySettings.MaxWidth
+	}
+	if s.MaxHeight <= 0 {
+		s.MaxHeight = DefaultProxySettings.MaxHeight
+	}
+	if s.VideoKbps <= 0 {
+		s.VideoKbps = DefaultProxySettings.VideoKbps
+	}
+	if s.AudioKbps <= 0 {
+		s.AudioKbps = DefaultProxySettings.AudioKbps
+	}
+	return s
+}
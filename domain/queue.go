@@ -0,0 +1,9 @@
+// domain/queue.go
+package domain
+
+// QueueStats is a snapshot of the processing queue.
+type QueueStats struct {
+	Name      string
+	Messages  int
+	Consumers int
+}
@@ -0,0 +1,23 @@
+// domain/quota.go
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrQuotaExceeded  = errors.New("quota exceeded: too many videos waiting or processing")
+	ErrUploadTooLarge = errors.New("upload exceeds the maximum allowed size")
+	ErrInvalidQuota   = errors.New("quota limits must not be negative")
+)
+
+// UserQuota limits what one user may submit. Zero values mean unlimited.
+type UserQuota struct {
+	UserID int
+	// MaxActiveJobs caps videos that are PENDING or PROCESSING at once.
+	MaxActiveJobs int
+	// MaxUploadBytes caps the size of a single upload.
+	MaxUploadBytes int64
+	UpdatedAt      time.Time
+}
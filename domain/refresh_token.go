@@ -0,0 +1,45 @@
+// domain/refresh_token.go
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrInvalidRefreshToken  = errors.New("invalid, expired or revoked refresh token")
+	// ErrRefreshTokenChained is returned when a refresh token is requested
+	// with an access token that was itself minted from one.
+	ErrRefreshTokenChained = errors.New("access tokens issued from a refresh token cannot issue another")
+)
+
+// TokenSubject is who an access token speaks for.
+type TokenSubject struct {
+	UserID      int
+	Username    string
+	Role        Role
+	WorkspaceID int
+}
+
+// RefreshToken is a single-use credential exchanged for a new access token
+// and its own replacement. Each exchange rotates it: the replacement joins
+// the same family and keeps its expiry, so a session ends when its first
+// token would have expired. Only a hash of the secret is kept.
+type RefreshToken struct {
+	ID       int
+	FamilyID string
+	TokenSubject
+	TokenHash string
+	ExpiresAt time.Time
+	// RotatedAt is set once the token was exchanged; presenting it again
+	// revokes the family.
+	RotatedAt *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// Usable reports whether the token may still be exchanged at now.
+func (t *RefreshToken) Usable(now time.Time) bool {
+	return t.RotatedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
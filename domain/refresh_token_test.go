@@ -0,0 +1,27 @@
+// domain/refresh_token_test.go
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRefreshTokenUsable(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Minute)
+	tests := []struct {
+		name  string
+		token RefreshToken
+		want  bool
+	}{
+		{"fresh", RefreshToken{ExpiresAt: now.Add(time.Hour)}, true},
+		{"expired", RefreshToken{ExpiresAt: now}, false},
+		{"rotated", RefreshToken{ExpiresAt: now.Add(time.Hour), RotatedAt: &earlier}, false},
+		{"revoked", RefreshToken{ExpiresAt: now.Add(time.Hour), RevokedAt: &earlier}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.token.Usable(now); got != tt.want {
+			t.Errorf("%s: Usable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// domain/role.go
+package domain
+
+import "fmt"
+
+// Role is the authorization level carried in a user's token. Each role
+// includes the permissions of the ones below it.
+type Role string
+
+const (
+	RoleUser Role = "user"
+	// RoleOperator can inspect every job, the queue and quotas, but can't
+	// fail, requeue or delete jobs or change quotas.
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+)
+
+var roleRank = map[Role]int{RoleUser: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// ParseRole validates a role claim. A missing role means RoleUser.
+func ParseRole(s string) (Role, error) {
+	if s == "" {
+		return RoleUser, nil
+	}
+	role := Role(s)
+	if _, ok := roleRank[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+	return role, nil
+}
+
+// Includes reports whether r grants at least the permissions of other.
+func (r Role) Includes(other Role) bool {
+	return roleRank[r] >= roleRank[other]
+}
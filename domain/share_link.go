@@ -0,0 +1,97 @@
+// domain/share_link.go
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrShareLinkNotFound       = errors.New("share link not found")
+	ErrShareLinkExpired        = errors.New("share link has expired")
+	ErrShareLinkRevoked        = errors.New("share link was revoked")
+	ErrShareLinkExhausted      = errors.New("share link reached its download limit")
+	ErrInvalidShareSignature   = errors.New("invalid share link signature")
+	ErrInvalidShareLinkRequest = errors.New("invalid share link request")
+	ErrShareTransferNotFound   = errors.New("no resumable share link transfer")
+)
+
+// ShareResumeBudget is how many bytes a share link transfer may send again
+// over all its resumes: clients resume from what they saved, which falls
+// short of what was sent when a connection drops with data in flight.
+const ShareResumeBudget = 4 << 20
+
+// ShareLink lets anyone holding its signed URL download a video's artifact
+// without authenticating, until it expires, is revoked or runs out of
+// downloads.
+type ShareLink struct {
+	ID        int
+	VideoID   int
+	CreatedBy int
+	ExpiresAt time.Time
+	// MaxDownloads is zero for links without a download limit.
+	MaxDownloads  int
+	DownloadCount int
+	RevokedAt     *time.Time
+	CreatedAt     time.Time
+}
+
+// Check returns why the link can't be used at now, or nil.
+func (l *ShareLink) Check(now time.Time) error {
+	if err := l.CheckResume(now); err != nil {
+		return err
+	}
+	if l.MaxDownloads > 0 && l.DownloadCount >= l.MaxDownloads {
+		return ErrShareLinkExhausted
+	}
+	return nil
+}
+
+// CheckResume is Check without the download limit, for requests that are
+// not downloads of their own, such as resuming a counted transfer.
+func (l *ShareLink) CheckResume(now time.Time) error {
+	switch {
+	case l.RevokedAt != nil:
+		return ErrShareLinkRevoked
+	case !now.Before(l.ExpiresAt):
+		return ErrShareLinkExpired
+	}
+	return nil
+}
+
+// ShareTransfer is a download counted against a share link. Requests that
+// carry its token continue it without counting again, even once the link is
+// exhausted, but only from where it stopped.
+type ShareTransfer struct {
+	Token       string
+	ShareLinkID int
+	// Checksum and Size describe the artifact being sent; a changed
+	// artifact can't be resumed into.
+	Checksum string
+	Size     int64
+	// Sent is how far the transfer has been sent from the start without
+	// gaps.
+	Sent int64
+	// Resent counts the bytes resumes sent a second time.
+	Resent    int64
+	CreatedAt time.Time
+}
+
+// CanResume reports whether a range of checksum starting at start continues
+// t: the transfer is unfinished, the range leaves no gap after what was sent
+// and what it sends again fits in ShareResumeBudget.
+func (t *ShareTransfer) CanResume(checksum string, start int64) bool {
+	return checksum == t.Checksum && t.Sent < t.Size &&
+		start > 0 && start <= t.Sent && t.Resent+t.Sent-start <= ShareResumeBudget
+}
+
+// ShareAccess records one request made through a share link.
+type ShareAccess struct {
+	ID          int
+	ShareLinkID int
+	RemoteAddr  string
+	UserAgent   string
+	// Outcome is "ok" for served downloads, otherwise why it was refused.
+	Outcome    string
+	AccessedAt time.Time
+}
@@ -0,0 +1,63 @@
+// domain/share_link_test.go
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShareLinkCheck(t *testing.T) {
+	now := time.Now()
+	revoked := now.Add(-time.Minute)
+	tests := []struct {
+		name string
+		link ShareLink
+		want error
+	}{
+		{"usable", ShareLink{ExpiresAt: now.Add(time.Hour)}, nil},
+		{"under limit", ShareLink{ExpiresAt: now.Add(time.Hour), MaxDownloads: 2, DownloadCount: 1}, nil},
+		{"limit reached", ShareLink{ExpiresAt: now.Add(time.Hour), MaxDownloads: 2, DownloadCount: 2}, ErrShareLinkExhausted},
+		{"expired", ShareLink{ExpiresAt: now}, ErrShareLinkExpired},
+		{"revoked", ShareLink{ExpiresAt: now.Add(time.Hour), RevokedAt: &revoked}, ErrShareLinkRevoked},
+		{"revoked and expired", ShareLink{ExpiresAt: now.Add(-time.Hour), RevokedAt: &revoked}, ErrShareLinkRevoked},
+	}
+	for _, tt := range tests {
+		if got := tt.link.Check(now); got != tt.want {
+			t.Errorf("%s: Check = %v, want %v", tt.name, got, tt.want)
+		}
+		// Resuming ignores only the download limit.
+		wantResume := tt.want
+		if wantResume == ErrShareLinkExhausted {
+			wantResume = nil
+		}
+		if got := tt.link.CheckResume(now); got != wantResume {
+			t.Errorf("%s: CheckResume = %v, want %v", tt.name, got, wantResume)
+		}
+	}
+}
+
+func TestShareTransferCanResume(t *testing.T) {
+	const size = 64 << 20
+	tests := []struct {
+		name     string
+		transfer ShareTransfer
+		checksum string
+		start    int64
+		want     bool
+	}{
+		{"where it stopped", ShareTransfer{Checksum: "a", Size: size, Sent: 1000}, "a", 1000, true},
+		{"a little before", ShareTransfer{Checksum: "a", Size: size, Sent: 1000}, "a", 10, true},
+		{"from the start", ShareTransfer{Checksum: "a", Size: size, Sent: 1000}, "a", 0, false},
+		{"past what was sent", ShareTransfer{Checksum: "a", Size: size, Sent: 1000}, "a", 1001, false},
+		{"other artifact", ShareTransfer{Checksum: "a", Size: size, Sent: 1000}, "b", 1000, false},
+		{"finished", ShareTransfer{Checksum: "a", Size: size, Sent: size}, "a", size - 10, false},
+		{"too far back", ShareTransfer{Checksum: "a", Size: size, Sent: 32 << 20}, "a", 1, false},
+		{"budget spent", ShareTransfer{Checksum: "a", Size: size, Sent: 1000, Resent: ShareResumeBudget}, "a", 999, false},
+		{"budget left", ShareTransfer{Checksum: "a", Size: size, Sent: 1000, Resent: ShareResumeBudget - 1}, "a", 999, true},
+	}
+	for _, tt := range tests {
+		if got := tt.transfer.CanResume(tt.checksum, tt.start); got != tt.want {
+			t.Errorf("%s: CanResume = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
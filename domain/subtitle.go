@@ -0,0 +1,67 @@
+// domain/subtitle.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnsupportedSubtitleFormat = errors.New("unsupported subtitle format")
+	// ErrBitmapSubtitles is returned for subtitle streams stored as images,
+	// such as Blu-ray PGS, which cannot be converted to text.
+	ErrBitmapSubtitles = errors.New("subtitle stream is not text")
+)
+
+// SubtitleFormat is the text format subtitle streams are extracted to.
+type SubtitleFormat string
+
+const (
+	SubtitleFormatSRT    SubtitleFormat = "srt"
+	SubtitleFormatWebVTT SubtitleFormat = "vtt"
+)
+
+func ParseSubtitleFormat(s string) (SubtitleFormat, error) {
+	switch f := SubtitleFormat(s); f {
+	case SubtitleFormatSRT, SubtitleFormatWebVTT:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedSubtitleFormat, s)
+	}
+}
+
+func (f SubtitleFormat) ContentType() string {
+	if f == SubtitleFormatSRT {
+		return "application/x-subrip; charset=utf-8"
+	}
+	return "text/vtt; charset=utf-8"
+}
+
+// SubtitleStream describes a subtitle stream embedded in an upload.
+type SubtitleStream struct {
+	// Index is the position of the stream among the subtitle streams of
+	// the file, starting at zero.
+	Index int    `json:"index"`
+	Codec string `json:"codec"`
+	// Language is the stream's language tag, usually ISO 639-2 such as
+	// "por", or empty when untagged.
+	Language string `json:"language,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Default  bool   `json:"default,omitempty"`
+	Forced   bool   `json:"forced,omitempty"`
+}
+
+// textSubtitleCodecs are the ffmpeg subtitle codecs that hold text.
+var textSubtitleCodecs = map[string]bool{
+	"subrip":   true,
+	"ass":      true,
+	"ssa":      true,
+	"mov_text": true,
+	"webvtt":   true,
+	"text":     true,
+}
+
+// IsText reports whether the stream can be extracted to a text format.
+func (s SubtitleStream) IsText() bool {
+	return textSubtitleCodecs[s.Codec]
+}
@@ -6,27 +6,58 @@ import "time"
 type VideoStatus string
 
 const (
-    VideoStatusPending    VideoStatus = "PENDING"
-    VideoStatusProcessing VideoStatus = "PROCESSING"
-    VideoStatusCompleted  VideoStatus = "COMPLETED"
-    VideoStatusFailed     VideoStatus = "FAILED"
+	VideoStatusPending    VideoStatus = "PENDING"
+	VideoStatusProcessing VideoStatus = "PROCESSING"
+	VideoStatusCompleted  VideoStatus = "COMPLETED"
+	VideoStatusFailed     VideoStatus = "FAILED"
 )
 
 type Video struct {
-    ID               int
-    UserID           int
-    OriginalFilename string
-    Status           VideoStatus
-    ProcessedFilePath string
-    ErrorMessage     string
-    CreatedAt        time.Time
-    UpdatedAt        time.Time
+	ID     int
+	UserID int
+	// WorkspaceID is the workspace that owns the video, or zero for a
+	// personal video only its uploader can see.
+	WorkspaceID       int
+	OriginalFilename  string
+	Status            VideoStatus
+	ProcessedFilePath string
+	// ArtifactLayout tells whether ProcessedFilePath is a ZIP or a directory
+	// of frames.
+	ArtifactLayout ArtifactLayout
+	// ProcessedFileChecksum is the hex SHA-256 of the artifact at
+	// ProcessedFilePath. It is empty for artifacts stored before checksums
+	// were recorded until their first download.
+	ProcessedFileChecksum string
+	ErrorMessage          string
+	// Claim changes whenever a worker claims the video or the video is taken
+	// from its worker. Worker writes name the claim they hold, so a job that
+	// was failed or superseded can't overwrite a later attempt.
+	Claim int
+	// ContentHash is the hex SHA-256 of the uploaded file.
+	ContentHash        string
+	ExtractionSettings ExtractionSettings
+	// SourcePath is where the upload is stored until processing succeeds, or
+	// for as long as the video exists when sources are kept; it lets a failed
+	// job be requeued and frames be grabbed on demand.
+	SourcePath string
+	// ReusedFromVideoID is set when the artifact was taken from an earlier
+	// job over identical content instead of being processed again.
+	ReusedFromVideoID int
+	// Audio is set by jobs run with audio outputs enabled.
+	Audio *AudioAnalysis
+	// SubtitleStreams lists the subtitle streams found in the upload by
+	// jobs that probe for them.
+	SubtitleStreams []SubtitleStream
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
 type VideoProcessingMessage struct {
-    UserID            int
-    VideoPath         string
-    OriginalFilename  string
-    ProcessingStarted time.Time
-    VideoStatusID     int
-}
\ No newline at end of file
+	UserID            int                `json:"user_id"`
+	VideoPath         string             `json:"video_path"`
+	OriginalFilename  string             `json:"original_filename"`
+	ProcessingStarted time.Time          `json:"processing_started"`
+	VideoStatusID     int                `json:"video_status_id"`
+	Attempt           int                `json:"attempt"`
+	Settings          ExtractionSettings `json:"settings"`
+}
@@ -0,0 +1,18 @@
+// domain/video_event.go
+package domain
+
+import "time"
+
+// VideoEvent is one entry in the append-only history of a video's state
+// transitions.
+type VideoEvent struct {
+	ID           int
+	VideoID      int
+	FromStatus   VideoStatus
+	ToStatus     VideoStatus
+	WorkerID     string
+	Attempt      int
+	Duration     time.Duration
+	ErrorMessage string
+	CreatedAt    time.Time
+}
@@ -0,0 +1,66 @@
+// domain/video_status.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTransition is matched by every InvalidTransitionError.
+var ErrInvalidTransition = errors.New("invalid video status transition")
+
+// allowedTransitions is the video lifecycle. COMPLETED is terminal; a FAILED
+// video may only go back to PENDING to be queued again.
+var allowedTransitions = map[VideoStatus][]VideoStatus{
+	VideoStatusPending:    {VideoStatusProcessing, VideoStatusFailed},
+	VideoStatusProcessing: {VideoStatusCompleted, VideoStatusFailed},
+	VideoStatusFailed:     {VideoStatusPending},
+}
+
+// InvalidTransitionError reports a status change that the lifecycle forbids,
+// or whose expected current status no longer matches the stored one.
+type InvalidTransitionError struct {
+	VideoID int
+	From    VideoStatus
+	To      VideoStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("video %d: cannot transition from %s to %s", e.VideoID, e.From, e.To)
+}
+
+func (e *InvalidTransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+func (s VideoStatus) CanTransitionTo(next VideoStatus) bool {
+	for _, allowed := range allowedTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid reports whether s is one of the lifecycle statuses.
+func (s VideoStatus) Valid() bool {
+	switch s {
+	case VideoStatusPending, VideoStatusProcessing, VideoStatusCompleted, VideoStatusFailed:
+		return true
+	}
+	return false
+}
+
+// IsTerminal reports whether no further transition is allowed from s.
+func (s VideoStatus) IsTerminal() bool {
+	return len(allowedTransitions[s]) == 0
+}
+
+// ValidateTransition returns an *InvalidTransitionError when from -> to is
+// not part of the lifecycle.
+func ValidateTransition(videoID int, from, to VideoStatus) error {
+	if !from.CanTransitionTo(to) {
+		return &InvalidTransitionError{VideoID: videoID, From: from, To: to}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+// domain/video_status_test.go
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from, to VideoStatus
+		ok       bool
+	}{
+		{VideoStatusPending, VideoStatusProcessing, true},
+		{VideoStatusPending, VideoStatusFailed, true},
+		{VideoStatusProcessing, VideoStatusCompleted, true},
+		{VideoStatusProcessing, VideoStatusFailed, true},
+		{VideoStatusFailed, VideoStatusPending, true},
+		{VideoStatusCompleted, VideoStatusProcessing, false},
+		{VideoStatusCompleted, VideoStatusPending, false},
+		{VideoStatusPending, VideoStatusCompleted, false},
+		{VideoStatusProcessing, VideoStatusProcessing, false},
+	}
+	for _, tt := range tests {
+		err := ValidateTransition(1, tt.from, tt.to)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s -> %s: err = %v, want ok = %v", tt.from, tt.to, err, tt.ok)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s -> %s: error %v does not match ErrInvalidTransition", tt.from, tt.to, err)
+		}
+	}
+	if !VideoStatusCompleted.IsTerminal() || VideoStatusFailed.IsTerminal() {
+		t.Error("only COMPLETED should be terminal")
+	}
+}
@@ -0,0 +1,65 @@
+// domain/workspace.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrWorkspaceNotFound         = errors.New("workspace not found")
+	ErrNotWorkspaceMember        = errors.New("not a member of the workspace")
+	ErrLastWorkspaceOwner        = errors.New("a workspace must keep at least one owner")
+	ErrInvalidWorkspaceRequest   = errors.New("invalid workspace request")
+	ErrInsufficientWorkspaceRole = errors.New("workspace role does not allow this action")
+)
+
+// WorkspaceRole is a member's level within one workspace. Each role includes
+// the permissions of the ones below it.
+type WorkspaceRole string
+
+const (
+	// WorkspaceRoleViewer can list and download the workspace's videos.
+	WorkspaceRoleViewer WorkspaceRole = "viewer"
+	// WorkspaceRoleMember can also upload and reprocess videos.
+	WorkspaceRoleMember WorkspaceRole = "member"
+	// WorkspaceRoleOwner can also manage memberships.
+	WorkspaceRoleOwner WorkspaceRole = "owner"
+)
+
+var workspaceRoleRank = map[WorkspaceRole]int{WorkspaceRoleViewer: 1, WorkspaceRoleMember: 2, WorkspaceRoleOwner: 3}
+
+func ParseWorkspaceRole(s string) (WorkspaceRole, error) {
+	role := WorkspaceRole(s)
+	if _, ok := workspaceRoleRank[role]; !ok {
+		return "", fmt.Errorf("%w: unknown workspace role %q", ErrInvalidWorkspaceRequest, s)
+	}
+	return role, nil
+}
+
+// Includes reports whether r grants at least the permissions of other.
+func (r WorkspaceRole) Includes(other WorkspaceRole) bool {
+	return workspaceRoleRank[r] >= workspaceRoleRank[other]
+}
+
+// Workspace groups users who share videos. Videos uploaded while a
+// workspace is active belong to it rather than to the uploader alone.
+type Workspace struct {
+	ID        int
+	Name      string
+	CreatedAt time.Time
+}
+
+type WorkspaceMember struct {
+	WorkspaceID int
+	UserID      int
+	Role        WorkspaceRole
+	CreatedAt   time.Time
+}
+
+// WorkspaceMembership is a workspace as seen by one of its members.
+type WorkspaceMembership struct {
+	Workspace Workspace
+	Role      WorkspaceRole
+}
@@ -0,0 +1,433 @@
+// e2e/admin_test.go
+package e2e
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/infrastructure"
+	"github.com/vitovidale/video-processor-service/infrastructure/memory"
+)
+
+func TestAdminRoutesRequireRole(t *testing.T) {
+	h := newHarness(t)
+	user := h.token(1)
+	operator := h.tokenWithRole(2, domain.RoleOperator)
+	admin := h.tokenWithRole(3, domain.RoleAdmin)
+	id := h.uploadOK(user, "clip.mp4", []byte("video"))
+	h.waitForStatus(user, id, "COMPLETED")
+
+	tests := []struct {
+		method, path string
+		body         string
+		want         map[string]int
+	}{
+		{http.MethodGet, "/admin/videos", "", map[string]int{user: 403, operator: 200, admin: 200}},
+		{http.MethodGet, "/admin/queue", "", map[string]int{user: 403, operator: 200, admin: 200}},
+		{http.MethodGet, "/admin/users/1/quota", "", map[string]int{user: 403, operator: 200, admin: 200}},
+		{http.MethodPut, "/admin/users/1/quota", `{"max_active_jobs": 5}`, map[string]int{user: 403, operator: 403, admin: 200}},
+		{http.MethodPost, fmt.Sprintf("/admin/videos/%d/requeue", id), "", map[string]int{user: 403, operator: 403, admin: 409}},
+		{http.MethodPost, fmt.Sprintf("/admin/videos/%d/fail", id), "", map[string]int{user: 403, operator: 403, admin: 409}},
+		{http.MethodDelete, fmt.Sprintf("/admin/videos/%d", id), "", map[string]int{user: 403, operator: 403, admin: 204}},
+	}
+	for _, tt := range tests {
+		for _, token := range []string{user, operator, admin} {
+			resp := h.do(tt.method, tt.path, token, strings.NewReader(tt.body), "application/json")
+			if resp.StatusCode != tt.want[token] {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want[token])
+			}
+		}
+	}
+
+	if resp := h.do(http.MethodGet, "/admin/videos", h.tokenWithRole(1, "root"), nil, ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("token with unknown role = %d, want 401", resp.StatusCode)
+	}
+	key := h.createAPIKey(user, `{"scopes": ["read"]}`).Key
+	if resp := h.withAPIKey(http.MethodGet, "/admin/videos", key); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("admin route with api key = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestAdminListsEveryUsersVideos(t *testing.T) {
+	h := newHarness(t)
+	h.processor.Err = errors.New("boom")
+	first := h.uploadOK(h.token(1), "a.mp4", []byte("a"))
+	second := h.uploadOK(h.token(2), "b.mp4", []byte("b"))
+	h.waitForStatus(h.token(1), first, "FAILED")
+	h.waitForStatus(h.token(2), second, "FAILED")
+	operator := h.tokenWithRole(9, domain.RoleOperator)
+
+	list := func(query string) []infrastructure.AdminVideoResponse {
+		resp := h.do(http.MethodGet, "/admin/videos"+query, operator, nil, "")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("list %q = %d: %s", query, resp.StatusCode, readAll(t, resp))
+		}
+		var videos []infrastructure.AdminVideoResponse
+		decodeJSON(t, resp, &videos)
+		return videos
+	}
+	if videos := list(""); len(videos) != 2 {
+		t.Errorf("all videos = %+v, want 2", videos)
+	}
+	if videos := list("?user_id=2&status=FAILED"); len(videos) != 1 || videos[0].ID != second || videos[0].UserID != 2 {
+		t.Errorf("filtered videos = %+v, want only video %d", videos, second)
+	}
+	if videos := list("?status=COMPLETED"); len(videos) != 0 {
+		t.Errorf("completed videos = %+v, want none", videos)
+	}
+	if videos := list("?limit=1&offset=1"); len(videos) != 1 {
+		t.Errorf("paged videos = %+v, want 1", videos)
+	}
+	if resp := h.do(http.MethodGet, "/admin/videos?status=DONE", operator, nil, ""); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown status filter = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestAdminRequeuesFailedVideo(t *testing.T) {
+	h := newHarness(t)
+	h.processor.Err = errors.New("boom")
+	token := h.token(1)
+	admin := h.tokenWithRole(9, domain.RoleAdmin)
+
+	id := h.uploadOK(token, "flaky.mp4", []byte("video"))
+	h.waitForStatus(token, id, "FAILED")
+
+	// Only failed videos can be requeued.
+	h.processor.Err = nil
+	resp := h.do(http.MethodPost, fmt.Sprintf("/admin/videos/%d/requeue", id), admin, nil, "")
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("requeue = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	h.waitForStatus(token, id, "COMPLETED")
+	if resp := h.do(http.MethodPost, fmt.Sprintf("/admin/videos/%d/requeue", id), admin, nil, ""); resp.StatusCode != http.StatusConflict {
+		t.Errorf("requeue of completed video = %d, want 409", resp.StatusCode)
+	}
+
+	events, _ := h.events.FindByVideoID(id)
+	var requeued bool
+	for _, e := range events {
+		if e.FromStatus == domain.VideoStatusFailed && e.ToStatus == domain.VideoStatusPending {
+			requeued = e.WorkerID == "user:9" && e.Attempt == 2
+		}
+	}
+	if !requeued {
+		t.Errorf("events = %+v, want a FAILED -> PENDING event by user:9 for attempt 2", events)
+	}
+	if published := h.queue.Published(); len(published) != 2 || published[1].Attempt != 2 {
+		t.Errorf("published = %+v, want a second message for attempt 2", published)
+	}
+}
+
+func TestRequeueDropsFramesOfTheFailedAttempt(t *testing.T) {
+	h := newHarness(t)
+	h.processor.Err = errors.New("boom")
+	token := h.token(1)
+	admin := h.tokenWithRole(9, domain.RoleAdmin)
+
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "FAILED")
+	// Frames a longer run wrote before failing after extraction.
+	failed, _ := h.repo.FindByID(id)
+	outputDir, _ := h.storage.OutputDir(1, id, failed.Claim)
+	for _, name := range []string{"clip.mp4_0001.png", "clip.mp4_0004.png", "clip.mp4_0005.png"} {
+		h.storage.WriteFile(path.Join(outputDir, name), []byte("stale"))
+	}
+
+	h.processor.Err = nil
+	if resp := h.do(http.MethodPost, fmt.Sprintf("/admin/videos/%d/requeue", id), admin, nil, ""); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("requeue = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	h.waitForStatus(token, id, "COMPLETED")
+	frames := h.listFrames(token, id)
+	if len(frames) != 3 {
+		t.Fatalf("frames = %+v, want the 3 of the new run", frames)
+	}
+	for _, f := range frames {
+		if f.SizeBytes == int64(len("stale")) {
+			t.Errorf("frame %d is left over from the failed attempt", f.Index)
+		}
+	}
+}
+
+func TestAdminForceFailsStuckVideo(t *testing.T) {
+	h := newHarness(t)
+	admin := h.tokenWithRole(9, domain.RoleAdmin)
+
+	// A job whose worker died mid-processing.
+	stuck := &domain.Video{UserID: 1, OriginalFilename: "stuck.mp4", Status: domain.VideoStatusProcessing, SourcePath: "uploads/stuck.mp4"}
+	if err := h.repo.Save(stuck); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := h.do(http.MethodPost, fmt.Sprintf("/admin/videos/%d/fail", stuck.ID), admin, strings.NewReader(`{"reason": "worker lost"}`), "application/json")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("fail = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	var video infrastructure.AdminVideoResponse
+	decodeJSON(t, resp, &video)
+	if video.Status != "FAILED" || video.ErrorMessage != "worker lost" {
+		t.Errorf("failed video = %+v", video)
+	}
+
+	if resp := h.do(http.MethodPost, fmt.Sprintf("/admin/videos/%d/fail", stuck.ID), admin, nil, ""); resp.StatusCode != http.StatusConflict {
+		t.Errorf("failing a failed video = %d, want 409", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodPost, "/admin/videos/999/fail", admin, nil, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("failing an unknown video = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestSupersededWorkerCannotOverwriteTheNextAttempt(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	admin := h.tokenWithRole(9, domain.RoleAdmin)
+	hold := make(chan struct{})
+	h.processor.Hold = hold
+
+	// The worker hangs in ffmpeg; an admin gives up on it and the job runs
+	// again on another worker.
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "PROCESSING")
+	if resp := h.do(http.MethodPost, fmt.Sprintf("/admin/videos/%d/fail", id), admin, nil, ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("fail = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	if resp := h.do(http.MethodPost, fmt.Sprintf("/admin/videos/%d/requeue", id), admin, nil, ""); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("requeue = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	second := *h.worker
+	second.WorkerID = "second-worker"
+	second.Processor = memory.NewVideoProcessor(h.storage, 2)
+	published := h.queue.Published()
+	second.Execute(context.Background(), published[len(published)-1])
+	done := h.waitForStatus(token, id, "COMPLETED")
+
+	// The consumer runs one job at a time, so once a later upload completes
+	// the hung job has finished as well.
+	close(hold)
+	later := h.uploadOK(token, "later.mp4", []byte("later"))
+	h.waitForStatus(token, later, "COMPLETED")
+
+	video, _ := h.repo.FindByID(id)
+	if video.Status != domain.VideoStatusCompleted || video.ProcessedFilePath != done.ProcessedFilePath || video.ProcessedFileChecksum != done.ProcessedFileChecksum {
+		t.Errorf("video = %+v, want the result of the second worker %+v", video, done)
+	}
+	// Each attempt wrote to a directory of its own, and the superseded one
+	// dropped its files.
+	if frames := h.listFrames(token, id); len(frames) != 2 {
+		t.Errorf("frames = %+v, want the 2 of the second worker", frames)
+	}
+	zipped, _ := h.storage.ReadFile(video.ProcessedFilePath)
+	if sum := sha256.Sum256(zipped); hex.EncodeToString(sum[:]) != video.ProcessedFileChecksum {
+		t.Error("artifact was overwritten after the second worker completed")
+	}
+	stale, _ := h.storage.OutputDir(1, id, 1)
+	for _, p := range h.storage.Paths() {
+		if strings.HasPrefix(p, stale+"/") {
+			t.Errorf("superseded attempt left %s behind", p)
+		}
+	}
+	events, _ := h.events.FindByVideoID(id)
+	for _, e := range events {
+		if e.WorkerID == "test-worker" && e.FromStatus == domain.VideoStatusProcessing {
+			t.Errorf("superseded worker recorded %+v", e)
+		}
+	}
+}
+
+func TestAdminDeletesVideo(t *testing.T) {
+	h := newHarness(t)
+	h.uploadUC.DedupScope = "global"
+	admin := h.tokenWithRole(9, domain.RoleAdmin)
+
+	original := h.uploadOK(h.token(1), "clip.mp4", []byte("same content"))
+	status := h.waitForStatus(h.token(1), original, "COMPLETED")
+	duplicate := h.uploadOK(h.token(2), "copy.mp4", []byte("same content"))
+
+	// The artifact is shared, so deleting the original keeps it on disk.
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/admin/videos/%d", original), admin, nil, ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	if _, ok := h.storage.ReadFile(status.ProcessedFilePath); !ok {
+		t.Fatal("shared artifact removed while still in use")
+	}
+	if len(h.statuses(h.token(1))) != 0 {
+		t.Error("deleted video still listed")
+	}
+
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/admin/videos/%d", duplicate), admin, nil, ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete duplicate = %d", resp.StatusCode)
+	}
+	if _, ok := h.storage.ReadFile(status.ProcessedFilePath); ok {
+		t.Error("artifact kept after its last video was deleted")
+	}
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/admin/videos/%d", duplicate), admin, nil, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("second delete = %d, want 404", resp.StatusCode)
+	}
+
+	processing := &domain.Video{UserID: 1, OriginalFilename: "busy.mp4", Status: domain.VideoStatusProcessing}
+	h.repo.Save(processing)
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/admin/videos/%d", processing.ID), admin, nil, ""); resp.StatusCode != http.StatusConflict {
+		t.Errorf("delete of processing video = %d, want 409", resp.StatusCode)
+	}
+}
+
+func TestQueueStats(t *testing.T) {
+	h := newHarness(t)
+	resp := h.do(http.MethodGet, "/admin/queue", h.tokenWithRole(9, domain.RoleOperator), nil, "")
+	var stats infrastructure.QueueStatsResponse
+	decodeJSON(t, resp, &stats)
+	if resp.StatusCode != http.StatusOK || stats.Queue == "" || stats.Consumers != 1 {
+		t.Errorf("queue stats = %d %+v", resp.StatusCode, stats)
+	}
+}
+
+func TestUploadQuotas(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	admin := h.tokenWithRole(9, domain.RoleAdmin)
+
+	resp := h.do(http.MethodPut, "/admin/users/1/quota", admin, strings.NewReader(`{"max_active_jobs": 1, "max_upload_bytes": 8}`), "application/json")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("set quota = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	resp = h.do(http.MethodGet, "/admin/users/1/quota", admin, nil, "")
+	var quota infrastructure.UserQuotaResponse
+	decodeJSON(t, resp, &quota)
+	if quota.MaxActiveJobs != 1 || quota.MaxUploadBytes != 8 || quota.UpdatedAt == nil {
+		t.Errorf("quota = %+v", quota)
+	}
+
+	if resp := h.upload(token, "big.mp4", []byte("123456789")); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized upload = %d, want 413", resp.StatusCode)
+	}
+	if len(h.storage.Paths()) != 0 {
+		t.Errorf("rejected upload left files behind: %v", h.storage.Paths())
+	}
+
+	pending := &domain.Video{UserID: 1, OriginalFilename: "queued.mp4", Status: domain.VideoStatusPending}
+	h.repo.Save(pending)
+	if resp := h.upload(token, "more.mp4", []byte("1234")); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("upload over active job quota = %d, want 429", resp.StatusCode)
+	}
+	// Other users keep the default, unlimited quota.
+	h.uploadOK(h.token(2), "other.mp4", []byte("123456789"))
+
+	if resp := h.do(http.MethodPut, "/admin/users/1/quota", admin, strings.NewReader(`{"max_active_jobs": -1}`), "application/json"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("negative quota = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestQuotaDoesNotBlockIdempotentReplay(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	h.quotas.Set(&domain.UserQuota{UserID: 1, MaxActiveJobs: 1})
+	key := map[string]string{"Idempotency-Key": "retry-1"}
+
+	first := h.uploadWithHeaders(token, "clip.mp4", []byte("same bytes"), key)
+	var accepted struct {
+		VideoStatusID int `json:"video_status_id"`
+	}
+	decodeJSON(t, first, &accepted)
+	h.waitForStatus(token, accepted.VideoStatusID, "COMPLETED")
+
+	// The user is at the limit when the retry arrives; the retry still
+	// gets the job it already created.
+	h.repo.Save(&domain.Video{UserID: 1, OriginalFilename: "queued.mp4", Status: domain.VideoStatusPending})
+	retry := h.uploadWithHeaders(token, "clip.mp4", []byte("same bytes"), key)
+	if retry.StatusCode != http.StatusOK || retry.Header.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry at the limit = %d: %s", retry.StatusCode, readAll(t, retry))
+	}
+	var replayed struct {
+		VideoStatusID int `json:"video_status_id"`
+	}
+	decodeJSON(t, retry, &replayed)
+	if replayed.VideoStatusID != accepted.VideoStatusID {
+		t.Errorf("replayed video %d, want %d", replayed.VideoStatusID, accepted.VideoStatusID)
+	}
+
+	// A new key is a new upload and is refused, without leaving its file.
+	before := len(h.storage.Paths())
+	if resp := h.uploadWithHeaders(token, "other.mp4", []byte("other bytes"), map[string]string{"Idempotency-Key": "retry-2"}); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("new key at the limit = %d, want 429", resp.StatusCode)
+	}
+	if after := len(h.storage.Paths()); after != before {
+		t.Errorf("refused upload left %d files behind", after-before)
+	}
+}
+
+func TestRequeueRespectsActiveJobQuota(t *testing.T) {
+	h := newHarness(t)
+	h.processor.Err = errors.New("boom")
+	token := h.token(1)
+	admin := h.tokenWithRole(9, domain.RoleAdmin)
+
+	id := h.uploadOK(token, "flaky.mp4", []byte("video"))
+	h.waitForStatus(token, id, "FAILED")
+	h.quotas.Set(&domain.UserQuota{UserID: 1, MaxActiveJobs: 1})
+	h.repo.Save(&domain.Video{UserID: 1, OriginalFilename: "queued.mp4", Status: domain.VideoStatusPending})
+
+	if resp := h.do(http.MethodPost, fmt.Sprintf("/videos/%d/reprocess", id), token, nil, ""); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("reprocess over active job quota = %d, want 429", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodPost, fmt.Sprintf("/admin/videos/%d/requeue", id), admin, nil, ""); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("requeue over active job quota = %d, want 429", resp.StatusCode)
+	}
+	if video, _ := h.repo.FindByID(id); video.Status != domain.VideoStatusFailed {
+		t.Errorf("status = %s, want the refused video still FAILED", video.Status)
+	}
+	if published := h.queue.Published(); len(published) != 1 {
+		t.Errorf("published %d messages, want only the upload's", len(published))
+	}
+}
+
+func TestActiveJobQuotaHoldsUnderConcurrentUploads(t *testing.T) {
+	h := newHarness(t)
+	hold := make(chan struct{})
+	h.processor.Hold = hold
+	t.Cleanup(func() { close(hold) })
+	h.quotas.Set(&domain.UserQuota{UserID: 1, MaxActiveJobs: 2})
+	token := h.token(1)
+
+	const uploads = 8
+	requests := make([]*http.Request, uploads)
+	for i := range requests {
+		requests[i] = h.uploadRequest(token, fmt.Sprintf("clip%d.mp4", i), []byte(fmt.Sprintf("video %d", i)), nil)
+	}
+	statuses := make(chan int, uploads)
+	var wg sync.WaitGroup
+	for _, req := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := h.server.Client().Do(req)
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	counts := map[int]int{}
+	for status := range statuses {
+		counts[status]++
+	}
+	if counts[http.StatusOK] != 2 || counts[http.StatusTooManyRequests] != uploads-2 {
+		t.Errorf("statuses = %v, want 2 accepted and %d refused", counts, uploads-2)
+	}
+	if active, _ := h.repo.CountActiveByUserID(1); active != 2 {
+		t.Errorf("active videos = %d, want 2", active)
+	}
+}
@@ -0,0 +1,136 @@
+// e2e/api_key_test.go
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/infrastructure"
+)
+
+// createAPIKey creates a key for the owner of token and returns it.
+func (h *harness) createAPIKey(token, body string) infrastructure.APIKeyResponse {
+	h.t.Helper()
+	resp := h.do(http.MethodPost, "/api-keys", token, strings.NewReader(body), "application/json")
+	if resp.StatusCode != http.StatusCreated {
+		h.t.Fatalf("create api key = %d: %s", resp.StatusCode, readAll(h.t, resp))
+	}
+	var key infrastructure.APIKeyResponse
+	decodeJSON(h.t, resp, &key)
+	return key
+}
+
+// withAPIKey performs a request authenticated with an API key.
+func (h *harness) withAPIKey(method, path, key string) *http.Response {
+	h.t.Helper()
+	req, err := http.NewRequest(method, h.server.URL+path, nil)
+	if err != nil {
+		h.t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set(infrastructure.APIKeyHeader, key)
+	return h.send(req)
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+
+	created := h.createAPIKey(token, `{"name": "nightly batch", "scopes": ["upload", "read"]}`)
+	if !strings.HasPrefix(created.Key, "vps_") || !strings.HasPrefix(created.Key, created.Prefix) {
+		t.Fatalf("created key = %+v, want a vps_ secret starting with its prefix", created)
+	}
+
+	// The key works for uploads and reads in place of a JWT.
+	resp := h.uploadWithHeaders("", "batch.mp4", []byte("video"), map[string]string{infrastructure.APIKeyHeader: created.Key})
+	var uploaded struct {
+		VideoStatusID int `json:"video_status_id"`
+	}
+	decodeJSON(t, resp, &uploaded)
+	resp = h.withAPIKey(http.MethodGet, "/videos/status", created.Key)
+	var statuses []infrastructure.VideoStatusResponse
+	decodeJSON(t, resp, &statuses)
+	if resp.StatusCode != http.StatusOK || len(statuses) != 1 || statuses[0].ID != uploaded.VideoStatusID {
+		t.Fatalf("status with api key = %d %+v", resp.StatusCode, statuses)
+	}
+
+	// Listing never returns the secret, but shows last use.
+	resp = h.do(http.MethodGet, "/api-keys", token, nil, "")
+	var keys []infrastructure.APIKeyResponse
+	decodeJSON(t, resp, &keys)
+	if len(keys) != 1 || keys[0].Key != "" || keys[0].LastUsedAt == nil || keys[0].Name != "nightly batch" {
+		t.Fatalf("listed keys = %+v", keys)
+	}
+	stored, _ := h.apiKeys.FindByUserID(1)
+	if stored[0].KeyHash == created.Key {
+		t.Error("api key stored in plaintext")
+	}
+
+	// Other users can't see or revoke it.
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/api-keys/%d", created.ID), h.token(2), nil, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("revoke by another user = %d, want 404", resp.StatusCode)
+	}
+
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/api-keys/%d", created.ID), token, nil, ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoke = %d, want 204", resp.StatusCode)
+	}
+	if resp := h.withAPIKey(http.MethodGet, "/videos/status", created.Key); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("revoked key = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+
+	readOnly := h.createAPIKey(token, `{"scopes": ["read"]}`).Key
+	uploadOnly := h.createAPIKey(token, `{"scopes": ["upload"]}`).Key
+
+	if resp := h.uploadWithHeaders("", "a.mp4", []byte("x"), map[string]string{infrastructure.APIKeyHeader: readOnly}); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("upload with read-only key = %d, want 403", resp.StatusCode)
+	}
+	if resp := h.withAPIKey(http.MethodGet, "/videos/status", readOnly); resp.StatusCode != http.StatusOK {
+		t.Errorf("status with read-only key = %d, want 200", resp.StatusCode)
+	}
+	if resp := h.uploadWithHeaders("", "a.mp4", []byte("x"), map[string]string{infrastructure.APIKeyHeader: uploadOnly}); resp.StatusCode != http.StatusOK {
+		t.Errorf("upload with upload-only key = %d, want 200", resp.StatusCode)
+	}
+	if resp := h.withAPIKey(http.MethodGet, "/videos/status", uploadOnly); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("status with upload-only key = %d, want 403", resp.StatusCode)
+	}
+
+	// API keys can never manage API keys.
+	if resp := h.withAPIKey(http.MethodGet, "/api-keys", readOnly); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("listing keys with an api key = %d, want 403", resp.StatusCode)
+	}
+	for _, body := range []string{
+		`{"scopes": []}`,
+		`{"scopes": ["api_keys"]}`,
+		`{"scopes": ["admin"]}`,
+		fmt.Sprintf(`{"scopes": ["read"], "expires_at": %q}`, time.Now().Add(-time.Hour).Format(time.RFC3339)),
+	} {
+		if resp := h.do(http.MethodPost, "/api-keys", token, strings.NewReader(body), "application/json"); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("create %s = %d, want 400", body, resp.StatusCode)
+		}
+	}
+}
+
+func TestExpiredAPIKeyIsRejected(t *testing.T) {
+	h := newHarness(t)
+
+	expiresAt := time.Now().Add(time.Second)
+	created := h.createAPIKey(h.token(1), fmt.Sprintf(`{"scopes": ["read"], "expires_at": %q}`, expiresAt.Format(time.RFC3339Nano)))
+	if resp := h.withAPIKey(http.MethodGet, "/videos/status", created.Key); resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpired key = %d, want 200", resp.StatusCode)
+	}
+
+	time.Sleep(time.Until(expiresAt) + 50*time.Millisecond)
+	if resp := h.withAPIKey(http.MethodGet, "/videos/status", created.Key); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expired key = %d, want 401", resp.StatusCode)
+	}
+	if resp := h.withAPIKey(http.MethodGet, "/videos/status", "vps_not-a-real-key"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unknown key = %d, want 401", resp.StatusCode)
+	}
+}
@@ -0,0 +1,623 @@
+// e2e/api_test.go
+package e2e
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/infrastructure"
+	"github.com/vitovidale/video-processor-service/logging"
+	"github.com/vitovidale/video-processor-service/usecase"
+)
+
+func TestUploadProcessDownload(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+
+	id := h.uploadOK(token, "clip.mp4", []byte("fake video bytes"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+	if status.OriginalFilename != "clip.mp4" {
+		t.Errorf("original_filename = %q", status.OriginalFilename)
+	}
+
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/download", id), token, nil, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("download status = %d", resp.StatusCode)
+	}
+	data := readAll(t, resp)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	want := []string{"manifest.json", "clip.mp4_0001.png", "clip.mp4_0002.png", "clip.mp4_0003.png"}
+	if len(names) != len(want) {
+		t.Fatalf("zip entries = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("zip entry %d = %q, want %q", i, names[i], want[i])
+		}
+	}
+
+	// The upload is kept for frame grabs and the frames for the frame API;
+	// nothing else is left behind.
+	video, _ := h.repo.FindByID(id)
+	kept := map[string]bool{status.ProcessedFilePath: true, video.SourcePath: true}
+	frames, _ := h.frames.FindByArtifact(status.ProcessedFilePath)
+	for _, f := range frames {
+		kept[f.Path] = true
+	}
+	for _, p := range h.storage.Paths() {
+		if !kept[p] {
+			t.Errorf("leftover file in storage: %s", p)
+		}
+	}
+}
+
+func TestProcessingFailureIsReported(t *testing.T) {
+	h := newHarness(t)
+	h.processor.Err = errors.New("boom")
+	token := h.token(1)
+
+	id := h.uploadOK(token, "broken.mp4", []byte("x"))
+	status := h.waitForStatus(token, id, "FAILED")
+	if status.ErrorMessage == "" {
+		t.Error("expected error_message on failed video")
+	}
+
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/download", id), token, nil, "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("download of failed video = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestUnqueuedUploadIsFailed(t *testing.T) {
+	h := newHarness(t)
+	h.queue.Err = errors.New("broker down")
+	token := h.token(1)
+
+	if resp := h.upload(token, "clip.mp4", []byte("video")); resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("upload with the queue down = %d, want 500", resp.StatusCode)
+	}
+	statuses := h.statuses(token)
+	if len(statuses) != 1 || statuses[0].Status != "FAILED" {
+		t.Fatalf("statuses = %+v, want the video failed", statuses)
+	}
+	if active, _ := h.repo.CountActiveByUserID(1); active != 0 {
+		t.Errorf("active videos = %d, want the unqueued video not to count", active)
+	}
+	if paths := h.storage.Paths(); len(paths) != 0 {
+		t.Errorf("unqueued upload left files behind: %v", paths)
+	}
+}
+
+func TestDownloadRequiresOwnership(t *testing.T) {
+	h := newHarness(t)
+	owner := h.token(1)
+
+	id := h.uploadOK(owner, "mine.mp4", []byte("x"))
+	h.waitForStatus(owner, id, "COMPLETED")
+
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/download", id), h.token(2), nil, "")
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("download by other user = %d, want 403", resp.StatusCode)
+	}
+	if got := h.statuses(h.token(2)); len(got) != 0 {
+		t.Errorf("other user sees %d videos, want 0", len(got))
+	}
+
+	// Ownership is checked before status, so other users can't probe it.
+	pending := &domain.Video{UserID: 1, OriginalFilename: "queued.mp4", Status: domain.VideoStatusPending}
+	h.repo.Save(pending)
+	if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/download", pending.ID), h.token(2), nil, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("download of other user's pending video = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestAuthRequired(t *testing.T) {
+	h := newHarness(t)
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodPost, "/upload"},
+		{http.MethodGet, "/videos/status"},
+		{http.MethodGet, "/videos/1/download"},
+	} {
+		if resp := h.do(tc.method, tc.path, "", nil, ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s %s without token = %d, want 401", tc.method, tc.path, resp.StatusCode)
+		}
+		if resp := h.do(tc.method, tc.path, "not-a-jwt", nil, ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s %s with bad token = %d, want 401", tc.method, tc.path, resp.StatusCode)
+		}
+	}
+}
+
+func TestUploadWithoutFile(t *testing.T) {
+	h := newHarness(t)
+
+	resp := h.do(http.MethodPost, "/upload", h.token(1), nil, "")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("upload without file = %d, want 400", resp.StatusCode)
+	}
+	if n := len(h.queue.Published()); n != 0 {
+		t.Errorf("published %d messages, want 0", n)
+	}
+}
+
+func TestVideoEventsHistory(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+
+	id := h.uploadOK(token, "clip.mp4", []byte("x"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/events", id), token, nil, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("events status = %d", resp.StatusCode)
+	}
+	var events []infrastructure.VideoEventResponse
+	decodeJSON(t, resp, &events)
+
+	want := []struct{ from, to string }{{"", "PENDING"}, {"PENDING", "PROCESSING"}, {"PROCESSING", "COMPLETED"}}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if events[i].FromStatus != w.from || events[i].ToStatus != w.to {
+			t.Errorf("event %d = %s->%s, want %s->%s", i, events[i].FromStatus, events[i].ToStatus, w.from, w.to)
+		}
+	}
+	if events[2].WorkerID != "test-worker" || events[2].Attempt != 1 {
+		t.Errorf("completion event worker=%q attempt=%d", events[2].WorkerID, events[2].Attempt)
+	}
+
+	if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/events", id), h.token(2), nil, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("events for other user = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestDuplicateDeliveryIsIgnored(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+
+	id := h.uploadOK(token, "clip.mp4", []byte("x"))
+	completed := h.waitForStatus(token, id, "COMPLETED")
+	eventsBefore, _ := h.events.FindByVideoID(id)
+
+	// Redeliver the original job, then upload another video: the queue is
+	// FIFO, so once the second video completes the duplicate has been handled.
+	if err := h.queue.PublishVideoProcessing(context.Background(), h.queue.Published()[0]); err != nil {
+		t.Fatalf("republish: %v", err)
+	}
+	next := h.uploadOK(token, "other.mp4", []byte("y"))
+	h.waitForStatus(token, next, "COMPLETED")
+
+	after := h.waitForStatus(token, id, "COMPLETED")
+	if after.ProcessedFilePath != completed.ProcessedFilePath {
+		t.Errorf("processed path changed from %q to %q", completed.ProcessedFilePath, after.ProcessedFilePath)
+	}
+	eventsAfter, _ := h.events.FindByVideoID(id)
+	if len(eventsAfter) != len(eventsBefore) {
+		t.Errorf("duplicate delivery added %d events", len(eventsAfter)-len(eventsBefore))
+	}
+}
+
+func TestIdempotentUploadReplaysResponse(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	key := map[string]string{"Idempotency-Key": "retry-1"}
+
+	first := h.uploadWithHeaders(token, "clip.mp4", []byte("same bytes"), key)
+	second := h.uploadWithHeaders(token, "clip.mp4", []byte("same bytes"), key)
+	if first.StatusCode != http.StatusOK || second.StatusCode != http.StatusOK {
+		t.Fatalf("statuses = %d, %d", first.StatusCode, second.StatusCode)
+	}
+	var a, b struct {
+		VideoStatusID int `json:"video_status_id"`
+	}
+	decodeJSON(t, first, &a)
+	decodeJSON(t, second, &b)
+	if a.VideoStatusID != b.VideoStatusID {
+		t.Errorf("retry created video %d, want replay of %d", b.VideoStatusID, a.VideoStatusID)
+	}
+	if second.Header.Get("Idempotent-Replayed") != "true" {
+		t.Error("replayed response missing Idempotent-Replayed header")
+	}
+	if n := len(h.queue.Published()); n != 1 {
+		t.Errorf("published %d jobs, want 1", n)
+	}
+
+	mismatch := h.uploadWithHeaders(token, "clip.mp4", []byte("different bytes"), key)
+	if mismatch.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("reused key with different payload = %d, want 422", mismatch.StatusCode)
+	}
+
+	// Keys are scoped per user.
+	other := h.uploadWithHeaders(h.token(2), "clip.mp4", []byte("same bytes"), key)
+	if other.StatusCode != http.StatusOK || other.Header.Get("Idempotent-Replayed") != "" {
+		t.Errorf("other user's upload = %d, replayed = %q", other.StatusCode, other.Header.Get("Idempotent-Replayed"))
+	}
+}
+
+func TestIdempotentReplayKeepsDeduplicatedResponse(t *testing.T) {
+	h := newHarness(t)
+	h.uploadUC.DedupScope = usecase.DedupScopeUser
+	token := h.token(1)
+	content := []byte("identical clip")
+	h.waitForStatus(token, h.uploadOK(token, "a.mp4", content), "COMPLETED")
+
+	key := map[string]string{"Idempotency-Key": "dedup-1"}
+	type response struct {
+		Message       string `json:"message"`
+		VideoStatusID int    `json:"video_status_id"`
+		Deduplicated  bool   `json:"deduplicated"`
+	}
+	var first, replay response
+	decodeJSON(t, h.uploadWithHeaders(token, "b.mp4", content, key), &first)
+	if !first.Deduplicated {
+		t.Fatalf("first upload = %+v, want deduplicated", first)
+	}
+	resp := h.uploadWithHeaders(token, "b.mp4", content, key)
+	if resp.Header.Get("Idempotent-Replayed") != "true" {
+		t.Error("replayed response missing Idempotent-Replayed header")
+	}
+	decodeJSON(t, resp, &replay)
+	if replay != first {
+		t.Errorf("replay = %+v, want %+v", replay, first)
+	}
+}
+
+func TestCrashedIdempotentUploadIsTakenOver(t *testing.T) {
+	h := newHarness(t)
+	h.uploadUC.IdempotencyClaimTimeout = 200 * time.Millisecond
+	token := h.token(1)
+	key := map[string]string{"Idempotency-Key": "crashed-1"}
+	content := []byte("same bytes")
+
+	// A request that claimed the key and died before recording its video.
+	sum := sha256.Sum256(content)
+	requestHash := sha256.Sum256([]byte("clip.mp4\n" + hex.EncodeToString(sum[:])))
+	h.uploadUC.IdempotencyRepo.Reserve(&domain.IdempotencyRecord{
+		UserID:      1,
+		Key:         "crashed-1",
+		RequestHash: hex.EncodeToString(requestHash[:]),
+		Filename:    "clip.mp4",
+	}, time.Hour, time.Hour)
+
+	if resp := h.uploadWithHeaders(token, "clip.mp4", content, key); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("retry while the claim is fresh = %d, want 409", resp.StatusCode)
+	}
+	time.Sleep(250 * time.Millisecond)
+	resp := h.uploadWithHeaders(token, "clip.mp4", content, key)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Idempotent-Replayed") != "" {
+		t.Fatalf("retry after the claim timeout = %d, replayed = %q", resp.StatusCode, resp.Header.Get("Idempotent-Replayed"))
+	}
+	var first struct {
+		VideoStatusID int `json:"video_status_id"`
+	}
+	decodeJSON(t, resp, &first)
+	h.waitForStatus(token, first.VideoStatusID, "COMPLETED")
+
+	// The key now belongs to the request that took it over.
+	resp = h.uploadWithHeaders(token, "clip.mp4", content, key)
+	var replay struct {
+		VideoStatusID int `json:"video_status_id"`
+	}
+	decodeJSON(t, resp, &replay)
+	if resp.Header.Get("Idempotent-Replayed") != "true" || replay.VideoStatusID != first.VideoStatusID {
+		t.Errorf("later retry = video %d replayed %q, want a replay of %d", replay.VideoStatusID, resp.Header.Get("Idempotent-Replayed"), first.VideoStatusID)
+	}
+}
+
+func TestIdenticalUploadReusesArtifact(t *testing.T) {
+	h := newHarness(t)
+	h.uploadUC.DedupScope = usecase.DedupScopeUser
+	token := h.token(1)
+	content := []byte("identical clip")
+	sum := sha256.Sum256(content)
+	wantHash := hex.EncodeToString(sum[:])
+
+	first := h.uploadOK(token, "a.mp4", content)
+	original := h.waitForStatus(token, first, "COMPLETED")
+	if original.ContentHash != wantHash {
+		t.Errorf("content_hash = %q, want %q", original.ContentHash, wantHash)
+	}
+
+	resp := h.upload(token, "b.mp4", content)
+	var out struct {
+		VideoStatusID int    `json:"video_status_id"`
+		ContentHash   string `json:"content_hash"`
+		Deduplicated  bool   `json:"deduplicated"`
+	}
+	decodeJSON(t, resp, &out)
+	if !out.Deduplicated || out.ContentHash != wantHash {
+		t.Fatalf("second upload = %+v, want deduplicated with hash %s", out, wantHash)
+	}
+	reused := h.waitForStatus(token, out.VideoStatusID, "COMPLETED")
+	if reused.ReusedFromVideoID != first || reused.ProcessedFilePath != original.ProcessedFilePath {
+		t.Errorf("reused video = %+v", reused)
+	}
+	if n := len(h.queue.Published()); n != 1 {
+		t.Errorf("published %d jobs, want 1", n)
+	}
+	if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/download", out.VideoStatusID), token, nil, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("download of reused video = %d", resp.StatusCode)
+	}
+
+	// User scope: another user's identical upload is processed on its own.
+	otherToken := h.token(2)
+	other := h.uploadOK(otherToken, "c.mp4", content)
+	if got := h.waitForStatus(otherToken, other, "COMPLETED"); got.ReusedFromVideoID != 0 {
+		t.Errorf("other user's upload reused video %d", got.ReusedFromVideoID)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+
+	id := h.uploadOK(token, "clip.mp4", []byte("0123456789"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	// The worker records the final status just after writing it, so poll.
+	var body string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		resp := h.do(http.MethodGet, "/metrics", "", nil, "")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("metrics status = %d", resp.StatusCode)
+		}
+		body = string(readAll(t, resp))
+		if strings.Contains(body, "video_processor_jobs_finished_total") {
+			break
+		}
+	}
+	for _, want := range []string{
+		`video_processor_http_requests_total{method="POST",route="/upload",status="200"} 1`,
+		`video_processor_upload_bytes_total 10`,
+		`video_processor_jobs_finished_total{status="COMPLETED"} 1`,
+		`video_processor_jobs_in_flight 0`,
+		`video_processor_frames_extracted_sum 3`,
+		`video_processor_ffmpeg_duration_seconds_count 1`,
+		`video_processor_zip_duration_seconds_count 1`,
+		`video_processor_queue_consume_lag_seconds_count 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+}
+
+func TestTraceFollowsUploadThroughWorker(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+
+	id := h.uploadOK(token, "traced.mp4", []byte("x"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	// Find the trace started by the upload request.
+	var traceID string
+	for _, s := range spans.Ended() {
+		if s.Name() == "POST /upload" {
+			traceID = s.SpanContext().TraceID().String()
+		}
+	}
+	if traceID == "" {
+		t.Fatal("no server span for POST /upload")
+	}
+
+	want := map[string]bool{
+		"db.insert video":                false,
+		"queue.publish video_processing": false,
+		"ffmpeg.extract_frames":          false,
+		"zip.frames":                     false,
+		"db.update video_status":         false,
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		for _, s := range spans.Ended() {
+			if _, ok := want[s.Name()]; ok && s.SpanContext().TraceID().String() == traceID {
+				want[s.Name()] = true
+			}
+		}
+		missing := 0
+		for _, seen := range want {
+			if !seen {
+				missing++
+			}
+		}
+		if missing == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("spans in upload trace: %v", want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// syncBuffer collects log output written concurrently by the API and worker.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogsCarryCorrelationIDs(t *testing.T) {
+	var out syncBuffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&out, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	h := newHarness(t)
+	token := h.token(1)
+
+	resp := h.uploadWithHeaders(token, "secret-name.mp4", []byte("video"), map[string]string{
+		infrastructure.RequestIDHeader: "req-abc",
+	})
+	if got := resp.Header.Get(infrastructure.RequestIDHeader); got != "req-abc" {
+		t.Errorf("%s = %q, want the caller's ID echoed", infrastructure.RequestIDHeader, got)
+	}
+	var body struct {
+		VideoStatusID int `json:"video_status_id"`
+	}
+	decodeJSON(t, resp, &body)
+	h.waitForStatus(token, body.VideoStatusID, "COMPLETED")
+
+	if resp := h.do(http.MethodGet, "/health", "", nil, ""); resp.Header.Get(infrastructure.RequestIDHeader) == "" {
+		t.Error("no request ID generated when the caller sends none")
+	}
+
+	var sawRequest, sawWorker bool
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %q", line)
+		}
+		if strings.Contains(line, "secret-name") || strings.Contains(line, "uploads/") {
+			t.Errorf("log line leaks a filename or path: %s", line)
+		}
+		if entry["msg"] == "http request" && entry["route"] == "/upload" && entry["request_id"] == "req-abc" {
+			sawRequest = true
+		}
+		if entry["msg"] == "video processed" {
+			sawWorker = true
+			if entry["video_id"] != float64(body.VideoStatusID) || entry["worker_id"] != "test-worker" {
+				t.Errorf("worker line missing job IDs: %s", line)
+			}
+		}
+	}
+	if !sawRequest || !sawWorker {
+		t.Errorf("missing access log (%v) or worker log (%v) in:\n%s", sawRequest, sawWorker, out.String())
+	}
+}
+
+func TestHealthProbes(t *testing.T) {
+	h := newHarness(t)
+
+	report := func() (int, infrastructure.HealthReport) {
+		resp := h.do(http.MethodGet, "/health", "", nil, "")
+		var r infrastructure.HealthReport
+		decodeJSON(t, resp, &r)
+		return resp.StatusCode, r
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		code, r := report()
+		if code == http.StatusOK && r.Status == infrastructure.HealthStatusUp {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("/health = %d %+v, want 200 UP", code, r)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if resp := h.do(http.MethodGet, "/readyz", "", nil, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("/readyz = %d, want 200", resp.StatusCode)
+	}
+
+	h.database.SetErr(errors.New("connection refused"))
+	resp := h.do(http.MethodGet, "/readyz", "", nil, "")
+	var ready struct {
+		Status  string   `json:"status"`
+		Failing []string `json:"failing"`
+	}
+	decodeJSON(t, resp, &ready)
+	if resp.StatusCode != http.StatusServiceUnavailable || len(ready.Failing) != 1 || ready.Failing[0] != "database" {
+		t.Errorf("/readyz with database down = %d %+v, want 503 naming database", resp.StatusCode, ready)
+	}
+	if resp := h.do(http.MethodGet, "/livez", "", nil, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("/livez with database down = %d, want 200", resp.StatusCode)
+	}
+	code, r := report()
+	if code != http.StatusServiceUnavailable || r.Checks["database"].Error != "connection refused" {
+		t.Errorf("/health with database down = %d %+v", code, r)
+	}
+	h.database.SetErr(nil)
+
+	// A detached consumer degrades the instance without taking it out of
+	// rotation.
+	h.queue.Close()
+	deadline = time.Now().Add(2 * time.Second)
+	for {
+		code, r = report()
+		if r.Status == infrastructure.HealthStatusDegraded {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("/health after consumer stopped = %d %+v, want DEGRADED", code, r)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if code != http.StatusOK {
+		t.Errorf("degraded /health = %d, want 200", code)
+	}
+	if resp := h.do(http.MethodGet, "/readyz", "", nil, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("/readyz with consumer stopped = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestHealthReportHidesPaths(t *testing.T) {
+	h := newHarness(t)
+	blocker := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	h.health.Checks = append(h.health.Checks, infrastructure.WritableDirHealthCheck("output_dir", filepath.Join(blocker, "outputs")))
+
+	resp := h.do(http.MethodGet, "/health", "", nil, "")
+	var r infrastructure.HealthReport
+	decodeJSON(t, resp, &r)
+	result := r.Checks["output_dir"]
+	if result.Status != infrastructure.HealthStatusDown || result.Error == "" {
+		t.Fatalf("output_dir check = %+v, want DOWN with a reason", result)
+	}
+	if strings.Contains(result.Error, blocker) || strings.Contains(result.Error, "not-a-dir") {
+		t.Errorf("health report leaks the path: %q", result.Error)
+	}
+}
+
+func TestHealthReportIsCached(t *testing.T) {
+	h := newHarness(t)
+	h.health.TTL = time.Minute
+
+	first := h.health.Report(context.Background())
+	h.database.SetErr(errors.New("down"))
+	if again := h.health.Report(context.Background()); !again.CheckedAt.Equal(first.CheckedAt) || again.Checks["database"].Status != infrastructure.HealthStatusUp {
+		t.Errorf("report refreshed within TTL: %+v", again)
+	}
+
+	h.health.TTL = 0
+	if fresh := h.health.Report(context.Background()); fresh.Checks["database"].Status != infrastructure.HealthStatusDown {
+		t.Errorf("report not refreshed after TTL: %+v", fresh)
+	}
+}
@@ -0,0 +1,219 @@
+// e2e/archive_test.go
+package e2e
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// unzip returns the files in a ZIP by name, failing on frames stored with
+// methods other than want.
+func unzip(t *testing.T, data []byte, want uint16) map[string][]byte {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range reader.File {
+		if f.Name != domain.ManifestName && f.Method != want {
+			t.Errorf("%s uses method %d, want %d", f.Name, f.Method, want)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+	return files
+}
+
+func untar(t *testing.T, r io.Reader) map[string][]byte {
+	t.Helper()
+	reader := tar.NewReader(r)
+	files := make(map[string][]byte)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatalf("read tar: %v", err)
+		}
+		files[header.Name], _ = io.ReadAll(reader)
+	}
+}
+
+// storedFrames returns the frames kept in a job's directory by name.
+func (h *harness) storedFrames(dir string) map[string][]byte {
+	frames := make(map[string][]byte)
+	for _, p := range h.storage.Paths() {
+		if path.Dir(p) == dir && strings.HasSuffix(p, ".png") {
+			frames[path.Base(p)], _ = h.storage.ReadFile(p)
+		}
+	}
+	return frames
+}
+
+func sameFiles(got, want map[string][]byte) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for name, data := range want {
+		if !bytes.Equal(got[name], data) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFramesLayoutStreamsArchives(t *testing.T) {
+	h := newHarness(t)
+	h.worker.ArtifactLayout = domain.ArtifactLayoutFrames
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	frames := h.storedFrames(status.ProcessedFilePath)
+	if len(frames) != 3 {
+		t.Fatalf("stored frames = %d, want the 3 extracted frames kept", len(frames))
+	}
+	manifest, ok := h.storage.ReadFile(path.Join(status.ProcessedFilePath, domain.ManifestName))
+	if !ok {
+		t.Fatal("manifest not kept next to the frames")
+	}
+	frames[domain.ManifestName] = manifest
+	for _, p := range h.storage.Paths() {
+		if strings.HasSuffix(p, ".zip") {
+			t.Errorf("zip written to storage: %s", p)
+		}
+	}
+
+	resp := h.download(token, id, nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/zip" {
+		t.Fatalf("download = %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if resp.Header.Get("ETag") != "" || resp.Header.Get("Accept-Ranges") == "bytes" {
+		t.Errorf("streamed archive advertised validators or ranges: %v", resp.Header)
+	}
+	if !strings.Contains(resp.Header.Get("Content-Disposition"), `_processed.zip"`) {
+		t.Errorf("Content-Disposition = %q", resp.Header.Get("Content-Disposition"))
+	}
+	// PNGs are already compressed, so they are stored as is.
+	if files := unzip(t, readAll(t, resp), zip.Store); !sameFiles(files, frames) {
+		t.Errorf("zip holds %d files, want the stored frames", len(files))
+	}
+
+	resp = h.do(http.MethodGet, fmt.Sprintf("/videos/%d/download?format=tar", id), token, nil, "")
+	if resp.Header.Get("Content-Type") != "application/x-tar" || !strings.Contains(resp.Header.Get("Content-Disposition"), `_processed.tar"`) {
+		t.Errorf("tar headers = %v", resp.Header)
+	}
+	if files := untar(t, resp.Body); !sameFiles(files, frames) {
+		t.Errorf("tar holds %d files, want the stored frames", len(files))
+	}
+
+	resp = h.do(http.MethodGet, fmt.Sprintf("/videos/%d/download?format=tar.gz", id), token, nil, "")
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("read gzip: %v", err)
+	}
+	if files := untar(t, gz); !sameFiles(files, frames) {
+		t.Errorf("tar.gz holds %d files, want the stored frames", len(files))
+	}
+}
+
+func TestStreamedArchivesAreAlwaysSentWhole(t *testing.T) {
+	h := newHarness(t)
+	h.worker.ArtifactLayout = domain.ArtifactLayoutFrames
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+	whole := readAll(t, h.download(token, id, nil))
+
+	for name, headers := range map[string]map[string]string{
+		"range":         {"Range": "bytes=10-"},
+		"if-range":      {"Range": "bytes=10-", "If-Range": `"anything"`},
+		"if-none-match": {"If-None-Match": "*"},
+	} {
+		resp := h.download(token, id, headers)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Accept-Ranges") != "none" {
+			t.Errorf("%s: download = %d Accept-Ranges %q, want 200 none", name, resp.StatusCode, resp.Header.Get("Accept-Ranges"))
+		}
+		for _, header := range []string{"ETag", "Content-Range", "Content-Digest", "Repr-Digest", "Digest"} {
+			if v := resp.Header.Get(header); v != "" {
+				t.Errorf("%s: %s = %q on a streamed archive", name, header, v)
+			}
+		}
+		if body := readAll(t, resp); !bytes.Equal(body, whole) {
+			t.Errorf("%s: body is %d bytes, want the whole %d byte archive", name, len(body), len(whole))
+		}
+	}
+}
+
+func TestCompletionNotificationFollowsTheLayout(t *testing.T) {
+	for layout, want := range map[domain.ArtifactLayout]string{
+		domain.ArtifactLayoutZip:    "O arquivo ZIP com os 3 frames está disponível para download.",
+		domain.ArtifactLayoutFrames: "Os 3 frames estão disponíveis para download, avulsos ou em um arquivo ZIP ou TAR.",
+	} {
+		t.Run(string(layout), func(t *testing.T) {
+			h := newHarness(t)
+			h.worker.ArtifactLayout = layout
+			token := h.token(1)
+			h.uploadOK(token, "clip.mp4", []byte("video"))
+			if n := h.waitForNotification("COMPLETED"); !strings.HasSuffix(n.Message, want) {
+				t.Errorf("notification = %q, want it to end with %q", n.Message, want)
+			}
+		})
+	}
+}
+
+func TestStoredZipCanBeRepackedAsTar(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+	artifact, _ := h.storage.ReadFile(status.ProcessedFilePath)
+	want := unzip(t, artifact, zip.Store)
+
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/download?format=tar", id), token, nil, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("tar download = %d", resp.StatusCode)
+	}
+	if files := untar(t, resp.Body); len(want) != 4 || !sameFiles(files, want) {
+		t.Errorf("tar holds %d files, want the %d in the stored zip", len(files), len(want))
+	}
+
+	if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/download?format=rar", id), token, nil, ""); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown format = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestDeletingFramesLayoutRemovesFrames(t *testing.T) {
+	h := newHarness(t)
+	h.worker.ArtifactLayout = domain.ArtifactLayoutFrames
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	resp := h.do(http.MethodDelete, fmt.Sprintf("/admin/videos/%d", id), h.tokenWithRole(9, domain.RoleAdmin), nil, "")
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete = %d", resp.StatusCode)
+	}
+	if frames := h.storedFrames(status.ProcessedFilePath); len(frames) != 0 {
+		t.Errorf("%d frames left after delete", len(frames))
+	}
+	if _, ok := h.storage.ReadFile(path.Join(status.ProcessedFilePath, domain.ManifestName)); ok {
+		t.Error("manifest left after delete")
+	}
+}
@@ -0,0 +1,95 @@
+// e2e/audio_test.go
+package e2e
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"testing"
+
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/usecase"
+)
+
+func TestAudioTrackWaveformAndLoudness(t *testing.T) {
+	h := newHarness(t)
+	h.uploadUC.DedupScope = usecase.DedupScopeUser
+	h.worker.Audio = &domain.AudioSettings{Format: domain.AudioFormatOpus, WaveformWidth: 200, WaveformHeight: 50}
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	want := domain.AudioAnalysis{HasAudio: true, Loudness: &domain.Loudness{IntegratedLUFS: -23, TruePeakDBTP: -1, RangeLU: 5}}
+	if status.Audio == nil || status.Audio.HasAudio != want.HasAudio || status.Audio.Loudness == nil || *status.Audio.Loudness != *want.Loudness {
+		t.Fatalf("audio = %+v, want %+v", status.Audio, want)
+	}
+
+	outputs := h.listOutputs(token, id)
+	if len(outputs) != 2 || outputs[0].Name != "audio.opus" || outputs[0].Kind != "audio" || outputs[0].ContentType != "audio/ogg" ||
+		outputs[1].Name != "waveform.png" || outputs[1].Kind != "waveform" {
+		t.Fatalf("outputs = %+v, want audio.opus and waveform.png", outputs)
+	}
+	if track := h.output(token, id, "audio.opus"); !bytes.HasPrefix(track, []byte("OggS")) || sha256Hex(track) != outputs[0].SHA256 || int64(len(track)) != outputs[0].SizeBytes {
+		t.Errorf("audio.opus does not match its record")
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(h.output(token, id, "waveform.png")))
+	if err != nil || config.Width != 200 || config.Height != 50 {
+		t.Errorf("waveform = %dx%d %v, want 200x50", config.Width, config.Height, err)
+	}
+
+	// An identical upload reuses the artifact, its outputs and the analysis.
+	again := h.uploadOK(token, "copy.mp4", []byte("video"))
+	reused := h.waitForStatus(token, again, "COMPLETED")
+	if reused.ReusedFromVideoID != id || reused.Audio == nil || reused.Audio.Loudness == nil || *reused.Audio.Loudness != *want.Loudness {
+		t.Errorf("reused audio = %+v", reused.Audio)
+	}
+	if outputs := h.listOutputs(token, again); len(outputs) != 2 {
+		t.Errorf("reused outputs = %+v", outputs)
+	}
+}
+
+func TestVideoWithoutAudio(t *testing.T) {
+	h := newHarness(t)
+	h.worker.Audio = &domain.AudioSettings{}
+	h.audio.NoAudio = true
+	token := h.token(1)
+	id := h.uploadOK(token, "silent.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	if status.Audio == nil || status.Audio.HasAudio || status.Audio.Loudness != nil {
+		t.Errorf("audio = %+v, want has_audio false without loudness", status.Audio)
+	}
+	if outputs := h.listOutputs(token, id); len(outputs) != 0 {
+		t.Errorf("outputs = %+v, want none", outputs)
+	}
+}
+
+func TestLoudnessFailureKeepsAudioOutputs(t *testing.T) {
+	h := newHarness(t)
+	h.worker.Audio = &domain.AudioSettings{Format: domain.AudioFormatWAV}
+	h.audio.Err = errors.New("meter crashed")
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	if status.Audio == nil || !status.Audio.HasAudio || status.Audio.Loudness != nil {
+		t.Errorf("audio = %+v, want has_audio without loudness", status.Audio)
+	}
+	outputs := h.listOutputs(token, id)
+	if len(outputs) != 2 || outputs[0].Name != "audio.wav" || outputs[0].ContentType != "audio/wav" {
+		t.Errorf("outputs = %+v, want audio.wav and the waveform", outputs)
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(h.output(token, id, "waveform.png")))
+	if err != nil || config.Width != domain.DefaultAudioSettings.WaveformWidth || config.Height != domain.DefaultAudioSettings.WaveformHeight {
+		t.Errorf("waveform = %dx%d %v, want the default size", config.Width, config.Height, err)
+	}
+}
+
+func TestVideosProcessedWithoutAudioOutputsHaveNoAnalysis(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	if status := h.waitForStatus(token, id, "COMPLETED"); status.Audio != nil {
+		t.Errorf("audio = %+v, want none", status.Audio)
+	}
+}
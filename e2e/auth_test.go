@@ -0,0 +1,237 @@
+// e2e/auth_test.go
+package e2e
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// jwksServer is a local JWKS endpoint whose key list tests can change, like
+// an identity provider rotating keys.
+type jwksServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	keys []map[string]string
+	// hold, when set, blocks each fetch until it is closed.
+	hold    chan struct{}
+	fetches atomic.Int64
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	s := &jwksServer{keys: []map[string]string{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		hold := s.hold
+		s.mu.Unlock()
+		if hold != nil {
+			<-hold
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func b64(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func (s *jwksServer) addRSA(kid string, key *rsa.PublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append(s.keys, map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+		"n": b64(key.N), "e": b64(big.NewInt(int64(key.E))),
+	})
+}
+
+func (s *jwksServer) addEC(kid string, key *ecdsa.PublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append(s.keys, map[string]string{
+		"kty": "EC", "kid": kid, "use": "sig", "alg": "ES256", "crv": "P-256",
+		"x": b64(key.X), "y": b64(key.Y),
+	})
+}
+
+func (s *jwksServer) remove(kid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, k := range s.keys {
+		if k["kid"] == kid {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+func TestHMACKeyRotation(t *testing.T) {
+	h := newHarness(t)
+
+	for _, kid := range []string{testKeyID, testRetiringKeyID} {
+		token := h.sign(jwt.SigningMethodHS256, kid, testHMACKeys[kid], h.claims(1))
+		if resp := h.do(http.MethodGet, "/videos/status", token, nil, ""); resp.StatusCode != http.StatusOK {
+			t.Errorf("token signed with active key %q = %d, want 200", kid, resp.StatusCode)
+		}
+	}
+
+	for name, token := range map[string]string{
+		"unknown kid":        h.sign(jwt.SigningMethodHS256, "retired", testHMACKeys[testKeyID], h.claims(1)),
+		"no kid":             h.sign(jwt.SigningMethodHS256, "", testHMACKeys[testKeyID], h.claims(1)),
+		"key of other kid":   h.sign(jwt.SigningMethodHS256, testKeyID, testHMACKeys[testRetiringKeyID], h.claims(1)),
+		"unsigned (none)":    h.sign(jwt.SigningMethodNone, testKeyID, jwt.UnsafeAllowNoneSignatureType, h.claims(1)),
+		"key from elsewhere": h.sign(jwt.SigningMethodHS256, testKeyID, []byte("some-other-secret-0123456789abcdef"), h.claims(1)),
+	} {
+		if resp := h.do(http.MethodGet, "/videos/status", token, nil, ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s = %d, want 401", name, resp.StatusCode)
+		}
+	}
+}
+
+func TestRegisteredClaimsAreRequired(t *testing.T) {
+	h := newHarness(t)
+
+	for name, mutate := range map[string]func(*jwt.RegisteredClaims){
+		"missing exp":   func(c *jwt.RegisteredClaims) { c.ExpiresAt = nil },
+		"expired":       func(c *jwt.RegisteredClaims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour)) },
+		"missing iss":   func(c *jwt.RegisteredClaims) { c.Issuer = "" },
+		"wrong iss":     func(c *jwt.RegisteredClaims) { c.Issuer = "https://evil.example" },
+		"missing aud":   func(c *jwt.RegisteredClaims) { c.Audience = nil },
+		"wrong aud":     func(c *jwt.RegisteredClaims) { c.Audience = jwt.ClaimStrings{"another-service"} },
+		"not yet valid": func(c *jwt.RegisteredClaims) { c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour)) },
+	} {
+		claims := h.claims(1)
+		mutate(&claims.RegisteredClaims)
+		token := h.sign(jwt.SigningMethodHS256, testKeyID, testHMACKeys[testKeyID], claims)
+		if resp := h.do(http.MethodGet, "/videos/status", token, nil, ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s = %d, want 401", name, resp.StatusCode)
+		}
+	}
+
+	claims := h.claims(0)
+	token := h.sign(jwt.SigningMethodHS256, testKeyID, testHMACKeys[testKeyID], claims)
+	if resp := h.do(http.MethodGet, "/videos/status", token, nil, ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("token without user_id = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestJWKSSignedTokens(t *testing.T) {
+	h := newHarness(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.jwks.addRSA("rsa-1", &rsaKey.PublicKey)
+
+	status := func(token string) int {
+		return h.do(http.MethodGet, "/videos/status", token, nil, "").StatusCode
+	}
+
+	if got := status(h.sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, h.claims(1))); got != http.StatusOK {
+		t.Errorf("RS256 token = %d, want 200", got)
+	}
+
+	// A key published after the first fetch is picked up on demand.
+	h.jwks.addEC("ec-1", &ecKey.PublicKey)
+	if got := status(h.sign(jwt.SigningMethodES256, "ec-1", ecKey, h.claims(1))); got != http.StatusOK {
+		t.Errorf("ES256 token with newly published key = %d, want 200", got)
+	}
+
+	if got := status(h.sign(jwt.SigningMethodES256, "rsa-1", ecKey, h.claims(1))); got != http.StatusUnauthorized {
+		t.Errorf("ES256 token naming an RSA kid = %d, want 401", got)
+	}
+	claims := h.claims(1)
+	claims.Audience = jwt.ClaimStrings{"another-service"}
+	if got := status(h.sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, claims)); got != http.StatusUnauthorized {
+		t.Errorf("RS256 token for another audience = %d, want 401", got)
+	}
+
+	// Fetching a newly rotated kid also drops keys removed from the set.
+	h.jwks.remove("rsa-1")
+	h.jwks.addRSA("rsa-2", &rsaKey.PublicKey)
+	if got := status(h.sign(jwt.SigningMethodRS256, "rsa-2", rsaKey, h.claims(1))); got != http.StatusOK {
+		t.Errorf("RS256 token with rotated kid = %d, want 200", got)
+	}
+	if got := status(h.sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, h.claims(1))); got != http.StatusUnauthorized {
+		t.Errorf("RS256 token with removed kid = %d, want 401", got)
+	}
+}
+
+func TestJWKSFetchDoesNotBlockCachedKeys(t *testing.T) {
+	h := newHarness(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.jwks.addRSA("rsa-1", &rsaKey.PublicKey)
+	known := h.sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, h.claims(1))
+	if resp := h.do(http.MethodGet, "/videos/status", known, nil, ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("RS256 token = %d, want 200", resp.StatusCode)
+	}
+
+	hold := make(chan struct{})
+	h.jwks.mu.Lock()
+	h.jwks.hold = hold
+	h.jwks.mu.Unlock()
+	rotated := h.sign(jwt.SigningMethodRS256, "rsa-2", rsaKey, h.claims(1))
+	statuses := make(chan int, 3)
+	for range 3 {
+		req, _ := http.NewRequest(http.MethodGet, h.server.URL+"/videos/status", nil)
+		req.Header.Set("Authorization", "Bearer "+rotated)
+		go func() {
+			resp, err := h.server.Client().Do(req)
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for h.jwks.fetches.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("the unknown kid did not trigger a fetch")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Cached keys keep working while the fetch is stuck.
+	if resp := h.do(http.MethodGet, "/videos/status", known, nil, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("cached key during a fetch = %d, want 200", resp.StatusCode)
+	}
+
+	h.jwks.addRSA("rsa-2", &rsaKey.PublicKey)
+	close(hold)
+	for range 3 {
+		if status := <-statuses; status != http.StatusOK {
+			t.Errorf("rotated kid = %d, want 200", status)
+		}
+	}
+	// The requests for the unknown kid shared one fetch.
+	if n := h.jwks.fetches.Load(); n != 2 {
+		t.Errorf("jwks fetches = %d, want 2", n)
+	}
+}
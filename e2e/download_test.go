@@ -0,0 +1,124 @@
+// e2e/download_test.go
+package e2e
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// download fetches a video's artifact with extra request headers.
+func (h *harness) download(token string, videoID int, headers map[string]string) *http.Response {
+	h.t.Helper()
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/videos/%d/download", h.server.URL, videoID), nil)
+	if err != nil {
+		h.t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return h.send(req)
+}
+
+func TestDownloadSendsChecksumHeaders(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "a.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+	artifact, _ := h.storage.ReadFile(status.ProcessedFilePath)
+	sum := sha256.Sum256(artifact)
+	checksum := hex.EncodeToString(sum[:])
+	digest := "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+
+	if status.ProcessedFileChecksum != checksum {
+		t.Fatalf("status checksum = %q, want %q", status.ProcessedFileChecksum, checksum)
+	}
+
+	resp := h.download(token, id, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("download = %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("ETag"); got != `"`+checksum+`"` {
+		t.Errorf("ETag = %q", got)
+	}
+	if resp.Header.Get("Accept-Ranges") != "bytes" || resp.Header.Get("Content-Digest") != digest || resp.Header.Get("Repr-Digest") != digest {
+		t.Errorf("headers = %v", resp.Header)
+	}
+	if resp.Header.Get("Digest") != "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]) {
+		t.Errorf("Digest = %q", resp.Header.Get("Digest"))
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp := h.download(token, id, map[string]string{"If-None-Match": etag}); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("If-None-Match with current ETag = %d, want 304", resp.StatusCode)
+	}
+	if resp := h.download(token, id, map[string]string{"If-None-Match": `"stale"`}); resp.StatusCode != http.StatusOK {
+		t.Errorf("If-None-Match with stale ETag = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestDownloadResumesWithRange(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "a.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+	artifact, _ := h.storage.ReadFile(status.ProcessedFilePath)
+	if len(artifact) < 10 {
+		t.Fatalf("artifact too small for range tests: %d bytes", len(artifact))
+	}
+	etag := `"` + status.ProcessedFileChecksum + `"`
+
+	resp := h.download(token, id, map[string]string{"Range": "bytes=2-5"})
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("range = %d, want 206", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Range"); got != fmt.Sprintf("bytes 2-5/%d", len(artifact)) {
+		t.Errorf("Content-Range = %q", got)
+	}
+	if resp.Header.Get("Content-Digest") != "" || resp.Header.Get("Repr-Digest") == "" {
+		t.Errorf("partial response digests = %v, want only Repr-Digest", resp.Header)
+	}
+	if body := readAll(t, resp); !bytes.Equal(body, artifact[2:6]) {
+		t.Errorf("range body = %q, want %q", body, artifact[2:6])
+	}
+
+	// Resuming with If-Range only gets the tail while the artifact is unchanged.
+	resp = h.download(token, id, map[string]string{"Range": "bytes=6-", "If-Range": etag})
+	if body := readAll(t, resp); resp.StatusCode != http.StatusPartialContent || !bytes.Equal(body, artifact[6:]) {
+		t.Errorf("If-Range with current ETag = %d (%d bytes), want 206 with the tail", resp.StatusCode, len(body))
+	}
+	resp = h.download(token, id, map[string]string{"Range": "bytes=6-", "If-Range": `"stale"`})
+	if body := readAll(t, resp); resp.StatusCode != http.StatusOK || !bytes.Equal(body, artifact) {
+		t.Errorf("If-Range with stale ETag = %d (%d bytes), want 200 with the whole artifact", resp.StatusCode, len(body))
+	}
+	if resp := h.download(token, id, map[string]string{"Range": fmt.Sprintf("bytes=%d-", len(artifact)+10)}); resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("unsatisfiable range = %d, want 416", resp.StatusCode)
+	}
+}
+
+func TestDownloadBackfillsMissingChecksum(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "a.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	// Artifacts stored before checksums were recorded have none.
+	video, _ := h.repo.FindByID(id)
+	h.repo.SetProcessedFileChecksum(id, video.Claim, "")
+	resp := h.download(token, id, nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") != `"`+status.ProcessedFileChecksum+`"` {
+		t.Fatalf("download = %d with ETag %q", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+	artifact, _ := h.storage.ReadFile(status.ProcessedFilePath)
+	if body := readAll(t, resp); !bytes.Equal(body, artifact) {
+		t.Error("download after computing the checksum was not the whole artifact")
+	}
+	if video, _ := h.repo.FindByID(id); video.ProcessedFileChecksum != status.ProcessedFileChecksum {
+		t.Errorf("stored checksum = %q, want it saved on first download", video.ProcessedFileChecksum)
+	}
+}
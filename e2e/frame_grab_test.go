@@ -0,0 +1,215 @@
+// e2e/frame_grab_test.go
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+func (h *harness) grabFrame(token string, videoID int, query string) *http.Response {
+	h.t.Helper()
+	return h.do(http.MethodGet, fmt.Sprintf("/videos/%d/frame?%s", videoID, query), token, nil, "")
+}
+
+func TestFrameIsGrabbedFromKeptSource(t *testing.T) {
+	h := newHarness(t)
+	h.worker.KeepSource = true
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	resp := h.grabFrame(token, id, "t=2.5&w=64&format=png")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" || resp.Header.Get("X-Frame-Cache") != "miss" {
+		t.Fatalf("grab = %d %v: %s", resp.StatusCode, resp.Header, readAll(t, resp))
+	}
+	if got := resp.Header.Get("X-Frame-Timestamp-Ms"); got != "2500" {
+		t.Errorf("timestamp = %q, want 2500", got)
+	}
+	first := readAll(t, resp)
+	config, format, err := image.DecodeConfig(bytes.NewReader(first))
+	if err != nil || format != "png" || config.Width != 64 {
+		t.Fatalf("image = %+v %q %v, want a 64px wide png", config, format, err)
+	}
+
+	resp = h.grabFrame(token, id, "t=2.5&w=64&format=png")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Frame-Cache") != "hit" || !bytes.Equal(readAll(t, resp), first) {
+		t.Errorf("second grab = %d cache %q, want the cached frame", resp.StatusCode, resp.Header.Get("X-Frame-Cache"))
+	}
+	if calls := h.grabber.Calls(); calls != 1 {
+		t.Errorf("grabber calls = %d, want 1", calls)
+	}
+
+	resp = h.grabFrame(token, id, "t=2.5")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/jpeg" || resp.Header.Get("X-Frame-Cache") != "miss" {
+		t.Errorf("default format grab = %d %v", resp.StatusCode, resp.Header)
+	}
+	if resp := h.grabFrame(token, id, "t=1&format=webp"); resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/webp" {
+		t.Errorf("webp grab = %d %v", resp.StatusCode, resp.Header)
+	}
+
+	for query, want := range map[string]int{
+		"t=30":           http.StatusNotFound,
+		"":               http.StatusBadRequest,
+		"t=-1":           http.StatusBadRequest,
+		"t=1&w=0":        http.StatusBadRequest,
+		"t=1&w=5000":     http.StatusBadRequest,
+		"t=1&format=gif": http.StatusBadRequest,
+	} {
+		if resp := h.grabFrame(token, id, query); resp.StatusCode != want {
+			t.Errorf("grab %q = %d, want %d", query, resp.StatusCode, want)
+		}
+	}
+	if resp := h.grabFrame(h.token(2), id, "t=1"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("grab by another user = %d, want 403", resp.StatusCode)
+	}
+
+	video, _ := h.repo.FindByID(id)
+	if _, ok := h.storage.ReadFile(video.SourcePath); !ok {
+		t.Fatalf("source %s was not kept", video.SourcePath)
+	}
+	admin := h.tokenWithRole(9, domain.RoleAdmin)
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/admin/videos/%d", id), admin, nil, ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	if _, ok := h.storage.ReadFile(video.SourcePath); ok {
+		t.Error("source was kept after the video was deleted")
+	}
+}
+
+func TestFrameGrabNeedsTheSource(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	resp := h.grabFrame(token, id, "t=1")
+	if resp.StatusCode != http.StatusConflict || !strings.Contains(string(readAll(t, resp)), "no longer available") {
+		t.Errorf("grab without source = %d, want 409", resp.StatusCode)
+	}
+	if calls := h.grabber.Calls(); calls != 0 {
+		t.Errorf("grabber calls = %d, want 0", calls)
+	}
+}
+
+func TestFrameGrabFallsBackToTheProxy(t *testing.T) {
+	h := newHarness(t)
+	h.worker.Proxy = &domain.DefaultProxySettings
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	video, _ := h.repo.FindByID(id)
+	if _, ok := h.storage.ReadFile(video.SourcePath); ok {
+		t.Fatal("source was kept")
+	}
+	resp := h.grabFrame(token, id, "t=1.5&format=png")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("grab = %d %v: %s", resp.StatusCode, resp.Header, readAll(t, resp))
+	}
+}
+
+func TestFrameGrabsAreLimitedPerUser(t *testing.T) {
+	h := newHarness(t)
+	h.worker.KeepSource = true
+	h.grabber.Hold = make(chan struct{})
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	statuses := make(chan int, 2)
+	for _, at := range []string{"1", "2"} {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/videos/%d/frame?t=%s", h.server.URL, id, at), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		go func() {
+			resp, err := h.server.Client().Do(req)
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for h.grabber.Calls() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("grabber calls = %d, want 2 in flight", h.grabber.Calls())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	resp := h.grabFrame(token, id, "t=3")
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Errorf("third grab = %d %v, want 429 with Retry-After", resp.StatusCode, resp.Header)
+	}
+
+	close(h.grabber.Hold)
+	for range 2 {
+		if status := <-statuses; status != http.StatusOK {
+			t.Errorf("held grab = %d, want 200", status)
+		}
+	}
+	if resp := h.grabFrame(token, id, "t=3"); resp.StatusCode != http.StatusOK {
+		t.Errorf("grab after release = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestFrameGrabTimesOut(t *testing.T) {
+	h := newHarness(t)
+	h.worker.KeepSource = true
+	h.grabber.Hold = make(chan struct{})
+	h.grabFrames.Timeout = 50 * time.Millisecond
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	if resp := h.grabFrame(token, id, "t=1"); resp.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("grab = %d, want 504", resp.StatusCode)
+	}
+	// The slot of the timed out grab is free again.
+	close(h.grabber.Hold)
+	if resp := h.grabFrame(token, id, "t=1"); resp.StatusCode != http.StatusOK {
+		t.Errorf("grab after timeout = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestFrameGrabCacheIsBoundedBySize(t *testing.T) {
+	h := newHarness(t)
+	h.worker.KeepSource = true
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+	video, _ := h.repo.FindByID(id)
+	small, err := h.grabber.GrabFrame(context.Background(), video.SourcePath, time.Second, 64, domain.ImageFormatPNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Room for a single 64px frame; wider frames are never kept.
+	h.grabFrames.CacheBytes = int64(len(small)) * 3 / 2
+	h.grabFrames.MaxCachedFrameBytes = int64(len(small)) * 3 / 2
+
+	for i, step := range []struct{ query, cache string }{
+		{"t=1&w=64&format=png", "miss"},
+		{"t=1&w=64&format=png", "hit"},
+		{"t=2&w=64&format=png", "miss"},
+		{"t=1&w=64&format=png", "miss"},
+		{"t=1&w=2048&format=png", "miss"},
+		{"t=1&w=2048&format=png", "miss"},
+		{"t=1&w=64&format=png", "hit"},
+	} {
+		resp := h.grabFrame(token, id, step.query)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Frame-Cache") != step.cache {
+			t.Errorf("grab %d (%s) = %d cache %q, want %s", i, step.query, resp.StatusCode, resp.Header.Get("X-Frame-Cache"), step.cache)
+		}
+	}
+}
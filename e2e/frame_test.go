@@ -0,0 +1,193 @@
+// e2e/frame_test.go
+package e2e
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/infrastructure"
+)
+
+func (h *harness) listFrames(token string, videoID int) []infrastructure.FrameResponse {
+	h.t.Helper()
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/frames", videoID), token, nil, "")
+	if resp.StatusCode != http.StatusOK {
+		h.t.Fatalf("list frames = %d: %s", resp.StatusCode, readAll(h.t, resp))
+	}
+	var frames []infrastructure.FrameResponse
+	decodeJSON(h.t, resp, &frames)
+	return frames
+}
+
+func TestFramesAreListedAndServedFromZip(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+	artifact, _ := h.storage.ReadFile(status.ProcessedFilePath)
+	zipped := unzip(t, artifact, zip.Store)
+
+	frames := h.listFrames(token, id)
+	if len(frames) != 3 {
+		t.Fatalf("frames = %+v, want 3", frames)
+	}
+	for i, f := range frames {
+		if f.Index != i+1 || f.TimestampMs != int64(i*1000) || f.Width != 16 || f.Height != 16 || f.SizeBytes != int64(len(zipped[f.Name])) {
+			t.Errorf("frame %d = %+v", i, f)
+		}
+	}
+
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/frames/2", id), token, nil, "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" || resp.Header.Get("X-Frame-Index") != "2" {
+		t.Fatalf("frame 2 = %d %v", resp.StatusCode, resp.Header)
+	}
+	if body := readAll(t, resp); !bytes.Equal(body, zipped[frames[1].Name]) {
+		t.Error("frame 2 differs from the zipped frame")
+	}
+
+	for query, want := range map[string]string{"t=0": "1", "t=1.4": "2", "t=1.5": "2", "t=1.6": "3", "t=3600": "3"} {
+		resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/frames/at?%s", id, query), token, nil, "")
+		if got := resp.Header.Get("X-Frame-Index"); resp.StatusCode != http.StatusOK || got != want {
+			t.Errorf("frame at %s = %d index %q, want %s", query, resp.StatusCode, got, want)
+		}
+	}
+	for path, want := range map[string]int{
+		"frames/9":       http.StatusNotFound,
+		"frames/0":       http.StatusBadRequest,
+		"frames/x":       http.StatusBadRequest,
+		"frames/at":      http.StatusBadRequest,
+		"frames/at?t=-1": http.StatusBadRequest,
+	} {
+		if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/%s", id, path), token, nil, ""); resp.StatusCode != want {
+			t.Errorf("%s = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestFrameSubsetArchives(t *testing.T) {
+	for _, layout := range []domain.ArtifactLayout{domain.ArtifactLayoutZip, domain.ArtifactLayoutFrames} {
+		t.Run(string(layout), func(t *testing.T) {
+			h := newHarness(t)
+			h.worker.ArtifactLayout = layout
+			token := h.token(1)
+			id := h.uploadOK(token, "clip.mp4", []byte("video"))
+			h.waitForStatus(token, id, "COMPLETED")
+			frames := h.listFrames(token, id)
+
+			names := func(query string) []string {
+				t.Helper()
+				resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/frames/archive?%s", id, query), token, nil, "")
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("archive %s = %d: %s", query, resp.StatusCode, readAll(t, resp))
+				}
+				var got []string
+				for name := range unzip(t, readAll(t, resp), zip.Store) {
+					got = append(got, name)
+				}
+				return got
+			}
+			if got := names("from=2"); len(got) != 2 {
+				t.Errorf("from=2 packed %v, want frames 2 and 3", got)
+			}
+			if got := names("indices=3,1,3"); len(got) != 2 {
+				t.Errorf("indices=3,1,3 packed %v, want frames 1 and 3", got)
+			}
+			if got := names("from=1&to=1"); len(got) != 1 || got[0] != frames[0].Name {
+				t.Errorf("from=1&to=1 packed %v, want %s", got, frames[0].Name)
+			}
+
+			resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/frames/archive?from=2&to=3&format=tar", id), token, nil, "")
+			if files := untar(t, resp.Body); len(files) != 2 {
+				t.Errorf("tar subset holds %d files, want 2", len(files))
+			}
+
+			for query, want := range map[string]int{
+				"":                  http.StatusBadRequest,
+				"from=3&to=2":       http.StatusBadRequest,
+				"from=1&indices=2":  http.StatusBadRequest,
+				"indices=1,x":       http.StatusBadRequest,
+				"indices=1,7":       http.StatusNotFound,
+				"from=10":           http.StatusNotFound,
+				"from=1&format=rar": http.StatusBadRequest,
+			} {
+				if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/frames/archive?%s", id, query), token, nil, ""); resp.StatusCode != want {
+					t.Errorf("archive?%s = %d, want %d", query, resp.StatusCode, want)
+				}
+			}
+		})
+	}
+}
+
+func TestKeptFramesServeTheFrameAPI(t *testing.T) {
+	h := newHarness(t)
+	h.worker.KeepFrames = true
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	stored, _ := h.frames.FindByArtifact(status.ProcessedFilePath)
+	if len(stored) != 3 {
+		t.Fatalf("recorded frames = %d, want 3", len(stored))
+	}
+	for _, f := range stored {
+		if _, ok := h.storage.ReadFile(f.Path); !ok {
+			t.Errorf("frame %d is not kept at %q", f.Index, f.Path)
+		}
+	}
+	// Single frames do not come out of the ZIP.
+	zipped, _ := h.storage.ReadFile(status.ProcessedFilePath)
+	h.storage.DeleteFile(status.ProcessedFilePath)
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/frames/2", id), token, nil, "")
+	if body := readAll(t, resp); resp.StatusCode != http.StatusOK || !bytes.Equal(body, unzip(t, zipped, zip.Store)[stored[1].Name]) {
+		t.Errorf("frame 2 = %d, want the kept frame", resp.StatusCode)
+	}
+	h.storage.WriteFile(status.ProcessedFilePath, zipped)
+
+	admin := h.tokenWithRole(9, domain.RoleAdmin)
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/admin/videos/%d", id), admin, nil, ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	for _, f := range stored {
+		if _, ok := h.storage.ReadFile(f.Path); ok {
+			t.Errorf("frame %d was kept after the video was deleted", f.Index)
+		}
+	}
+}
+
+func TestFramesOfOlderArtifactsAreIndexedOnDemand(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	h.frames.DeleteByArtifact(status.ProcessedFilePath)
+	if frames := h.listFrames(token, id); len(frames) != 3 {
+		t.Fatalf("frames = %d, want the 3 in the zip", len(frames))
+	}
+	if stored, _ := h.frames.FindByArtifact(status.ProcessedFilePath); len(stored) != 3 {
+		t.Errorf("recorded frames = %d, want the index saved", len(stored))
+	}
+}
+
+func TestFrameAccessFollowsVideoAccess(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	for _, path := range []string{"frames", "frames/1", "frames/at?t=0", "frames/archive?from=1"} {
+		if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/%s", id, path), h.token(2), nil, ""); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s by another user = %d, want 403", path, resp.StatusCode)
+		}
+	}
+
+	pending := &domain.Video{UserID: 1, OriginalFilename: "p.mp4", Status: domain.VideoStatusPending}
+	h.repo.Save(pending)
+	if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/frames", pending.ID), token, nil, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("frames of a pending video = %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,434 @@
+// e2e/harness_test.go
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/infrastructure"
+	"github.com/vitovidale/video-processor-service/infrastructure/memory"
+	"github.com/vitovidale/video-processor-service/logging"
+	"github.com/vitovidale/video-processor-service/usecase"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const (
+	testIssuer   = "https://auth.example.test"
+	testAudience = "video-processor-service"
+	// testKeyID is the HS256 key the harness signs with; testRetiringKeyID
+	// is still accepted, as during a rotation.
+	testKeyID         = "2025-01"
+	testRetiringKeyID = "2024-07"
+)
+
+var testHMACKeys = map[string][]byte{
+	testKeyID:         []byte("e2e-current-secret-0123456789abcdef"),
+	testRetiringKeyID: []byte("e2e-previous-secret-0123456789abcde"),
+}
+
+// spans records every span ended during the test run.
+var spans = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	slog.SetDefault(logging.New(io.Discard, slog.LevelInfo))
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	os.Exit(m.Run())
+}
+
+// harness wires the real use cases and HTTP router to in-memory adapters and
+// runs the worker in the background.
+type harness struct {
+	t             *testing.T
+	server        *httptest.Server
+	repo          *memory.VideoRepository
+	events        *memory.VideoEventRepository
+	queue         *memory.MessageQueue
+	storage       *memory.FileStorage
+	processor     *memory.VideoProcessor
+	worker        *usecase.ProcessVideoUseCase
+	notifications *memory.NotificationService
+	uploadUC      *usecase.UploadVideoUseCase
+	metrics       *infrastructure.PrometheusMetrics
+	health        *infrastructure.HealthChecker
+	database      *fakeDependency
+	jwks          *jwksServer
+	apiKeys       *memory.APIKeyRepository
+	refreshTokens *memory.RefreshTokenRepository
+	quotas        *memory.QuotaRepository
+	workspaces    *memory.WorkspaceRepository
+	shareLinks    *memory.ShareLinkRepository
+	frames        *memory.FrameRepository
+	outputs       *memory.OutputRepository
+	grabber       *memory.FrameGrabber
+	previews      *memory.PreviewRenderer
+	audio         *memory.AudioProcessor
+	subtitles     *memory.SubtitleExtractor
+	transcoder    *memory.Transcoder
+	grabFrames    *usecase.GrabFrameUseCase
+}
+
+// fakeDependency stands in for an external service in health checks.
+type fakeDependency struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (d *fakeDependency) SetErr(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.err = err
+}
+
+func (d *fakeDependency) Check(context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+
+	h := &harness{
+		t:             t,
+		repo:          memory.NewVideoRepository(),
+		events:        memory.NewVideoEventRepository(),
+		queue:         memory.NewMessageQueue(16),
+		storage:       memory.NewFileStorage(),
+		notifications: memory.NewNotificationService(),
+		metrics:       infrastructure.NewPrometheusMetrics(),
+		database:      &fakeDependency{},
+		jwks:          newJWKSServer(t),
+		apiKeys:       memory.NewAPIKeyRepository(),
+		refreshTokens: memory.NewRefreshTokenRepository(),
+		quotas:        memory.NewQuotaRepository(),
+		workspaces:    memory.NewWorkspaceRepository(),
+		shareLinks:    memory.NewShareLinkRepository(),
+		frames:        memory.NewFrameRepository(),
+		outputs:       memory.NewOutputRepository(),
+	}
+	h.processor = memory.NewVideoProcessor(h.storage, 3)
+	h.grabber = memory.NewFrameGrabber(h.storage, 10*time.Second)
+	h.previews = memory.NewPreviewRenderer(h.storage)
+	h.audio = memory.NewAudioProcessor(h.storage)
+	h.subtitles = memory.NewSubtitleExtractor(h.storage)
+	h.transcoder = memory.NewTranscoder(h.storage)
+	h.grabFrames = &usecase.GrabFrameUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, FileStorage: h.storage, Grabber: h.grabber, Outputs: h.outputs}
+
+	h.worker = &usecase.ProcessVideoUseCase{
+		VideoRepo:       h.repo,
+		EventRepo:       h.events,
+		FileStorage:     h.storage,
+		Processor:       h.processor,
+		Notification:    h.notifications,
+		Metrics:         h.metrics,
+		WorkerID:        "test-worker",
+		Frames:          h.frames,
+		Outputs:         h.outputs,
+		PreviewRenderer: h.previews,
+		AudioProcessor:  h.audio,
+		Subtitles:       h.subtitles,
+		Transcoder:      h.transcoder,
+	}
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		h.queue.ConsumeVideoProcessing(h.worker.Execute)
+	}()
+
+	h.uploadUC = &usecase.UploadVideoUseCase{
+		VideoRepo:       h.repo,
+		EventRepo:       h.events,
+		IdempotencyRepo: memory.NewIdempotencyRepository(),
+		MessageQueue:    h.queue,
+		FileStorage:     h.storage,
+		Metrics:         h.metrics,
+		QuotaRepo:       h.quotas,
+	}
+
+	h.health = infrastructure.NewHealthChecker(0,
+		infrastructure.HealthCheck{Name: "database", Critical: true, Check: h.database.Check},
+		infrastructure.ConsumerHealthCheck(h.queue),
+		infrastructure.WritableDirHealthCheck("upload_dir", t.TempDir()),
+	)
+
+	keySet := infrastructure.NewJWKSKeySet(h.jwks.URL)
+	keySet.MinRefreshInterval = 0
+	verifier, err := infrastructure.NewJWTVerifier(testIssuer, testAudience, testHMACKeys, keySet)
+	if err != nil {
+		t.Fatalf("verifier: %v", err)
+	}
+
+	tokenSigner, err := infrastructure.NewJWTSigner(verifier, testKeyID, 0)
+	if err != nil {
+		t.Fatalf("token signer: %v", err)
+	}
+	signer := &usecase.ShareLinkSigner{Secret: []byte("e2e-share-link-secret-0123456789ab")}
+	requeueUC := &usecase.RequeueVideoUseCase{VideoRepo: h.repo, EventRepo: h.events, MessageQueue: h.queue, QuotaRepo: h.quotas}
+	router := infrastructure.NewRouter(infrastructure.RouterConfig{
+		VideoHandlers: infrastructure.NewVideoHandlers(
+			h.uploadUC,
+			&usecase.ListVideoStatusUseCase{VideoRepo: h.repo},
+			&usecase.DownloadVideoUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, FileStorage: h.storage},
+			&usecase.ListVideoEventsUseCase{VideoRepo: h.repo, EventRepo: h.events, Workspaces: h.workspaces},
+			&usecase.ReprocessVideoUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, Requeue: requeueUC},
+		),
+		APIKeyHandlers: infrastructure.NewAPIKeyHandlers(
+			&usecase.CreateAPIKeyUseCase{Repo: h.apiKeys},
+			&usecase.ListAPIKeysUseCase{Repo: h.apiKeys},
+			&usecase.RevokeAPIKeyUseCase{Repo: h.apiKeys},
+		),
+		AdminHandlers: infrastructure.NewAdminHandlers(
+			&usecase.ListAllVideosUseCase{VideoRepo: h.repo},
+			&usecase.ForceFailVideoUseCase{VideoRepo: h.repo, EventRepo: h.events},
+			requeueUC,
+			&usecase.DeleteVideoUseCase{VideoRepo: h.repo, FileStorage: h.storage, Frames: h.frames, Outputs: h.outputs},
+			&usecase.GetQueueStatsUseCase{Queue: h.queue},
+			&usecase.GetUserQuotaUseCase{QuotaRepo: h.quotas},
+			&usecase.SetUserQuotaUseCase{QuotaRepo: h.quotas},
+		),
+		WorkspaceHandlers: infrastructure.NewWorkspaceHandlers(
+			&usecase.CreateWorkspaceUseCase{Repo: h.workspaces},
+			&usecase.ListWorkspacesUseCase{Repo: h.workspaces},
+			&usecase.ListWorkspaceMembersUseCase{Repo: h.workspaces},
+			&usecase.SetWorkspaceMemberUseCase{Repo: h.workspaces},
+			&usecase.RemoveWorkspaceMemberUseCase{Repo: h.workspaces},
+		),
+		ShareHandlers: infrastructure.NewShareHandlers(
+			&usecase.CreateShareLinkUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, Repo: h.shareLinks, Signer: signer},
+			&usecase.ListShareLinksUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, Repo: h.shareLinks},
+			&usecase.ListShareAccessesUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, Repo: h.shareLinks},
+			&usecase.RevokeShareLinkUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, Repo: h.shareLinks},
+			&usecase.DownloadSharedVideoUseCase{Repo: h.shareLinks, VideoRepo: h.repo, FileStorage: h.storage, Signer: signer},
+			"",
+		),
+		FrameHandlers: infrastructure.NewFrameHandlers(
+			&usecase.ListFramesUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, FileStorage: h.storage, Frames: h.frames},
+			&usecase.GetFrameUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, FileStorage: h.storage, Frames: h.frames},
+			&usecase.DownloadFramesUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, FileStorage: h.storage, Frames: h.frames},
+			h.grabFrames,
+			&usecase.GetManifestUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, FileStorage: h.storage},
+		),
+		OutputHandlers: infrastructure.NewOutputHandlers(
+			&usecase.ListOutputsUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, Outputs: h.outputs},
+			&usecase.GetOutputUseCase{VideoRepo: h.repo, Workspaces: h.workspaces, FileStorage: h.storage, Outputs: h.outputs},
+		),
+		RefreshTokenHandlers: infrastructure.NewRefreshTokenHandlers(
+			&usecase.IssueRefreshTokenUseCase{Repo: h.refreshTokens},
+			&usecase.RefreshAccessTokenUseCase{Repo: h.refreshTokens, Signer: tokenSigner},
+			&usecase.RevokeRefreshTokenUseCase{Repo: h.refreshTokens},
+			&usecase.RevokeUserRefreshTokensUseCase{Repo: h.refreshTokens},
+		),
+		Health:              h.health,
+		AuthMiddleware:      infrastructure.AuthMiddleware(verifier, &usecase.AuthenticateAPIKeyUseCase{Repo: h.apiKeys}),
+		WorkspaceMiddleware: infrastructure.WorkspaceMiddleware(&usecase.ResolveWorkspaceUseCase{Repo: h.workspaces}),
+		Metrics:             h.metrics,
+	})
+	h.server = httptest.NewServer(router)
+
+	t.Cleanup(func() {
+		h.server.Close()
+		h.queue.Close()
+		<-consumerDone
+	})
+	return h
+}
+
+// claims returns valid claims for userID, which tests may tweak before
+// signing.
+func (h *harness) claims(userID int) infrastructure.Claims {
+	return infrastructure.Claims{
+		Username: fmt.Sprintf("user%d", userID),
+		UserID:   userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			Audience:  jwt.ClaimStrings{testAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+// sign signs claims with method and key, setting the kid header when given.
+func (h *harness) sign(method jwt.SigningMethod, kid string, key any, claims jwt.Claims) string {
+	h.t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		h.t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func (h *harness) token(userID int) string {
+	h.t.Helper()
+	return h.sign(jwt.SigningMethodHS256, testKeyID, testHMACKeys[testKeyID], h.claims(userID))
+}
+
+// tokenWithRole is token for a user holding role.
+func (h *harness) tokenWithRole(userID int, role domain.Role) string {
+	h.t.Helper()
+	claims := h.claims(userID)
+	claims.Role = string(role)
+	return h.sign(jwt.SigningMethodHS256, testKeyID, testHMACKeys[testKeyID], claims)
+}
+
+func (h *harness) do(method, path, token string, body io.Reader, contentType string) *http.Response {
+	h.t.Helper()
+	req, err := http.NewRequest(method, h.server.URL+path, body)
+	if err != nil {
+		h.t.Fatalf("new request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return h.send(req)
+}
+
+func (h *harness) send(req *http.Request) *http.Response {
+	h.t.Helper()
+	resp, err := h.server.Client().Do(req)
+	if err != nil {
+		h.t.Fatalf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	h.t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func (h *harness) upload(token, filename string, content []byte) *http.Response {
+	h.t.Helper()
+	return h.uploadWithHeaders(token, filename, content, nil)
+}
+
+func (h *harness) uploadWithHeaders(token, filename string, content []byte, headers map[string]string) *http.Response {
+	h.t.Helper()
+	return h.send(h.uploadRequest(token, filename, content, headers))
+}
+
+// uploadRequest builds an upload without sending it, for tests that send
+// from other goroutines.
+func (h *harness) uploadRequest(token, filename string, content []byte, headers map[string]string) *http.Request {
+	h.t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("video", filename)
+	if err != nil {
+		h.t.Fatalf("create form file: %v", err)
+	}
+	part.Write(content)
+	mw.Close()
+
+	req, err := http.NewRequest(http.MethodPost, h.server.URL+"/upload", &body)
+	if err != nil {
+		h.t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+// uploadOK uploads content and returns the new video_status_id.
+func (h *harness) uploadOK(token, filename string, content []byte) int {
+	h.t.Helper()
+	resp := h.upload(token, filename, content)
+	if resp.StatusCode != http.StatusOK {
+		h.t.Fatalf("upload status = %d, body = %s", resp.StatusCode, readAll(h.t, resp))
+	}
+	var out struct {
+		VideoStatusID int `json:"video_status_id"`
+	}
+	decodeJSON(h.t, resp, &out)
+	return out.VideoStatusID
+}
+
+func (h *harness) statuses(token string) []infrastructure.VideoStatusResponse {
+	h.t.Helper()
+	resp := h.do(http.MethodGet, "/videos/status", token, nil, "")
+	if resp.StatusCode != http.StatusOK {
+		h.t.Fatalf("status list = %d, body = %s", resp.StatusCode, readAll(h.t, resp))
+	}
+	var out []infrastructure.VideoStatusResponse
+	decodeJSON(h.t, resp, &out)
+	return out
+}
+
+// waitForStatus polls the status endpoint until videoID reaches want.
+func (h *harness) waitForStatus(token string, videoID int, want string) infrastructure.VideoStatusResponse {
+	h.t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	var last infrastructure.VideoStatusResponse
+	for time.Now().Before(deadline) {
+		for _, s := range h.statuses(token) {
+			if s.ID == videoID {
+				last = s
+			}
+		}
+		if last.Status == want {
+			return last
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	h.t.Fatalf("video %d status = %q, want %q", videoID, last.Status, want)
+	return last
+}
+
+// waitForNotification returns the first notification with status, which
+// is sent after the status itself is written.
+func (h *harness) waitForNotification(status string) memory.Notification {
+	h.t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, n := range h.notifications.Notifications() {
+			if n.Status == status {
+				return n
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	h.t.Fatalf("no %s notification in %+v", status, h.notifications.Notifications())
+	return memory.Notification{}
+}
+
+func readAll(t *testing.T, resp *http.Response) []byte {
+	t.Helper()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return data
+}
+
+func decodeJSON(t *testing.T, resp *http.Response, v any) {
+	t.Helper()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+// e2e/manifest_test.go
+package e2e
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+func (h *harness) manifest(token string, videoID int) *domain.Manifest {
+	h.t.Helper()
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/manifest", videoID), token, nil, "")
+	if resp.StatusCode != http.StatusOK {
+		h.t.Fatalf("manifest = %d: %s", resp.StatusCode, readAll(h.t, resp))
+	}
+	var manifest domain.Manifest
+	decodeJSON(h.t, resp, &manifest)
+	return &manifest
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkManifestFrames compares the frames listed in a manifest with the
+// frames of an artifact, by name.
+func checkManifestFrames(t *testing.T, manifest *domain.Manifest, frames map[string][]byte) {
+	t.Helper()
+	if manifest.FrameCount != len(frames) || len(manifest.Frames) != len(frames) {
+		t.Fatalf("manifest lists %d/%d frames, want %d", manifest.FrameCount, len(manifest.Frames), len(frames))
+	}
+	names := make([]string, 0, len(frames))
+	for name := range frames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, f := range manifest.Frames {
+		data := frames[names[i]]
+		if f.Index != i+1 || f.Name != names[i] || f.PTSSeconds != float64(i) || f.Width != 16 || f.Height != 16 ||
+			f.SizeBytes != int64(len(data)) || f.SHA256 != sha256Hex(data) {
+			t.Errorf("manifest frame %d = %+v", i, f)
+		}
+	}
+}
+
+func TestManifestIsPackedInZipAndServed(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	content := []byte("video")
+	id := h.uploadOK(token, "clip.mp4", content)
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	artifact, _ := h.storage.ReadFile(status.ProcessedFilePath)
+	files := unzip(t, artifact, zip.Store)
+	var packed domain.Manifest
+	if err := json.Unmarshal(files[domain.ManifestName], &packed); err != nil {
+		t.Fatalf("manifest.json in zip: %v", err)
+	}
+	delete(files, domain.ManifestName)
+
+	want := domain.ManifestSource{Filename: "clip.mp4", SizeBytes: int64(len(content)), SHA256: sha256Hex(content)}
+	if packed.Version != domain.ManifestVersion || packed.Source != want || packed.Extraction.FPS != 1 || packed.CreatedAt.IsZero() {
+		t.Errorf("manifest = %+v", packed)
+	}
+	checkManifestFrames(t, &packed, files)
+
+	if served := h.manifest(token, id); !reflect.DeepEqual(served, &packed) {
+		t.Errorf("served manifest = %+v, want the packed one %+v", served, packed)
+	}
+	for i, f := range h.listFrames(token, id) {
+		if f.SHA256 != packed.Frames[i].SHA256 {
+			t.Errorf("frame %d sha256 = %q, want %q", f.Index, f.SHA256, packed.Frames[i].SHA256)
+		}
+	}
+
+	if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/manifest", id), h.token(2), nil, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("manifest for another user = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestManifestIsKeptWithFrames(t *testing.T) {
+	h := newHarness(t)
+	h.worker.ArtifactLayout = domain.ArtifactLayoutFrames
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	manifest := h.manifest(token, id)
+	checkManifestFrames(t, manifest, h.storedFrames(status.ProcessedFilePath))
+
+	files := unzip(t, readAll(t, h.download(token, id, nil)), zip.Store)
+	var packed domain.Manifest
+	if err := json.Unmarshal(files[domain.ManifestName], &packed); err != nil {
+		t.Fatalf("manifest.json in streamed zip: %v", err)
+	}
+	if !reflect.DeepEqual(&packed, manifest) {
+		t.Errorf("streamed manifest = %+v, want %+v", packed, manifest)
+	}
+}
+
+func TestManifestIsBuiltForOlderArtifacts(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	content := []byte("video")
+	id := h.uploadOK(token, "clip.mp4", content)
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	// Rewrite the artifact as it was stored before manifests existed.
+	artifact, _ := h.storage.ReadFile(status.ProcessedFilePath)
+	frames := unzip(t, artifact, zip.Store)
+	delete(frames, domain.ManifestName)
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range frames {
+		w, _ := zw.Create(name)
+		w.Write(data)
+	}
+	zw.Close()
+	h.storage.WriteFile(status.ProcessedFilePath, buf.Bytes())
+
+	manifest := h.manifest(token, id)
+	want := domain.ManifestSource{Filename: "clip.mp4", SHA256: sha256Hex(content)}
+	if manifest.Source != want {
+		t.Errorf("source = %+v, want %+v", manifest.Source, want)
+	}
+	checkManifestFrames(t, manifest, frames)
+}
+
+func TestFrameTimestampsComeFromTheExtractor(t *testing.T) {
+	h := newHarness(t)
+	// A variable frame rate source: the frames picked do not sit on the
+	// 1 fps grid.
+	h.processor.Timestamps = []time.Duration{40 * time.Millisecond, 1200 * time.Millisecond, 2950 * time.Millisecond}
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	for i, f := range h.manifest(token, id).Frames {
+		if want := h.processor.Timestamps[i].Seconds(); f.PTSSeconds != want {
+			t.Errorf("manifest frame %d pts_seconds = %v, want %v", f.Index, f.PTSSeconds, want)
+		}
+	}
+	for i, f := range h.listFrames(token, id) {
+		if want := h.processor.Timestamps[i].Milliseconds(); f.TimestampMs != want {
+			t.Errorf("frame %d timestamp_ms = %d, want %d", f.Index, f.TimestampMs, want)
+		}
+	}
+	for query, want := range map[string]string{"t=1": "2", "t=2": "2", "t=2.2": "3"} {
+		resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/frames/at?%s", id, query), token, nil, "")
+		if got := resp.Header.Get("X-Frame-Index"); resp.StatusCode != http.StatusOK || got != want {
+			t.Errorf("frame at %s = %d index %q, want %s", query, resp.StatusCode, got, want)
+		}
+	}
+}
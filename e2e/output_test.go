@@ -0,0 +1,155 @@
+// e2e/output_test.go
+package e2e
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/infrastructure"
+)
+
+func (h *harness) listOutputs(token string, videoID int) []infrastructure.OutputResponse {
+	h.t.Helper()
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/outputs", videoID), token, nil, "")
+	if resp.StatusCode != http.StatusOK {
+		h.t.Fatalf("list outputs = %d: %s", resp.StatusCode, readAll(h.t, resp))
+	}
+	var outputs []infrastructure.OutputResponse
+	decodeJSON(h.t, resp, &outputs)
+	return outputs
+}
+
+func (h *harness) output(token string, videoID int, name string) []byte {
+	h.t.Helper()
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/outputs/%s", videoID, name), token, nil, "")
+	if resp.StatusCode != http.StatusOK {
+		h.t.Fatalf("output %s = %d: %s", name, resp.StatusCode, readAll(h.t, resp))
+	}
+	return readAll(h.t, resp)
+}
+
+func TestSpriteSheetsAndThumbnailTrack(t *testing.T) {
+	h := newHarness(t)
+	h.processor.FrameCount = 7
+	h.worker.Sprites = &domain.SpriteSettings{Columns: 3, Rows: 2, Width: 8}
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	outputs := h.listOutputs(token, id)
+	want := []struct{ name, kind, contentType string }{
+		{"sprites_001.jpg", "sprite_sheet", "image/jpeg"},
+		{"sprites_002.jpg", "sprite_sheet", "image/jpeg"},
+		{"thumbnails.vtt", "thumbnail_track", "text/vtt; charset=utf-8"},
+	}
+	if len(outputs) != len(want) {
+		t.Fatalf("outputs = %+v, want %d", outputs, len(want))
+	}
+	for i, w := range want {
+		o := outputs[i]
+		if o.Name != w.name || o.Kind != w.kind || o.ContentType != w.contentType {
+			t.Errorf("output %d = %+v, want %+v", i, o, w)
+		}
+		if data := h.output(token, id, o.Name); int64(len(data)) != o.SizeBytes || sha256Hex(data) != o.SHA256 {
+			t.Errorf("%s body does not match its size or sha256", o.Name)
+		}
+	}
+
+	// Six thumbnails fill the first 3x2 sheet; the seventh gets a sheet
+	// trimmed to its size.
+	for name, size := range map[string]image.Point{"sprites_001.jpg": {24, 16}, "sprites_002.jpg": {8, 8}} {
+		img, format, err := image.Decode(bytes.NewReader(h.output(token, id, name)))
+		if err != nil || format != "jpeg" || img.Bounds().Size() != size {
+			t.Fatalf("%s = %v %q %v, want a %v jpeg", name, img.Bounds(), format, err, size)
+		}
+		if name == "sprites_001.jpg" {
+			// The second frame is a flat gray of 32.
+			if r, _, _, _ := img.At(12, 4).RGBA(); r>>8 < 20 || r>>8 > 44 {
+				t.Errorf("thumbnail 2 gray = %d, want about 32", r>>8)
+			}
+		}
+	}
+
+	track := string(h.output(token, id, "thumbnails.vtt"))
+	for _, cue := range []string{
+		"WEBVTT\n\n00:00:00.000 --> 00:00:01.000\nsprites_001.jpg#xywh=0,0,8,8\n",
+		"\n00:00:04.000 --> 00:00:05.000\nsprites_001.jpg#xywh=8,8,8,8\n",
+		"\n00:00:06.000 --> 00:00:07.000\nsprites_002.jpg#xywh=0,0,8,8\n",
+	} {
+		if !strings.Contains(track, cue) {
+			t.Errorf("track lacks cue %q:\n%s", cue, track)
+		}
+	}
+	if n := strings.Count(track, " --> "); n != 7 {
+		t.Errorf("track has %d cues, want 7", n)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/videos/%d/outputs/sprites_001.jpg", h.server.URL, id), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Range", "bytes=0-9")
+	resp := h.send(req)
+	if resp.StatusCode != http.StatusPartialContent || !strings.HasPrefix(resp.Header.Get("Content-Disposition"), "inline") || resp.Header.Get("ETag") == "" {
+		t.Errorf("ranged output = %d %v", resp.StatusCode, resp.Header)
+	}
+	if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/outputs/nope.jpg", id), token, nil, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown output = %d, want 404", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/outputs", id), h.token(2), nil, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("outputs for another user = %d, want 403", resp.StatusCode)
+	}
+	// Outputs live apart from the frames, so frames and archives ignore them.
+	if frames := h.listFrames(token, id); len(frames) != 7 {
+		t.Errorf("frames = %d, want 7", len(frames))
+	}
+
+	admin := h.tokenWithRole(9, domain.RoleAdmin)
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/admin/videos/%d", id), admin, nil, ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete = %d", resp.StatusCode)
+	}
+	for _, p := range h.storage.Paths() {
+		if strings.Contains(p, "/outputs/") {
+			t.Errorf("output %s left after delete", p)
+		}
+	}
+}
+
+func TestSpriteIntervalSamplesFrames(t *testing.T) {
+	h := newHarness(t)
+	h.processor.FrameCount = 7
+	h.worker.ArtifactLayout = domain.ArtifactLayoutFrames
+	h.worker.Sprites = &domain.SpriteSettings{Columns: 5, Rows: 5, Width: 4, Height: 3, Interval: 2 * time.Second}
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	track := string(h.output(token, id, "thumbnails.vtt"))
+	want := "WEBVTT\n" +
+		"\n00:00:00.000 --> 00:00:02.000\nsprites_001.jpg#xywh=0,0,4,3\n" +
+		"\n00:00:02.000 --> 00:00:04.000\nsprites_001.jpg#xywh=4,0,4,3\n" +
+		"\n00:00:04.000 --> 00:00:06.000\nsprites_001.jpg#xywh=8,0,4,3\n" +
+		"\n00:00:06.000 --> 00:00:08.000\nsprites_001.jpg#xywh=12,0,4,3\n"
+	if track != want {
+		t.Errorf("track =\n%s\nwant\n%s", track, want)
+	}
+	img, _, err := image.Decode(bytes.NewReader(h.output(token, id, "sprites_001.jpg")))
+	if err != nil || img.Bounds().Size() != (image.Point{16, 3}) {
+		t.Errorf("sheet = %v %v, want 16x3", img, err)
+	}
+}
+
+func TestVideosWithoutOutputs(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	if outputs := h.listOutputs(token, id); len(outputs) != 0 {
+		t.Errorf("outputs = %+v, want none", outputs)
+	}
+}
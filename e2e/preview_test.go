@@ -0,0 +1,95 @@
+// e2e/preview_test.go
+package e2e
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+func TestGIFPreviewIsStoredAsOutput(t *testing.T) {
+	h := newHarness(t)
+	h.worker.Preview = &domain.PreviewSettings{Format: domain.PreviewFormatGIF, Segments: 3, SegmentLength: time.Second, Width: 64, FPS: 5}
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	outputs := h.listOutputs(token, id)
+	if len(outputs) != 1 || outputs[0].Name != "preview.gif" || outputs[0].Kind != "preview" || outputs[0].ContentType != "image/gif" {
+		t.Fatalf("outputs = %+v, want preview.gif", outputs)
+	}
+	anim, err := gif.DecodeAll(bytes.NewReader(h.output(token, id, "preview.gif")))
+	if err != nil {
+		t.Fatalf("decode preview: %v", err)
+	}
+	if len(anim.Image) != 15 || anim.Config.Width != 64 || anim.LoopCount != 0 {
+		t.Errorf("preview = %d frames %dpx loop %d, want 15 frames of 64px looping", len(anim.Image), anim.Config.Width, anim.LoopCount)
+	}
+	if calls := h.previews.Calls(); len(calls) != 1 || calls[0].MaxBytes != 0 {
+		t.Errorf("renders = %+v, want one without a budget", calls)
+	}
+}
+
+func TestPreviewIsShrunkToFitBudget(t *testing.T) {
+	h := newHarness(t)
+	const budget = 200 << 10
+	h.worker.Preview = &domain.PreviewSettings{Format: domain.PreviewFormatGIF, Segments: 5, SegmentLength: time.Second, Width: 320, FPS: 10, MaxBytes: budget}
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	calls := h.previews.Calls()
+	if len(calls) != 2 || calls[0].Width != 320 || calls[1].Width >= 320 || calls[1].Width%2 != 0 || calls[1].FPS != 10 {
+		t.Fatalf("renders = %+v, want a second, narrower one", calls)
+	}
+	data := h.output(token, id, "preview.gif")
+	if len(data) > budget {
+		t.Errorf("preview = %d bytes, over the %d budget", len(data), budget)
+	}
+	if config, err := gif.DecodeConfig(bytes.NewReader(data)); err != nil || config.Width != calls[1].Width {
+		t.Errorf("preview width = %d %v, want %d", config.Width, err, calls[1].Width)
+	}
+}
+
+func TestPreviewOverBudgetIsSkipped(t *testing.T) {
+	h := newHarness(t)
+	h.worker.Preview = &domain.PreviewSettings{Format: domain.PreviewFormatGIF, Width: 66, FPS: 8, MaxBytes: 100}
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	calls := h.previews.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("renders = %d, want 4 attempts", len(calls))
+	}
+	// The width bottoms out first, then the frame rate drops.
+	if calls[1].Width != 64 || calls[2].Width != 64 || calls[2].FPS >= calls[1].FPS || calls[3].FPS > calls[2].FPS {
+		t.Errorf("renders = %+v", calls)
+	}
+	if outputs := h.listOutputs(token, id); len(outputs) != 0 {
+		t.Errorf("outputs = %+v, want none", outputs)
+	}
+}
+
+func TestWebPPreview(t *testing.T) {
+	h := newHarness(t)
+	h.worker.Preview = &domain.PreviewSettings{Format: domain.PreviewFormatWebP}
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	outputs := h.listOutputs(token, id)
+	if len(outputs) != 1 || outputs[0].Name != "preview.webp" || outputs[0].ContentType != "image/webp" {
+		t.Fatalf("outputs = %+v, want preview.webp", outputs)
+	}
+	// Unset fields take the defaults, except the budget: zero means none.
+	want := domain.DefaultPreviewSettings
+	want.Format = domain.PreviewFormatWebP
+	want.MaxBytes = 0
+	if calls := h.previews.Calls(); len(calls) != 1 || calls[0] != want {
+		t.Errorf("renders = %+v, want %+v", calls, want)
+	}
+}
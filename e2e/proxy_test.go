@@ -0,0 +1,75 @@
+// e2e/proxy_test.go
+package e2e
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+func TestProxyRenditionIsServedWithRanges(t *testing.T) {
+	h := newHarness(t)
+	h.worker.Proxy = &domain.ProxySettings{MaxWidth: 640, MaxHeight: 360, VideoKbps: 800}
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mov", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	want := domain.ProxySettings{MaxWidth: 640, MaxHeight: 360, VideoKbps: 800, AudioKbps: domain.DefaultProxySettings.AudioKbps}
+	if calls := h.transcoder.Calls(); len(calls) != 1 || calls[0] != want {
+		t.Errorf("transcodes = %+v, want %+v", calls, want)
+	}
+	outputs := h.listOutputs(token, id)
+	if len(outputs) != 1 || outputs[0].Name != "proxy.mp4" || outputs[0].Kind != "proxy" || outputs[0].ContentType != "video/mp4" {
+		t.Fatalf("outputs = %+v, want proxy.mp4", outputs)
+	}
+	proxy := h.output(token, id, "proxy.mp4")
+	if int64(len(proxy)) != outputs[0].SizeBytes || sha256Hex(proxy) != outputs[0].SHA256 {
+		t.Errorf("proxy body does not match its size or sha256")
+	}
+	// Faststart puts the index before the media.
+	if moov, mdat := bytes.Index(proxy, []byte("moov")), bytes.Index(proxy, []byte("mdat")); moov < 0 || moov > mdat {
+		t.Errorf("moov at %d, mdat at %d, want moov first", moov, mdat)
+	}
+
+	// Players seek by asking for the bytes they need.
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/videos/%d/outputs/proxy.mp4", h.server.URL, id), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Range", "bytes=1000-1999")
+	resp := h.send(req)
+	body := readAll(t, resp)
+	if resp.StatusCode != http.StatusPartialContent || !bytes.Equal(body, proxy[1000:2000]) {
+		t.Fatalf("ranged proxy = %d, %d bytes", resp.StatusCode, len(body))
+	}
+	if got, want := resp.Header.Get("Content-Range"), fmt.Sprintf("bytes 1000-1999/%d", len(proxy)); got != want {
+		t.Errorf("Content-Range = %q, want %q", got, want)
+	}
+	if resp.Header.Get("Content-Type") != "video/mp4" || !strings.HasPrefix(resp.Header.Get("Content-Disposition"), "inline") || resp.Header.Get("Accept-Ranges") != "bytes" {
+		t.Errorf("ranged proxy headers = %v", resp.Header)
+	}
+}
+
+func TestFailedProxyLeavesNoPartialFile(t *testing.T) {
+	h := newHarness(t)
+	h.worker.Proxy = &domain.ProxySettings{}
+	h.transcoder.Err = errors.New("encoder crashed")
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mov", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	if calls := h.transcoder.Calls(); len(calls) != 1 || calls[0] != domain.DefaultProxySettings {
+		t.Errorf("transcodes = %+v, want the defaults", calls)
+	}
+	if outputs := h.listOutputs(token, id); len(outputs) != 0 {
+		t.Errorf("outputs = %+v, want none", outputs)
+	}
+	for _, p := range h.storage.Paths() {
+		if strings.HasSuffix(p, "proxy.mp4") {
+			t.Errorf("partial proxy %s left behind", p)
+		}
+	}
+}
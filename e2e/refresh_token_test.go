@@ -0,0 +1,124 @@
+// e2e/refresh_token_test.go
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/infrastructure"
+)
+
+func (h *harness) issueRefreshToken(token string) string {
+	h.t.Helper()
+	resp := h.do(http.MethodPost, "/auth/refresh-tokens", token, nil, "")
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Cache-Control") != "no-store" {
+		h.t.Fatalf("issue refresh token = %d: %s", resp.StatusCode, readAll(h.t, resp))
+	}
+	var issued infrastructure.RefreshTokenResponse
+	decodeJSON(h.t, resp, &issued)
+	return issued.RefreshToken
+}
+
+// refresh posts refreshToken to path, /auth/refresh or /auth/revoke.
+func (h *harness) refresh(path, refreshToken string) *http.Response {
+	h.t.Helper()
+	return h.do(http.MethodPost, path, "", strings.NewReader(fmt.Sprintf(`{"refresh_token": %q}`, refreshToken)), "application/json")
+}
+
+func (h *harness) refreshOK(refreshToken string) infrastructure.TokenResponse {
+	h.t.Helper()
+	resp := h.refresh("/auth/refresh", refreshToken)
+	if resp.StatusCode != http.StatusOK {
+		h.t.Fatalf("refresh = %d: %s", resp.StatusCode, readAll(h.t, resp))
+	}
+	var tokens infrastructure.TokenResponse
+	decodeJSON(h.t, resp, &tokens)
+	return tokens
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	h := newHarness(t)
+	first := h.issueRefreshToken(h.tokenWithRole(1, domain.RoleOperator))
+
+	tokens := h.refreshOK(first)
+	if tokens.TokenType != "Bearer" || tokens.ExpiresIn <= 0 || tokens.RefreshToken == "" || tokens.RefreshToken == first {
+		t.Fatalf("tokens = %+v, want a new access and refresh token", tokens)
+	}
+	// The access token speaks for the same user and role.
+	if resp := h.do(http.MethodGet, "/admin/videos", tokens.AccessToken, nil, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("refreshed access token = %d, want 200", resp.StatusCode)
+	}
+	second := h.refreshOK(tokens.RefreshToken)
+	if !second.RefreshTokenExpiresAt.Equal(tokens.RefreshTokenExpiresAt) {
+		t.Errorf("rotated expiry = %v, want the session's %v", second.RefreshTokenExpiresAt, tokens.RefreshTokenExpiresAt)
+	}
+
+	// Replaying a spent token ends the session, including its newest token.
+	if resp := h.refresh("/auth/refresh", first); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("replayed refresh token = %d, want 401", resp.StatusCode)
+	}
+	if resp := h.refresh("/auth/refresh", second.RefreshToken); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("latest token after a replay = %d, want 401", resp.StatusCode)
+	}
+
+	for name, body := range map[string]string{
+		"unknown token": `{"refresh_token": "vpr_unknown"}`,
+		"api key":       `{"refresh_token": "vps_unknown"}`,
+	} {
+		if resp := h.do(http.MethodPost, "/auth/refresh", "", strings.NewReader(body), "application/json"); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s = %d, want 401", name, resp.StatusCode)
+		}
+	}
+	if resp := h.do(http.MethodPost, "/auth/refresh", "", strings.NewReader(`{}`), "application/json"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("missing token = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestRefreshTokensAreRevoked(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+
+	session := h.issueRefreshToken(token)
+	if resp := h.refresh("/auth/revoke", session); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoke = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	if resp := h.refresh("/auth/refresh", session); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("revoked refresh token = %d, want 401", resp.StatusCode)
+	}
+	if resp := h.refresh("/auth/revoke", "vpr_unknown"); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("revoking an unknown token = %d, want 204", resp.StatusCode)
+	}
+
+	// Logging out everywhere ends every session of the user only.
+	laptop, phone, other := h.issueRefreshToken(token), h.issueRefreshToken(token), h.issueRefreshToken(h.token(2))
+	if resp := h.do(http.MethodDelete, "/auth/refresh-tokens", token, nil, ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoke all = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	for _, session := range []string{laptop, phone} {
+		if resp := h.refresh("/auth/refresh", session); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("session after revoking all = %d, want 401", resp.StatusCode)
+		}
+	}
+	h.refreshOK(other)
+}
+
+func TestRefreshTokensNeedAnIdentityProviderToken(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+
+	// Otherwise a session could be renewed forever from its own tokens.
+	refreshed := h.refreshOK(h.issueRefreshToken(token)).AccessToken
+	if resp := h.do(http.MethodPost, "/auth/refresh-tokens", refreshed, nil, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("issue with a refreshed access token = %d, want 403", resp.StatusCode)
+	}
+	key := h.createAPIKey(token, `{"scopes": ["upload", "read", "share"]}`).Key
+	if resp := h.withAPIKey(http.MethodPost, "/auth/refresh-tokens", key); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("issue with an api key = %d, want 403", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodPost, "/auth/refresh-tokens", "", nil, ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("issue without credentials = %d, want 401", resp.StatusCode)
+	}
+}
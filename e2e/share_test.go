@@ -0,0 +1,294 @@
+// e2e/share_test.go
+package e2e
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/vitovidale/video-processor-service/infrastructure"
+)
+
+// createShareLink shares videoID as the holder of token.
+func (h *harness) createShareLink(token string, videoID int, body string) infrastructure.ShareLinkResponse {
+	h.t.Helper()
+	resp := h.do(http.MethodPost, fmt.Sprintf("/videos/%d/share", videoID), token, strings.NewReader(body), "application/json")
+	if resp.StatusCode != http.StatusCreated {
+		h.t.Fatalf("create share link = %d: %s", resp.StatusCode, readAll(h.t, resp))
+	}
+	var link infrastructure.ShareLinkResponse
+	decodeJSON(h.t, resp, &link)
+	return link
+}
+
+// fetch requests rawURL without credentials.
+func (h *harness) fetch(rawURL string) *http.Response {
+	h.t.Helper()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		h.t.Fatalf("new request: %v", err)
+	}
+	return h.send(req)
+}
+
+func TestShareLinkServesArtifactWithoutAuth(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "deliverable.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+	artifact, _ := h.storage.ReadFile(status.ProcessedFilePath)
+
+	link := h.createShareLink(token, id, `{"expires_in": 3600, "max_downloads": 2}`)
+	if !strings.HasPrefix(link.URL, h.server.URL+"/share/") {
+		t.Fatalf("share url = %q", link.URL)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp := h.fetch(link.URL)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("download %d = %d: %s", i+1, resp.StatusCode, readAll(t, resp))
+		}
+		if body := readAll(t, resp); !bytes.Equal(body, artifact) {
+			t.Errorf("download %d returned %d bytes, want the %d byte artifact", i+1, len(body), len(artifact))
+		}
+	}
+	if resp := h.fetch(link.URL); resp.StatusCode != http.StatusGone {
+		t.Errorf("download past the limit = %d, want 410", resp.StatusCode)
+	}
+
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/shares", id), token, nil, "")
+	var links []infrastructure.ShareLinkResponse
+	decodeJSON(t, resp, &links)
+	if len(links) != 1 || links[0].DownloadCount != 2 || links[0].URL != "" {
+		t.Errorf("listed links = %+v", links)
+	}
+
+	resp = h.do(http.MethodGet, fmt.Sprintf("/videos/%d/shares/%d/accesses", id, link.ID), token, nil, "")
+	var accesses []infrastructure.ShareAccessResponse
+	decodeJSON(t, resp, &accesses)
+	var outcomes []string
+	for _, a := range accesses {
+		outcomes = append(outcomes, a.Outcome)
+	}
+	if strings.Join(outcomes, ",") != "exhausted,ok,ok" || accesses[0].RemoteAddr == "" {
+		t.Errorf("access log = %+v, want exhausted,ok,ok with the client address", accesses)
+	}
+}
+
+func TestShareLinksNeedTheShareScope(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "clip.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+	link := h.createShareLink(token, id, "")
+
+	readOnly := h.createAPIKey(token, `{"scopes": ["read", "upload"]}`).Key
+	sharer := h.createAPIKey(token, `{"scopes": ["share"]}`).Key
+	create := fmt.Sprintf("/videos/%d/share", id)
+	revoke := fmt.Sprintf("/videos/%d/shares/%d", id, link.ID)
+
+	for path, method := range map[string]string{create: http.MethodPost, revoke: http.MethodDelete} {
+		if resp := h.withAPIKey(method, path, readOnly); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s %s with a read/upload key = %d, want 403", method, path, resp.StatusCode)
+		}
+	}
+	if resp := h.withAPIKey(http.MethodGet, fmt.Sprintf("/videos/%d/shares", id), readOnly); resp.StatusCode != http.StatusOK {
+		t.Errorf("listing links with a read key = %d, want 200", resp.StatusCode)
+	}
+	if resp := h.withAPIKey(http.MethodPost, create, sharer); resp.StatusCode != http.StatusCreated {
+		t.Errorf("create with a share key = %d, want 201", resp.StatusCode)
+	}
+	if resp := h.withAPIKey(http.MethodDelete, revoke, sharer); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("revoke with a share key = %d, want 204", resp.StatusCode)
+	}
+}
+
+func TestShareLinkRejectsTampering(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "a.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+	link := h.createShareLink(token, id, "")
+
+	u, _ := url.Parse(link.URL)
+	query := u.Query()
+	tamper := func(mutate func(u *url.URL, q url.Values)) string {
+		u, _ := url.Parse(link.URL)
+		q := u.Query()
+		mutate(u, q)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	for name, rawURL := range map[string]string{
+		"extended expiry": tamper(func(_ *url.URL, q url.Values) { q.Set("expires", "99999999999") }),
+		"other link id":   tamper(func(u *url.URL, _ url.Values) { u.Path = fmt.Sprintf("/share/%d", link.ID+1) }),
+		"forged sig":      tamper(func(_ *url.URL, q url.Values) { q.Set("sig", strings.Repeat("A", len(query.Get("sig")))) }),
+		"missing sig":     tamper(func(_ *url.URL, q url.Values) { q.Del("sig") }),
+	} {
+		if resp := h.fetch(rawURL); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s = %d, want 403", name, resp.StatusCode)
+		}
+	}
+	if resp := h.fetch(link.URL); resp.StatusCode != http.StatusOK {
+		t.Errorf("untampered url = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestShareLinkRevocation(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "a.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+	link := h.createShareLink(token, id, `{"expires_in": 600}`)
+
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/videos/%d/shares/%d", id, link.ID), h.token(2), nil, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("revoke by another user = %d, want 403", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/videos/%d/shares/%d", id, link.ID), token, nil, ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoke = %d", resp.StatusCode)
+	}
+	if resp := h.fetch(link.URL); resp.StatusCode != http.StatusGone {
+		t.Errorf("revoked link = %d, want 410", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodDelete, fmt.Sprintf("/videos/%d/shares/%d", id, link.ID+1), token, nil, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("revoke unknown link = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestShareLinkCreationRules(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "a.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	for _, body := range []string{`{"expires_in": 30}`, `{"expires_in": 864000}`, `{"max_downloads": -1}`} {
+		if resp := h.do(http.MethodPost, fmt.Sprintf("/videos/%d/share", id), token, strings.NewReader(body), "application/json"); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("share with %s = %d, want 400", body, resp.StatusCode)
+		}
+	}
+	if resp := h.do(http.MethodPost, fmt.Sprintf("/videos/%d/share", id), h.token(2), nil, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("share by another user = %d, want 403", resp.StatusCode)
+	}
+
+	// Workspace viewers can download but not hand the result to outsiders.
+	workspaceID := h.createWorkspace(token, "Clientes", map[int]string{3: "viewer"})
+	resp := h.uploadWithHeaders(token, "team.mp4", []byte("team"), map[string]string{infrastructure.WorkspaceHeader: fmt.Sprint(workspaceID)})
+	var uploaded struct {
+		VideoStatusID int `json:"video_status_id"`
+	}
+	decodeJSON(t, resp, &uploaded)
+	h.waitForWorkspaceStatus(token, workspaceID, uploaded.VideoStatusID, "COMPLETED")
+	if resp := h.do(http.MethodPost, fmt.Sprintf("/videos/%d/share", uploaded.VideoStatusID), h.token(3), nil, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("share by workspace viewer = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestShareLinkDownloadCanBeResumed(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "deliverable.mp4", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+	artifact, _ := h.storage.ReadFile(status.ProcessedFilePath)
+	link := h.createShareLink(token, id, `{"expires_in": 3600, "max_downloads": 1}`)
+
+	get := func(method string, header map[string]string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, link.URL, nil)
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		return h.send(req)
+	}
+
+	// A player probes first; that is not a download.
+	head := get(http.MethodHead, nil)
+	if head.StatusCode != http.StatusOK || head.Header.Get("ETag") == "" {
+		t.Fatalf("HEAD = %d %v", head.StatusCode, head.Header)
+	}
+	etag := head.Header.Get("ETag")
+
+	first := get(http.MethodGet, map[string]string{"Range": "bytes=0-9"})
+	if body := readAll(t, first); first.StatusCode != http.StatusPartialContent || !bytes.Equal(body, artifact[:10]) {
+		t.Fatalf("first range = %d, %d bytes", first.StatusCode, len(body))
+	}
+	transfer := first.Header.Get("ETag")
+	if transfer == "" || transfer == etag {
+		t.Fatalf("counted transfer ETag = %q, want one of its own", transfer)
+	}
+	// Resuming the same transfer, or revalidating it, is not another
+	// download, even though the link allows only one.
+	rest := get(http.MethodGet, map[string]string{"Range": "bytes=10-", "If-Range": transfer})
+	if body := readAll(t, rest); rest.StatusCode != http.StatusPartialContent || !bytes.Equal(body, artifact[10:]) {
+		t.Fatalf("resumed range = %d, %d bytes", rest.StatusCode, len(body))
+	}
+	if resp := get(http.MethodGet, map[string]string{"If-None-Match": etag}); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("conditional download = %d, want 304", resp.StatusCode)
+	}
+
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/shares", id), token, nil, "")
+	var links []infrastructure.ShareLinkResponse
+	decodeJSON(t, resp, &links)
+	if len(links) != 1 || links[0].DownloadCount != 1 {
+		t.Errorf("listed links = %+v, want one download", links)
+	}
+	if resp := get(http.MethodGet, nil); resp.StatusCode != http.StatusGone {
+		t.Errorf("second full download = %d, want 410", resp.StatusCode)
+	}
+}
+
+func TestExhaustedShareLinkRefusesRangesOutsideATransfer(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	id := h.uploadOK(token, "deliverable.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+	link := h.createShareLink(token, id, `{"expires_in": 3600, "max_downloads": 1}`)
+
+	get := func(header map[string]string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, link.URL, nil)
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		return h.send(req)
+	}
+
+	whole := get(nil)
+	readAll(t, whole)
+	if whole.StatusCode != http.StatusOK {
+		t.Fatalf("first download = %d, want 200", whole.StatusCode)
+	}
+	etag := strings.Split(strings.Trim(whole.Header.Get("ETag"), `"`), ".")[0]
+	transfer := whole.Header.Get("ETag")
+
+	for _, header := range []map[string]string{
+		{"Range": "bytes=1-"},
+		{"Range": "bytes=-999999999"},
+		{"Range": "bytes=-4"},
+		{"Range": "bytes=0-0,2-"},
+		{"Range": "bytes=1-", "If-Range": `"` + etag + `"`},
+		// The transfer was sent whole; there is nothing left to resume.
+		{"Range": "bytes=1-", "If-Range": transfer},
+		{"Range": "bytes=-999999999", "If-Range": transfer},
+	} {
+		resp := get(header)
+		if body := readAll(t, resp); resp.StatusCode != http.StatusGone {
+			t.Errorf("%v on an exhausted link = %d (%d bytes), want 410", header, resp.StatusCode, len(body))
+		}
+	}
+
+	resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/shares", id), token, nil, "")
+	var links []infrastructure.ShareLinkResponse
+	decodeJSON(t, resp, &links)
+	if len(links) != 1 || links[0].DownloadCount != 1 {
+		t.Errorf("listed links = %+v, want one download", links)
+	}
+}
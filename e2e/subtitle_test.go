@@ -0,0 +1,83 @@
+// e2e/subtitle_test.go
+package e2e
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+var embeddedSubtitles = []domain.SubtitleStream{
+	{Index: 0, Codec: "subrip", Language: "por", Default: true},
+	{Index: 1, Codec: "hdmv_pgs_subtitle", Language: "eng"},
+	{Index: 2, Codec: "ass", Language: "spa", Title: "Signs", Forced: true},
+	{Index: 3, Codec: "mov_text", Language: "../x"},
+}
+
+func TestSubtitleStreamsAreExtracted(t *testing.T) {
+	h := newHarness(t)
+	h.subtitles.Streams = embeddedSubtitles
+	h.worker.SubtitleFormat = domain.SubtitleFormatWebVTT
+	token := h.token(1)
+	id := h.uploadOK(token, "movie.mkv", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	if !reflect.DeepEqual(status.SubtitleStreams, embeddedSubtitles) {
+		t.Errorf("subtitle streams = %+v, want %+v", status.SubtitleStreams, embeddedSubtitles)
+	}
+
+	// The PGS stream is an image and is not extracted; a language tag that
+	// is not one stays out of the name.
+	outputs := h.listOutputs(token, id)
+	want := []struct{ name, language string }{
+		{"subtitles_0.por.vtt", "por"},
+		{"subtitles_2.spa.vtt", "spa"},
+		{"subtitles_3.vtt", "../x"},
+	}
+	if len(outputs) != len(want) {
+		t.Fatalf("outputs = %+v, want %d", outputs, len(want))
+	}
+	for i, w := range want {
+		o := outputs[i]
+		if o.Name != w.name || o.Language != w.language || o.Kind != "subtitles" || o.ContentType != "text/vtt; charset=utf-8" {
+			t.Errorf("output %d = %+v, want %+v", i, o, w)
+		}
+	}
+	if track := string(h.output(token, id, "subtitles_2.spa.vtt")); !strings.HasPrefix(track, "WEBVTT\n") || !strings.Contains(track, "spa") {
+		t.Errorf("subtitles = %q", track)
+	}
+}
+
+func TestSubtitlesAsSRT(t *testing.T) {
+	h := newHarness(t)
+	h.subtitles.Streams = embeddedSubtitles[:1]
+	h.worker.SubtitleFormat = domain.SubtitleFormatSRT
+	token := h.token(1)
+	id := h.uploadOK(token, "movie.mp4", []byte("video"))
+	h.waitForStatus(token, id, "COMPLETED")
+
+	outputs := h.listOutputs(token, id)
+	if len(outputs) != 1 || outputs[0].Name != "subtitles_0.por.srt" || outputs[0].ContentType != "application/x-subrip; charset=utf-8" {
+		t.Fatalf("outputs = %+v, want subtitles_0.por.srt", outputs)
+	}
+	if track := string(h.output(token, id, "subtitles_0.por.srt")); !strings.HasPrefix(track, "1\n00:00:01,000 --> ") {
+		t.Errorf("subtitles = %q", track)
+	}
+}
+
+func TestSubtitleStreamsAreProbedWithoutExtraction(t *testing.T) {
+	h := newHarness(t)
+	h.subtitles.Streams = embeddedSubtitles
+	token := h.token(1)
+	id := h.uploadOK(token, "movie.mkv", []byte("video"))
+	status := h.waitForStatus(token, id, "COMPLETED")
+
+	if len(status.SubtitleStreams) != len(embeddedSubtitles) {
+		t.Errorf("subtitle streams = %+v, want %d", status.SubtitleStreams, len(embeddedSubtitles))
+	}
+	if outputs := h.listOutputs(token, id); len(outputs) != 0 {
+		t.Errorf("outputs = %+v, want none", outputs)
+	}
+}
@@ -0,0 +1,280 @@
+// e2e/workspace_test.go
+package e2e
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/vitovidale/video-processor-service/infrastructure"
+)
+
+// createWorkspace creates a workspace owned by the holder of token and adds
+// the given members.
+func (h *harness) createWorkspace(token, name string, members map[int]string) int {
+	h.t.Helper()
+	resp := h.do(http.MethodPost, "/workspaces", token, strings.NewReader(fmt.Sprintf(`{"name": %q}`, name)), "application/json")
+	if resp.StatusCode != http.StatusCreated {
+		h.t.Fatalf("create workspace = %d: %s", resp.StatusCode, readAll(h.t, resp))
+	}
+	var workspace infrastructure.WorkspaceResponse
+	decodeJSON(h.t, resp, &workspace)
+	for userID, role := range members {
+		path := fmt.Sprintf("/workspaces/%d/members/%d", workspace.ID, userID)
+		resp := h.do(http.MethodPut, path, token, strings.NewReader(fmt.Sprintf(`{"role": %q}`, role)), "application/json")
+		if resp.StatusCode != http.StatusOK {
+			h.t.Fatalf("add member %d = %d: %s", userID, resp.StatusCode, readAll(h.t, resp))
+		}
+	}
+	return workspace.ID
+}
+
+// inWorkspace performs a request with workspaceID selected by header.
+func (h *harness) inWorkspace(method, path, token string, workspaceID int) *http.Response {
+	h.t.Helper()
+	req, err := http.NewRequest(method, h.server.URL+path, nil)
+	if err != nil {
+		h.t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(infrastructure.WorkspaceHeader, strconv.Itoa(workspaceID))
+	return h.send(req)
+}
+
+func (h *harness) workspaceStatuses(token string, workspaceID int) []infrastructure.VideoStatusResponse {
+	h.t.Helper()
+	resp := h.inWorkspace(http.MethodGet, "/videos/status", token, workspaceID)
+	if resp.StatusCode != http.StatusOK {
+		h.t.Fatalf("workspace status list = %d: %s", resp.StatusCode, readAll(h.t, resp))
+	}
+	var out []infrastructure.VideoStatusResponse
+	decodeJSON(h.t, resp, &out)
+	return out
+}
+
+// waitForWorkspaceStatus polls the workspace's status list until videoID
+// reaches want.
+func (h *harness) waitForWorkspaceStatus(token string, workspaceID, videoID int, want string) {
+	h.t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	var last string
+	for time.Now().Before(deadline) {
+		for _, s := range h.workspaceStatuses(token, workspaceID) {
+			if s.ID == videoID {
+				last = s.Status
+			}
+		}
+		if last == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	h.t.Fatalf("video %d status = %q, want %q", videoID, last, want)
+}
+
+func TestWorkspaceMembersShareVideos(t *testing.T) {
+	h := newHarness(t)
+	owner, member, viewer, outsider := h.token(1), h.token(2), h.token(3), h.token(4)
+	workspaceID := h.createWorkspace(owner, "Edição", map[int]string{2: "member", 3: "viewer"})
+
+	header := map[string]string{infrastructure.WorkspaceHeader: strconv.Itoa(workspaceID)}
+	resp := h.uploadWithHeaders(owner, "team.mp4", []byte("team video"), header)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("workspace upload = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	var uploaded struct {
+		VideoStatusID int `json:"video_status_id"`
+	}
+	decodeJSON(t, resp, &uploaded)
+	id := uploaded.VideoStatusID
+	h.uploadOK(owner, "private.mp4", []byte("personal video"))
+
+	// Every member sees the workspace's videos; personal lists stay apart.
+	for _, token := range []string{owner, member, viewer} {
+		videos := h.workspaceStatuses(token, workspaceID)
+		if len(videos) != 1 || videos[0].ID != id || videos[0].WorkspaceID != workspaceID {
+			t.Errorf("workspace videos = %+v, want only video %d", videos, id)
+		}
+	}
+	if personal := h.statuses(owner); len(personal) != 1 || personal[0].ID == id {
+		t.Errorf("owner's personal videos = %+v, want only the personal upload", personal)
+	}
+	if personal := h.statuses(member); len(personal) != 0 {
+		t.Errorf("member's personal videos = %+v, want none", personal)
+	}
+
+	// Access to a video follows membership of its workspace, whichever
+	// workspace is active.
+	h.waitForWorkspaceStatus(owner, workspaceID, id, "COMPLETED")
+	for name, tt := range map[string]struct {
+		token string
+		want  int
+	}{
+		"member":   {member, http.StatusOK},
+		"viewer":   {viewer, http.StatusOK},
+		"outsider": {outsider, http.StatusForbidden},
+	} {
+		if resp := h.do(http.MethodGet, fmt.Sprintf("/videos/%d/download", id), tt.token, nil, ""); resp.StatusCode != tt.want {
+			t.Errorf("download by %s = %d, want %d", name, resp.StatusCode, tt.want)
+		}
+	}
+
+	if resp := h.uploadWithHeaders(viewer, "nope.mp4", []byte("x"), header); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("upload by viewer = %d, want 403", resp.StatusCode)
+	}
+	if resp := h.inWorkspace(http.MethodGet, "/videos/status", outsider, workspaceID); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("status in a workspace the user isn't in = %d, want 403", resp.StatusCode)
+	}
+	if resp := h.uploadWithHeaders(member, "bad.mp4", []byte("x"), map[string]string{infrastructure.WorkspaceHeader: "abc"}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("malformed workspace header = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestWorkspaceClaimSelectsWorkspace(t *testing.T) {
+	h := newHarness(t)
+	workspaceID := h.createWorkspace(h.token(1), "Marketing", map[int]string{2: "member"})
+
+	claims := h.claims(2)
+	claims.WorkspaceID = workspaceID
+	token := h.sign(jwt.SigningMethodHS256, testKeyID, testHMACKeys[testKeyID], claims)
+	id := h.uploadOK(token, "claim.mp4", []byte("x"))
+
+	if videos := h.workspaceStatuses(h.token(1), workspaceID); len(videos) != 1 || videos[0].ID != id {
+		t.Errorf("workspace videos = %+v, want the upload made with the claim", videos)
+	}
+
+	// The header overrides the claim.
+	other := h.createWorkspace(h.token(2), "Pessoal da equipe", nil)
+	req, _ := http.NewRequest(http.MethodGet, h.server.URL+"/videos/status", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(infrastructure.WorkspaceHeader, strconv.Itoa(other))
+	var videos []infrastructure.VideoStatusResponse
+	decodeJSON(t, h.send(req), &videos)
+	if len(videos) != 0 {
+		t.Errorf("videos with header selecting another workspace = %+v, want none", videos)
+	}
+
+	// Membership is checked for the claim as well.
+	claims = h.claims(3)
+	claims.WorkspaceID = workspaceID
+	if resp := h.do(http.MethodGet, "/videos/status", h.sign(jwt.SigningMethodHS256, testKeyID, testHMACKeys[testKeyID], claims), nil, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("claim for a workspace the user isn't in = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestWorkspaceMemberReprocessesFailedVideo(t *testing.T) {
+	h := newHarness(t)
+	h.processor.Err = errors.New("boom")
+	owner, member, viewer := h.token(1), h.token(2), h.token(3)
+	workspaceID := h.createWorkspace(owner, "Ops", map[int]string{2: "member", 3: "viewer"})
+
+	resp := h.uploadWithHeaders(owner, "retry.mp4", []byte("x"), map[string]string{infrastructure.WorkspaceHeader: strconv.Itoa(workspaceID)})
+	var uploaded struct {
+		VideoStatusID int `json:"video_status_id"`
+	}
+	decodeJSON(t, resp, &uploaded)
+	id := uploaded.VideoStatusID
+	h.waitForWorkspaceStatus(owner, workspaceID, id, "FAILED")
+	h.processor.Err = nil
+
+	path := fmt.Sprintf("/videos/%d/reprocess", id)
+	if resp := h.do(http.MethodPost, path, viewer, nil, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("reprocess by viewer = %d, want 403", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodPost, path, h.token(4), nil, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("reprocess by outsider = %d, want 403", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodPost, path, member, nil, ""); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("reprocess by member = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	h.waitForWorkspaceStatus(member, workspaceID, id, "COMPLETED")
+}
+
+func TestWorkspaceMembershipManagement(t *testing.T) {
+	h := newHarness(t)
+	owner, member := h.token(1), h.token(2)
+	workspaceID := h.createWorkspace(owner, "Produto", map[int]string{2: "member"})
+	members := func(id int) string { return fmt.Sprintf("/workspaces/%d/members/%d", workspaceID, id) }
+	role := func(r string) *strings.Reader { return strings.NewReader(fmt.Sprintf(`{"role": %q}`, r)) }
+
+	resp := h.do(http.MethodGet, "/workspaces", member, nil, "")
+	var listed []infrastructure.WorkspaceResponse
+	decodeJSON(t, resp, &listed)
+	if len(listed) != 1 || listed[0].ID != workspaceID || listed[0].Role != "member" || listed[0].Name != "Produto" {
+		t.Errorf("member's workspaces = %+v", listed)
+	}
+
+	if resp := h.do(http.MethodPut, members(5), member, role("viewer"), "application/json"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("member adding a member = %d, want 403", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodPut, members(5), owner, role("superuser"), "application/json"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown workspace role = %d, want 400", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodGet, fmt.Sprintf("/workspaces/%d/members", workspaceID), h.token(9), nil, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("outsider listing members = %d, want 404", resp.StatusCode)
+	}
+
+	// The only owner can neither leave nor step down.
+	if resp := h.do(http.MethodDelete, members(1), owner, nil, ""); resp.StatusCode != http.StatusConflict {
+		t.Errorf("last owner leaving = %d, want 409", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodPut, members(1), owner, role("member"), "application/json"); resp.StatusCode != http.StatusConflict {
+		t.Errorf("last owner stepping down = %d, want 409", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodPut, members(2), owner, role("owner"), "application/json"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("promote member = %d", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodDelete, members(1), owner, nil, ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("owner leaving once another owner exists = %d, want 204", resp.StatusCode)
+	}
+
+	resp = h.do(http.MethodGet, fmt.Sprintf("/workspaces/%d/members", workspaceID), member, nil, "")
+	var remaining []infrastructure.WorkspaceMemberResponse
+	decodeJSON(t, resp, &remaining)
+	if len(remaining) != 1 || remaining[0].UserID != 2 || remaining[0].Role != "owner" {
+		t.Errorf("members = %+v, want only user 2 as owner", remaining)
+	}
+
+	key := h.createAPIKey(member, `{"scopes": ["upload", "read"]}`).Key
+	req, _ := http.NewRequest(http.MethodPost, h.server.URL+"/workspaces", strings.NewReader(`{"name": "x"}`))
+	req.Header.Set(infrastructure.APIKeyHeader, key)
+	if resp := h.send(req); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("creating a workspace with an api key = %d, want 403", resp.StatusCode)
+	}
+	if resp := h.do(http.MethodPost, "/workspaces", member, strings.NewReader(`{"name": "  "}`), "application/json"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("blank workspace name = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestIdempotencyKeyIsBoundToTheWorkspace(t *testing.T) {
+	h := newHarness(t)
+	token := h.token(1)
+	first := h.createWorkspace(token, "first", nil)
+	second := h.createWorkspace(token, "second", nil)
+	content := []byte("same bytes")
+	inWorkspace := func(workspaceID int) map[string]string {
+		return map[string]string{"Idempotency-Key": "retry-1", infrastructure.WorkspaceHeader: strconv.Itoa(workspaceID)}
+	}
+
+	if resp := h.uploadWithHeaders(token, "clip.mp4", content, inWorkspace(first)); resp.StatusCode != http.StatusOK {
+		t.Fatalf("upload = %d: %s", resp.StatusCode, readAll(t, resp))
+	}
+	// Replaying would answer with a video of the first workspace.
+	if resp := h.uploadWithHeaders(token, "clip.mp4", content, inWorkspace(second)); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("same key in another workspace = %d, want 422", resp.StatusCode)
+	}
+	if resp := h.uploadWithHeaders(token, "clip.mp4", content, map[string]string{"Idempotency-Key": "retry-1"}); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("same key as a personal upload = %d, want 422", resp.StatusCode)
+	}
+	if resp := h.uploadWithHeaders(token, "clip.mp4", content, inWorkspace(first)); resp.Header.Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry in the same workspace = %d, want a replay", resp.StatusCode)
+	}
+	if videos := h.workspaceStatuses(token, second); len(videos) != 0 {
+		t.Errorf("second workspace has %d videos, want none", len(videos))
+	}
+}
@@ -0,0 +1,218 @@
+// infrastructure/admin_handlers.go
+package infrastructure
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/usecase"
+)
+
+// AdminHandlers serve the /admin routes, which act on every user's videos.
+type AdminHandlers struct {
+	ListAllVideosUC  *usecase.ListAllVideosUseCase
+	ForceFailVideoUC *usecase.ForceFailVideoUseCase
+	RequeueVideoUC   *usecase.RequeueVideoUseCase
+	DeleteVideoUC    *usecase.DeleteVideoUseCase
+	QueueStatsUC     *usecase.GetQueueStatsUseCase
+	GetUserQuotaUC   *usecase.GetUserQuotaUseCase
+	SetUserQuotaUC   *usecase.SetUserQuotaUseCase
+}
+
+// AdminVideoResponse adds the owner to VideoStatusResponse.
+type AdminVideoResponse struct {
+	UserID int `json:"user_id"`
+	VideoStatusResponse
+}
+
+type FailVideoRequest struct {
+	Reason string `json:"reason"`
+}
+
+type QueueStatsResponse struct {
+	Queue     string `json:"queue"`
+	Messages  int    `json:"messages"`
+	Consumers int    `json:"consumers"`
+}
+
+type UserQuotaRequest struct {
+	MaxActiveJobs  int   `json:"max_active_jobs"`
+	MaxUploadBytes int64 `json:"max_upload_bytes"`
+}
+
+type UserQuotaResponse struct {
+	UserID         int        `json:"user_id"`
+	MaxActiveJobs  int        `json:"max_active_jobs"`
+	MaxUploadBytes int64      `json:"max_upload_bytes"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
+
+func NewAdminHandlers(
+	listUC *usecase.ListAllVideosUseCase,
+	failUC *usecase.ForceFailVideoUseCase,
+	requeueUC *usecase.RequeueVideoUseCase,
+	deleteUC *usecase.DeleteVideoUseCase,
+	queueUC *usecase.GetQueueStatsUseCase,
+	getQuotaUC *usecase.GetUserQuotaUseCase,
+	setQuotaUC *usecase.SetUserQuotaUseCase,
+) *AdminHandlers {
+	return &AdminHandlers{
+		ListAllVideosUC:  listUC,
+		ForceFailVideoUC: failUC,
+		RequeueVideoUC:   requeueUC,
+		DeleteVideoUC:    deleteUC,
+		QueueStatsUC:     queueUC,
+		GetUserQuotaUC:   getQuotaUC,
+		SetUserQuotaUC:   setQuotaUC,
+	}
+}
+
+func newAdminVideoResponse(v domain.Video) AdminVideoResponse {
+	return AdminVideoResponse{UserID: v.UserID, VideoStatusResponse: newVideoStatusResponse(v)}
+}
+
+func newUserQuotaResponse(q domain.UserQuota) UserQuotaResponse {
+	response := UserQuotaResponse{UserID: q.UserID, MaxActiveJobs: q.MaxActiveJobs, MaxUploadBytes: q.MaxUploadBytes}
+	if !q.UpdatedAt.IsZero() {
+		response.UpdatedAt = &q.UpdatedAt
+	}
+	return response
+}
+
+func (h *AdminHandlers) ListVideosHandler(c *gin.Context) {
+	var filter domain.VideoFilter
+	for name, target := range map[string]*int{"user_id": &filter.UserID, "limit": &filter.Limit, "offset": &filter.Offset} {
+		if raw := c.Query(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+				return
+			}
+			*target = n
+		}
+	}
+	if status := c.Query("status"); status != "" {
+		filter.Status = domain.VideoStatus(status)
+		if !filter.Status.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+			return
+		}
+	}
+
+	videos, err := h.ListAllVideosUC.Execute(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	response := make([]AdminVideoResponse, 0, len(videos))
+	for _, v := range videos {
+		response = append(response, newAdminVideoResponse(v))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandlers) FailVideoHandler(c *gin.Context) {
+	actorID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+	var req FailVideoRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	video, err := h.ForceFailVideoUC.Execute(c.Request.Context(), actorID, videoID, req.Reason)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newAdminVideoResponse(*video))
+}
+
+func (h *AdminHandlers) RequeueVideoHandler(c *gin.Context) {
+	actorID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+
+	video, err := h.RequeueVideoUC.Execute(c.Request.Context(), actorID, videoID)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, newAdminVideoResponse(*video))
+}
+
+func (h *AdminHandlers) DeleteVideoHandler(c *gin.Context) {
+	actorID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+
+	if err := h.DeleteVideoUC.Execute(c.Request.Context(), actorID, videoID); err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AdminHandlers) QueueStatsHandler(c *gin.Context) {
+	stats, err := h.QueueStatsUC.Execute(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, QueueStatsResponse{Queue: stats.Name, Messages: stats.Messages, Consumers: stats.Consumers})
+}
+
+func (h *AdminHandlers) GetUserQuotaHandler(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	quota, err := h.GetUserQuotaUC.Execute(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newUserQuotaResponse(*quota))
+}
+
+func (h *AdminHandlers) SetUserQuotaHandler(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	var req UserQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	quota, err := h.SetUserQuotaUC.Execute(domain.UserQuota{UserID: userID, MaxActiveJobs: req.MaxActiveJobs, MaxUploadBytes: req.MaxUploadBytes})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidQuota) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newUserQuotaResponse(*quota))
+}
@@ -0,0 +1,123 @@
+// infrastructure/api_key_handlers.go
+package infrastructure
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/usecase"
+)
+
+type APIKeyHandlers struct {
+	CreateAPIKeyUC *usecase.CreateAPIKeyUseCase
+	ListAPIKeysUC  *usecase.ListAPIKeysUseCase
+	RevokeAPIKeyUC *usecase.RevokeAPIKeyUseCase
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string         `json:"name"`
+	Scopes    []domain.Scope `json:"scopes"`
+	ExpiresAt *time.Time     `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name,omitempty"`
+	Prefix     string         `json:"prefix"`
+	Scopes     []domain.Scope `json:"scopes"`
+	ExpiresAt  *time.Time     `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time     `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time     `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	// Key is the plaintext secret, present only in the creation response.
+	Key string `json:"key,omitempty"`
+}
+
+func NewAPIKeyHandlers(createUC *usecase.CreateAPIKeyUseCase, listUC *usecase.ListAPIKeysUseCase, revokeUC *usecase.RevokeAPIKeyUseCase) *APIKeyHandlers {
+	return &APIKeyHandlers{
+		CreateAPIKeyUC: createUC,
+		ListAPIKeysUC:  listUC,
+		RevokeAPIKeyUC: revokeUC,
+	}
+}
+
+func newAPIKeyResponse(k domain.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+func (h *APIKeyHandlers) CreateAPIKeyHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	output, err := h.CreateAPIKeyUC.Execute(usecase.CreateAPIKeyInput{
+		UserID:    userID,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAPIKeyRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := newAPIKeyResponse(*output.Key)
+	response.Key = output.Secret
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *APIKeyHandlers) ListAPIKeysHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	keys, err := h.ListAPIKeysUC.Execute(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]APIKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		response = append(response, newAPIKeyResponse(k))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *APIKeyHandlers) RevokeAPIKeyHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	keyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	if err := h.RevokeAPIKeyUC.Execute(userID, keyID); err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,166 @@
+// infrastructure/artifact_response.go
+package infrastructure
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/usecase"
+)
+
+// serveDownload writes a stored artifact with serveArtifact, or streams an
+// archive built on the fly. Streamed archives have no known length and no
+// checksum until they are written, so they are sent whole, without
+// validators or digests, and say so with Accept-Ranges: none; Range and
+// conditional headers are ignored.
+func serveDownload(c *gin.Context, download *usecase.ArtifactDownload) {
+	if download.File != nil {
+		serveArtifact(c, download.File)
+		return
+	}
+	archive := download.Archive
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", archive.Name))
+	c.Header("Content-Type", archive.Format.ContentType())
+	c.Header("Last-Modified", archive.ModTime.UTC().Format(http.TimeFormat))
+	c.Header("Accept-Ranges", "none")
+	c.Status(http.StatusOK)
+	if c.Request.Method == http.MethodHead {
+		return
+	}
+	if _, err := archive.WriteTo(c.Writer); err != nil {
+		// The status is already sent; the truncated archive fails to unpack.
+		slog.ErrorContext(c.Request.Context(), "failed to stream archive", "error", err)
+		c.Abort()
+	}
+}
+
+// classifyShareRequest tells how serving download for r counts against a
+// share link, mirroring the checks of http.ServeContent. HEAD requests and
+// 304s are probes. A single range from a given offset whose If-Range names
+// a transfer of this artifact resumes it. Anything else starts a transfer,
+// suffix and multiple ranges included.
+func classifyShareRequest(r *http.Request, download *usecase.ArtifactDownload) usecase.ShareRequest {
+	if r.Method == http.MethodHead {
+		return usecase.ShareRequest{Kind: usecase.ShareProbe}
+	}
+	starts := usecase.ShareRequest{Kind: usecase.ShareTransferStart}
+	file := download.File
+	if file == nil || file.Checksum == "" {
+		// Streamed archives are always sent whole.
+		return starts
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etagListMatches(inm, `"`+file.Checksum+`"`) {
+			return usecase.ShareRequest{Kind: usecase.ShareProbe}
+		}
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !file.ModTime.IsZero() && !file.ModTime.Truncate(time.Second).After(since) {
+		return usecase.ShareRequest{Kind: usecase.ShareProbe}
+	}
+
+	token, ok := transferToken(r.Header.Get("If-Range"), file.Checksum)
+	if !ok {
+		return starts
+	}
+	start, ok := rangeStart(r.Header.Get("Range"))
+	if !ok {
+		return starts
+	}
+	return usecase.ShareRequest{Kind: usecase.ShareTransferResume, Transfer: token, Start: start}
+}
+
+// shareTransferETag tags a transfer's responses, so that the If-Range of a
+// resume names the transfer it continues.
+func shareTransferETag(transfer *domain.ShareTransfer) string {
+	return `"` + transfer.Checksum + "." + transfer.Token + `"`
+}
+
+// transferToken extracts the transfer token from an If-Range holding a
+// share transfer ETag of the artifact with checksum.
+func transferToken(ifRange, checksum string) (string, bool) {
+	tag, ok := strings.CutPrefix(ifRange, `"`+checksum+".")
+	if !ok {
+		return "", false
+	}
+	token, ok := strings.CutSuffix(tag, `"`)
+	return token, ok && token != ""
+}
+
+// rangeStart returns the first byte of a Range header asking for a single
+// range from a given offset, as in "bytes=100-" or "bytes=100-199".
+func rangeStart(ranges string) (int64, bool) {
+	spec, ok := strings.CutPrefix(ranges, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, false
+	}
+	first, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(strings.TrimSpace(first), 10, 64)
+	if err != nil || start < 0 {
+		return 0, false
+	}
+	return start, true
+}
+
+// etagListMatches applies the weak comparison of If-None-Match.
+func etagListMatches(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || (etag != "" && strings.TrimPrefix(candidate, "W/") == etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveArtifact writes a processed artifact as an attachment.
+func serveArtifact(c *gin.Context, file *domain.StoredFile) {
+	serveStoredFile(c, file, "attachment")
+}
+
+// serveStoredFile writes a stored file with the given disposition. Range,
+// If-Range, If-None-Match and If-Modified-Since are handled by
+// http.ServeContent; the strong ETag and digests come from the stored
+// checksum, so they don't depend on the storage backend. An ETag the caller
+// set already, as share transfers do, is kept.
+func serveStoredFile(c *gin.Context, file *domain.StoredFile, disposition string) {
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, file.Name))
+	w := http.ResponseWriter(c.Writer)
+	if sum, err := hex.DecodeString(file.Checksum); err == nil && len(sum) > 0 {
+		if c.Writer.Header().Get("ETag") == "" {
+			c.Header("ETag", `"`+file.Checksum+`"`)
+		}
+		w = &digestWriter{ResponseWriter: c.Writer, digest: base64.StdEncoding.EncodeToString(sum)}
+	}
+	http.ServeContent(w, c.Request, file.Name, file.ModTime, file)
+}
+
+// digestWriter adds digest fields once the status is known. Repr-Digest and
+// the legacy Digest describe the whole artifact, so they are sent with full
+// and partial responses; Content-Digest describes the bytes in this message
+// and is only sent when that is the whole artifact.
+type digestWriter struct {
+	http.ResponseWriter
+	digest string
+}
+
+func (w *digestWriter) WriteHeader(code int) {
+	switch code {
+	case http.StatusOK:
+		w.Header().Set("Content-Digest", "sha-256=:"+w.digest+":")
+		fallthrough
+	case http.StatusPartialContent:
+		w.Header().Set("Repr-Digest", "sha-256=:"+w.digest+":")
+		w.Header().Set("Digest", "SHA-256="+w.digest)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,263 @@
+// infrastructure/auth_middleware.go
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/usecase"
+)
+
+// APIKeyHeader carries an API key as an alternative to a bearer JWT.
+const APIKeyHeader = "X-API-Key"
+
+// WorkspaceHeader selects the active workspace, overriding the token's
+// workspace_id claim.
+const WorkspaceHeader = "X-Workspace-ID"
+
+// MinHMACKeyLength is the shortest HS256 secret accepted outside dev mode.
+const MinHMACKeyLength = 32
+
+type Claims struct {
+	Username string `json:"username"`
+	UserID   int    `json:"user_id"`
+	// Role defaults to user when absent.
+	Role string `json:"role,omitempty"`
+	// WorkspaceID is the active workspace; zero means the user's personal
+	// space.
+	WorkspaceID int `json:"workspace_id,omitempty"`
+	// SessionID is set on access tokens minted from a refresh token and
+	// names its family.
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// JWTVerifier validates bearer tokens. HS256 tokens are checked against
+// HMACKeys by their kid header; the key stored under "" also accepts tokens
+// without a kid. RS256 and ES256 tokens are checked against JWKS. Several
+// keys can be active at once, so a new key can be rolled out before the old
+// one is retired. Every token must carry exp, and iss and aud must match.
+type JWTVerifier struct {
+	HMACKeys map[string][]byte
+	JWKS     *JWKSKeySet
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew between the issuer and this service.
+	Leeway time.Duration
+}
+
+func NewJWTVerifier(issuer, audience string, hmacKeys map[string][]byte, jwks *JWKSKeySet) (*JWTVerifier, error) {
+	if issuer == "" || audience == "" {
+		return nil, errors.New("jwt issuer and audience are required")
+	}
+	if len(hmacKeys) == 0 && jwks == nil {
+		return nil, errors.New("no jwt verification keys configured")
+	}
+	return &JWTVerifier{
+		HMACKeys: hmacKeys,
+		JWKS:     jwks,
+		Issuer:   issuer,
+		Audience: audience,
+		Leeway:   30 * time.Second,
+	}, nil
+}
+
+func (v *JWTVerifier) validMethods() []string {
+	var methods []string
+	if len(v.HMACKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if v.JWKS != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+	return methods
+}
+
+// Verify parses tokenString and returns its claims when the signature, kid
+// and registered claims are all valid.
+func (v *JWTVerifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		alg := token.Method.Alg()
+		if alg == jwt.SigningMethodHS256.Alg() {
+			key, ok := v.HMACKeys[kid]
+			if !ok {
+				return nil, fmt.Errorf("%w %q", errUnknownKeyID, kid)
+			}
+			return key, nil
+		}
+		return v.JWKS.Key(ctx, kid, alg)
+	},
+		jwt.WithValidMethods(v.validMethods()),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(v.Issuer),
+		jwt.WithAudience(v.Audience),
+		jwt.WithLeeway(v.Leeway),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// ParseHMACKeys reads a JWT_KEYS style list of "kid:secret" pairs separated
+// by commas.
+func ParseHMACKeys(spec string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for i, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, secret, ok := strings.Cut(entry, ":")
+		if !ok || kid == "" || secret == "" {
+			// The entry itself may be a secret, so only its position is reported.
+			return nil, fmt.Errorf("invalid key entry #%d, want kid:secret", i+1)
+		}
+		if _, dup := keys[kid]; dup {
+			return nil, fmt.Errorf("duplicate key id %q", kid)
+		}
+		keys[kid] = []byte(secret)
+	}
+	return keys, nil
+}
+
+// AuthMiddleware accepts either a bearer JWT or, when apiKeys is set, an
+// API key in the X-API-Key header. It stores the caller's user_id, scopes
+// and role in the context for the handlers, RequireScope and RequireRole.
+// API keys always act with the user role.
+func AuthMiddleware(verifier *JWTVerifier, apiKeys *usecase.AuthenticateAPIKeyUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret := c.GetHeader(APIKeyHeader); secret != "" && apiKeys != nil {
+			key, err := apiKeys.Execute(c.Request.Context(), secret)
+			if err != nil {
+				if !errors.Is(err, domain.ErrInvalidAPIKey) {
+					slog.ErrorContext(c.Request.Context(), "api key lookup failed", "error", err)
+				}
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			c.Set("user_id", key.UserID)
+			c.Set("api_key_id", key.ID)
+			c.Set("scopes", key.Scopes)
+			c.Set("role", domain.RoleUser)
+			c.Next()
+			return
+		}
+
+		tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := verifier.Verify(c.Request.Context(), tokenString)
+		if err == nil && claims.UserID <= 0 {
+			err = errors.New("token has no user_id")
+		}
+		var role domain.Role
+		if err == nil {
+			role, err = domain.ParseRole(claims.Role)
+		}
+		if err != nil {
+			slog.DebugContext(c.Request.Context(), "token rejected", "error", err)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Set("user_id", claims.UserID)
+		c.Set("username", claims.Username)
+		c.Set("scopes", domain.UserScopes)
+		c.Set("role", role)
+		if claims.WorkspaceID > 0 {
+			c.Set("workspace_id", claims.WorkspaceID)
+		}
+		if claims.SessionID != "" {
+			c.Set("session_id", claims.SessionID)
+		}
+		c.Next()
+	}
+}
+
+// RequireScope answers 403 unless the authenticated caller holds scope.
+func RequireScope(scope domain.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, _ := c.Get("scopes")
+		if granted, ok := scopes.([]domain.Scope); !ok || !slices.Contains(granted, scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Missing required scope %q", scope)})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole answers 403 unless the authenticated caller's role includes
+// min.
+func RequireRole(min domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		if r, ok := role.(domain.Role); !ok || !r.Includes(min) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Requires the %s role", min)})
+			return
+		}
+		c.Next()
+	}
+}
+
+// WorkspaceMiddleware resolves the active workspace from the X-Workspace-ID
+// header or the token claim and checks that the caller belongs to it. It
+// stores workspace_id and workspace_role in the context; requests without a
+// workspace act on the user's personal videos.
+func WorkspaceMiddleware(resolve *usecase.ResolveWorkspaceUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspaceID := c.GetInt("workspace_id")
+		if raw := c.GetHeader(WorkspaceHeader); raw != "" {
+			id, err := strconv.Atoi(raw)
+			if err != nil || id <= 0 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid " + WorkspaceHeader})
+				return
+			}
+			workspaceID = id
+		}
+		if workspaceID == 0 {
+			c.Next()
+			return
+		}
+
+		member, err := resolve.Execute(c.MustGet("user_id").(int), workspaceID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotWorkspaceMember) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not a member of the selected workspace"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Set("workspace_id", workspaceID)
+		c.Set("workspace_role", member.Role)
+		c.Next()
+	}
+}
+
+// RequireWorkspaceRole answers 403 when a workspace is active and the
+// caller's role in it doesn't include min. Personal requests pass.
+func RequireWorkspaceRole(min domain.WorkspaceRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, ok := c.Get("workspace_role"); ok && !role.(domain.WorkspaceRole).Includes(min) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Requires the %s workspace role", min)})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+// infrastructure/disk_space_other.go
+package infrastructure
+
+import "errors"
+
+func freeDiskSpace(string) (uint64, error) {
+	return 0, errors.ErrUnsupported
+}
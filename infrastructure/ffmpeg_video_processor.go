@@ -0,0 +1,382 @@
+// infrastructure/ffmpeg_video_processor.go
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type FFmpegVideoProcessor struct{}
+
+func NewFFmpegVideoProcessor() *FFmpegVideoProcessor {
+	return &FFmpegVideoProcessor{}
+}
+
+// ExtractFrames keeps ffmpeg's output off the process's stdout, which
+// carries the JSON log; what ffmpeg reports on failure goes into the error,
+// where the logger redacts paths.
+//
+// Rather than the fps filter, which stamps its output with a regular grid,
+// it selects the first source frame of every 1/FPS interval and has
+// showinfo log each one, so the timestamps returned are the frames' own.
+func (p *FFmpegVideoProcessor) ExtractFrames(videoPath, framePattern string, settings domain.ExtractionSettings) ([]time.Duration, error) {
+	fps := settings.OrDefault().FPS
+	filter := fmt.Sprintf("select='isnan(prev_selected_t)+gt(floor(t*%[1]g),floor(prev_selected_t*%[1]g))',showinfo", fps)
+	var stderr bytes.Buffer
+	cmd := exec.Command("ffmpeg", "-hide_banner", "-nostats", "-loglevel", "level+info", "-y", "-i", videoPath, "-vf", filter, "-fps_mode", "vfr", framePattern)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, ffmpegErrors(stderr.String()))
+	}
+	return parseShowinfoTimestamps(stderr.String())
+}
+
+// ffmpegErrors keeps the error lines of a log written with the level flag,
+// leaving out the stream details printed at info level.
+func ffmpegErrors(log string) string {
+	var lines []string
+	for _, line := range strings.Split(log, "\n") {
+		if strings.Contains(line, "[error]") || strings.Contains(line, "[fatal]") {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	return strings.Join(lines, "; ")
+}
+
+var showinfoFrame = regexp.MustCompile(`\bn:\s*(\d+)\s+pts:\s*-?\d+\s+pts_time:(-?[\d.]+)`)
+
+// parseShowinfoTimestamps reads the presentation time of every frame
+// showinfo logged, in output order.
+func parseShowinfoTimestamps(log string) ([]time.Duration, error) {
+	var timestamps []time.Duration
+	for _, line := range strings.Split(log, "\n") {
+		if !strings.Contains(line, "Parsed_showinfo") {
+			continue
+		}
+		m := showinfoFrame.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		seconds, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid showinfo timestamp %q: %w", m[2], err)
+		}
+		if n != len(timestamps) {
+			return nil, fmt.Errorf("showinfo skipped from frame %d to %d", len(timestamps), n)
+		}
+		timestamps = append(timestamps, time.Duration(math.Round(seconds*float64(time.Second))))
+	}
+	return timestamps, nil
+}
+
+// imageCodecs maps frame formats to ffmpeg encoder arguments.
+var imageCodecs = map[domain.ImageFormat][]string{
+	domain.ImageFormatJPEG: {"-c:v", "mjpeg", "-q:v", "3"},
+	domain.ImageFormatPNG:  {"-c:v", "png"},
+	domain.ImageFormatWebP: {"-c:v", "libwebp", "-quality", "80"},
+}
+
+// GrabFrame seeks before opening the input, so ffmpeg jumps to the nearest
+// keyframe instead of decoding the video from the start.
+func (p *FFmpegVideoProcessor) GrabFrame(ctx context.Context, videoPath string, at time.Duration, width int, format domain.ImageFormat) ([]byte, error) {
+	codec, ok := imageCodecs[format]
+	if !ok {
+		return nil, domain.ErrUnsupportedImageType
+	}
+	args := []string{"-hide_banner", "-loglevel", "error", "-ss", strconv.FormatFloat(at.Seconds(), 'f', 3, 64), "-i", videoPath, "-frames:v", "1"}
+	if width > 0 {
+		args = append(args, "-vf", fmt.Sprintf("scale=%d:-2", width))
+	}
+	args = append(args, codec...)
+	args = append(args, "-f", "image2pipe", "pipe:1")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, domain.ErrFrameNotFound
+	}
+	return stdout.Bytes(), nil
+}
+
+// probeDuration asks ffprobe for the length of the container.
+func probeDuration(ctx context.Context, videoPath string) (time.Duration, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", videoPath)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(stdout.String()), 64)
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe returned no duration: %q", stdout.String())
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// RenderPreview opens the video once per segment, seeking before each
+// input, and concatenates the segments. GIFs get a palette generated from
+// the clip itself, which avoids the banding of the default palette.
+func (p *FFmpegVideoProcessor) RenderPreview(ctx context.Context, videoPath string, settings domain.PreviewSettings) ([]byte, error) {
+	settings = settings.OrDefault()
+	duration, err := probeDuration(ctx, videoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	segments, length := settings.Segments, settings.SegmentLength
+	if duration <= time.Duration(segments)*length {
+		segments, length = 1, duration
+	}
+	var args []string
+	var filter strings.Builder
+	for i := 0; i < segments; i++ {
+		start := max(0, duration*time.Duration(2*i+1)/time.Duration(2*segments)-length/2)
+		if segments == 1 {
+			start = 0
+		}
+		args = append(args, "-ss", strconv.FormatFloat(start.Seconds(), 'f', 3, 64), "-t", strconv.FormatFloat(length.Seconds(), 'f', 3, 64), "-i", videoPath)
+		fmt.Fprintf(&filter, "[%d:v]", i)
+	}
+	fmt.Fprintf(&filter, "concat=n=%d:v=1:a=0,fps=%g,scale=%d:-2:flags=lanczos", segments, settings.FPS, settings.Width)
+
+	switch settings.Format {
+	case domain.PreviewFormatGIF:
+		filter.WriteString(",split[a][b];[a]palettegen=stats_mode=diff[p];[b][p]paletteuse=dither=bayer:bayer_scale=5")
+		args = append(args, "-filter_complex", filter.String(), "-loop", "0", "-f", "gif")
+	case domain.PreviewFormatWebP:
+		args = append(args, "-filter_complex", filter.String(), "-c:v", "libwebp", "-loop", "0", "-quality", "70", "-an", "-f", "webp")
+	default:
+		return nil, domain.ErrUnsupportedPreviewFormat
+	}
+	args = append([]string{"-hide_banner", "-loglevel", "error"}, append(args, "pipe:1")...)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// audioCodecs maps audio formats to ffmpeg encoder and muxer arguments.
+var audioCodecs = map[domain.AudioFormat][]string{
+	domain.AudioFormatWAV:  {"-c:a", "pcm_s16le", "-f", "wav"},
+	domain.AudioFormatFLAC: {"-c:a", "flac", "-f", "flac"},
+	domain.AudioFormatMP3:  {"-c:a", "libmp3lame", "-q:a", "2", "-f", "mp3"},
+	domain.AudioFormatOpus: {"-c:a", "libopus", "-b:a", "128k", "-f", "ogg"},
+}
+
+func (p *FFmpegVideoProcessor) HasAudio(ctx context.Context, videoPath string) (bool, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-select_streams", "a", "-show_entries", "stream=index", "-of", "csv=p=0", videoPath)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return false, fmt.Errorf("ffprobe failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()) != "", nil
+}
+
+// runAudio runs ffmpeg over the first audio stream of videoPath, checking
+// for one first so a silent video is told apart from a failed run.
+func (p *FFmpegVideoProcessor) runAudio(ctx context.Context, videoPath string, args ...string) (stdout, stderr []byte, err error) {
+	hasAudio, err := p.HasAudio(ctx, videoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !hasAudio {
+		return nil, nil, domain.ErrNoAudioStream
+	}
+	var out, errOut bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", append([]string{"-hide_banner", "-nostats", "-i", videoPath}, args...)...)
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		return nil, nil, fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(errOut.String()))
+	}
+	return out.Bytes(), errOut.Bytes(), nil
+}
+
+func (p *FFmpegVideoProcessor) ExtractAudio(ctx context.Context, videoPath, outputPath string, format domain.AudioFormat) error {
+	codec, ok := audioCodecs[format]
+	if !ok {
+		return domain.ErrUnsupportedAudioFormat
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return err
+	}
+	args := append([]string{"-loglevel", "error", "-map", "0:a:0", "-vn"}, codec...)
+	_, _, err := p.runAudio(ctx, videoPath, append(args, "-y", outputPath)...)
+	return err
+}
+
+func (p *FFmpegVideoProcessor) RenderWaveform(ctx context.Context, videoPath string, width, height int) ([]byte, error) {
+	filter := fmt.Sprintf("[0:a:0]aformat=channel_layouts=mono,showwavespic=s=%dx%d:colors=0x3b82f6", width, height)
+	stdout, _, err := p.runAudio(ctx, videoPath, "-loglevel", "error", "-filter_complex", filter, "-frames:v", "1", "-c:v", "png", "-f", "image2pipe", "pipe:1")
+	return stdout, err
+}
+
+var (
+	integratedLoudness = regexp.MustCompile(`I:\s+(-?[\d.]+|-inf) LUFS`)
+	loudnessRange      = regexp.MustCompile(`LRA:\s+(-?[\d.]+) LU`)
+	truePeak           = regexp.MustCompile(`Peak:\s+(-?[\d.]+|-inf) dBFS`)
+)
+
+// MeasureLoudness runs the ebur128 filter over the whole track and reads
+// the summary it logs at the end.
+func (p *FFmpegVideoProcessor) MeasureLoudness(ctx context.Context, videoPath string) (domain.Loudness, error) {
+	_, stderr, err := p.runAudio(ctx, videoPath, "-loglevel", "info", "-map", "0:a:0", "-af", "ebur128=peak=true", "-f", "null", "-")
+	if err != nil {
+		return domain.Loudness{}, err
+	}
+	return parseLoudnessSummary(string(stderr))
+}
+
+func parseLoudnessSummary(log string) (domain.Loudness, error) {
+	i := strings.LastIndex(log, "Summary:")
+	if i < 0 {
+		return domain.Loudness{}, fmt.Errorf("ebur128 printed no summary")
+	}
+	summary := log[i:]
+	var values [3]float64
+	for n, re := range []*regexp.Regexp{integratedLoudness, truePeak, loudnessRange} {
+		m := re.FindStringSubmatch(summary)
+		if m == nil {
+			return domain.Loudness{}, fmt.Errorf("ebur128 summary lacks %s", re)
+		}
+		v, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return domain.Loudness{}, err
+		}
+		values[n] = v
+	}
+	loudness := domain.Loudness{IntegratedLUFS: values[0], TruePeakDBTP: values[1], RangeLU: values[2]}
+	if math.IsInf(loudness.TruePeakDBTP, -1) {
+		loudness.TruePeakDBTP = domain.SilentTruePeak
+	}
+	// The meter gates silence out, leaving its floor of -70 LUFS.
+	if math.IsInf(loudness.IntegratedLUFS, -1) {
+		loudness.IntegratedLUFS = -70
+	}
+	return loudness, nil
+}
+
+// ffprobeStreams is the part of ffprobe's JSON output ProbeSubtitles reads.
+type ffprobeStreams struct {
+	Streams []struct {
+		CodecName   string            `json:"codec_name"`
+		Tags        map[string]string `json:"tags"`
+		Disposition map[string]int    `json:"disposition"`
+	} `json:"streams"`
+}
+
+func (p *FFmpegVideoProcessor) ProbeSubtitles(ctx context.Context, videoPath string) ([]domain.SubtitleStream, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-select_streams", "s",
+		"-show_entries", "stream=codec_name:stream_tags=language,title:stream_disposition=default,forced", "-of", "json", videoPath)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var probe ffprobeStreams
+	if err := json.Unmarshal(stdout.Bytes(), &probe); err != nil {
+		return nil, fmt.Errorf("invalid ffprobe output: %w", err)
+	}
+	streams := make([]domain.SubtitleStream, 0, len(probe.Streams))
+	for i, s := range probe.Streams {
+		streams = append(streams, domain.SubtitleStream{
+			Index:    i,
+			Codec:    s.CodecName,
+			Language: s.Tags["language"],
+			Title:    s.Tags["title"],
+			Default:  s.Disposition["default"] == 1,
+			Forced:   s.Disposition["forced"] == 1,
+		})
+	}
+	return streams, nil
+}
+
+// subtitleCodecs maps subtitle formats to the ffmpeg encoder and muxer.
+var subtitleCodecs = map[domain.SubtitleFormat][]string{
+	domain.SubtitleFormatSRT:    {"-c:s", "srt", "-f", "srt"},
+	domain.SubtitleFormatWebVTT: {"-c:s", "webvtt", "-f", "webvtt"},
+}
+
+func (p *FFmpegVideoProcessor) ExtractSubtitles(ctx context.Context, videoPath string, index int, format domain.SubtitleFormat) ([]byte, error) {
+	codec, ok := subtitleCodecs[format]
+	if !ok {
+		return nil, domain.ErrUnsupportedSubtitleFormat
+	}
+	streams, err := p.ProbeSubtitles(ctx, videoPath)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(streams) {
+		return nil, fmt.Errorf("no subtitle stream %d", index)
+	}
+	if !streams[index].IsText() {
+		return nil, fmt.Errorf("%w: %s", domain.ErrBitmapSubtitles, streams[index].Codec)
+	}
+
+	args := append([]string{"-hide_banner", "-loglevel", "error", "-i", videoPath, "-map", fmt.Sprintf("0:s:%d", index)}, codec...)
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", append(args, "pipe:1")...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// TranscodeProxy scales the picture down to fit the bounds, rounding to
+// even sizes for yuv420p, and moves the MP4 index to the front of the file.
+func (p *FFmpegVideoProcessor) TranscodeProxy(ctx context.Context, videoPath, outputPath string, settings domain.ProxySettings) error {
+	settings = settings.OrDefault()
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return err
+	}
+	scale := fmt.Sprintf("scale='min(%d,iw)':'min(%d,ih)':force_original_aspect_ratio=decrease:force_divisible_by=2", settings.MaxWidth, settings.MaxHeight)
+	videoRate := fmt.Sprintf("%dk", settings.VideoKbps)
+	args := []string{
+		"-hide_banner", "-loglevel", "error", "-y", "-i", videoPath,
+		"-map", "0:v:0", "-map", "0:a:0?",
+		"-vf", scale,
+		"-c:v", "libx264", "-preset", "veryfast", "-profile:v", "high", "-pix_fmt", "yuv420p",
+		"-b:v", videoRate, "-maxrate", videoRate, "-bufsize", fmt.Sprintf("%dk", 2*settings.VideoKbps),
+		"-c:a", "aac", "-b:a", fmt.Sprintf("%dk", settings.AudioKbps), "-ac", "2",
+		"-movflags", "+faststart", "-f", "mp4", outputPath,
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
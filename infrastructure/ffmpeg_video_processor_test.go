@@ -0,0 +1,135 @@
+// infrastructure/ffmpeg_video_processor_test.go
+package infrastructure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+func TestParseShowinfoTimestamps(t *testing.T) {
+	log := `[info] Input #0, mov,mp4,m4a,3gp,3g2,mj2, from '/data/uploads/1/clip.mp4':
+[info]   Duration: 00:00:03.04, start: 0.000000, bitrate: 530 kb/s
+[Parsed_showinfo_1 @ 0x55d5c8a0c4c0] [info] config in time_base: 1/15360, frame_rate: 30000/1001
+[Parsed_showinfo_1 @ 0x55d5c8a0c4c0] [info] n:   0 pts:      0 pts_time:0       duration:    512 duration_time:0.0333333 fmt:yuv420p cl:left sar:1/1 s:320x240 i:P iskey:1 type:I checksum:8F5A2C3D plane_checksum:[1B2C3D4E 5F607182 93A4B5C6] mean:[110 128 128] stdev:[51.2 4.1 3.9]
+[Parsed_showinfo_1 @ 0x55d5c8a0c4c0] [info]   color_range:tv color_space:bt709 color_primaries:bt709 color_trc:bt709
+[Parsed_showinfo_1 @ 0x55d5c8a0c4c0] [info] n:   1 pts:  15872 pts_time:1.03333 duration:    512 duration_time:0.0333333 fmt:yuv420p cl:left sar:1/1 s:320x240 i:P iskey:0 type:P checksum:1A2B3C4D plane_checksum:[0A1B2C3D 4E5F6071 8293A4B5] mean:[109 128 128] stdev:[50.8 4.0 3.9]
+[Parsed_showinfo_1 @ 0x55d5c8a0c4c0] [info] n:   2 pts:  31232 pts_time:2.03333 pos:   201344 fmt:yuv420p sar:1/1 s:320x240 i:P iskey:0 type:P checksum:2B3C4D5E plane_checksum:[1A2B3C4D 5E6F7081 92A3B4C5] mean:[108 128 128] stdev:[50.1 4.0 3.8]
+[out#0/image2 @ 0x55d5c8a11d80] [info] video:312KiB audio:0KiB subtitle:0KiB other streams:0KiB global headers:0KiB muxing overhead: unknown
+`
+	got, err := parseShowinfoTimestamps(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Duration{0, 1033330 * time.Microsecond, 2033330 * time.Microsecond}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("timestamps = %v, want %v", got, want)
+	}
+
+	if got, err := parseShowinfoTimestamps("[info] Output #0, image2\n"); err != nil || len(got) != 0 {
+		t.Errorf("no frames = %v, %v", got, err)
+	}
+
+	gap := `[Parsed_showinfo_1 @ 0x1] [info] n:   0 pts:      0 pts_time:0       duration:    512
+[Parsed_showinfo_1 @ 0x1] [info] n:   2 pts:  31232 pts_time:2.03333 duration:    512
+`
+	if _, err := parseShowinfoTimestamps(gap); err == nil {
+		t.Error("a skipped frame number was accepted")
+	}
+}
+
+func TestFFmpegErrorsLeaveOutInfoLines(t *testing.T) {
+	log := `[info] Input #0, mov,mp4,m4a,3gp,3g2,mj2, from '/data/uploads/1/clip.mp4':
+[mov,mp4,m4a,3gp,3g2,mj2 @ 0x5612] [error] moov atom not found
+[in#0 @ 0x5613] [fatal] Error opening input: Invalid data found when processing input
+`
+	want := "[mov,mp4,m4a,3gp,3g2,mj2 @ 0x5612] [error] moov atom not found; [in#0 @ 0x5613] [fatal] Error opening input: Invalid data found when processing input"
+	if got := ffmpegErrors(log); got != want {
+		t.Errorf("ffmpegErrors = %q, want %q", got, want)
+	}
+}
+
+func TestParseLoudnessSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		log     string
+		want    domain.Loudness
+		wantErr string
+	}{
+		{
+			name: "normal track",
+			log: `[Parsed_ebur128_0 @ 0x5581c0d5a6c0] t: 9.9       TARGET:-23 LUFS    M: -21.8 S: -22.6     I: -23.4 LUFS       LRA:   4.9 LU  FTPK: -3.2 dBFS  TPK: -1.1 dBFS
+[Parsed_ebur128_0 @ 0x5581c0d5a6c0] Summary:
+
+  Integrated loudness:
+    I:         -23.4 LUFS
+    Threshold: -33.6 LUFS
+
+  Loudness range:
+    LRA:         5.2 LU
+    Threshold: -43.7 LUFS
+    LRA low:   -26.4 LUFS
+    LRA high:  -21.2 LUFS
+
+  True peak:
+    Peak:       -1.1 dBFS
+`,
+			want: domain.Loudness{IntegratedLUFS: -23.4, TruePeakDBTP: -1.1, RangeLU: 5.2},
+		},
+		{
+			name: "silence",
+			log: `[Parsed_ebur128_0 @ 0x55d1e2f0b2c0] Summary:
+
+  Integrated loudness:
+    I:         -70.0 LUFS
+    Threshold:   0.0 LUFS
+
+  Loudness range:
+    LRA:         0.0 LU
+    Threshold:   0.0 LUFS
+    LRA low:     0.0 LUFS
+    LRA high:    0.0 LUFS
+
+  True peak:
+    Peak:       -inf dBFS
+`,
+			want: domain.Loudness{IntegratedLUFS: -70, TruePeakDBTP: domain.SilentTruePeak, RangeLU: 0},
+		},
+		{
+			name: "no true peak",
+			log: `[Parsed_ebur128_0 @ 0x562b7a1c4a80] Summary:
+
+  Integrated loudness:
+    I:         -16.2 LUFS
+    Threshold: -26.5 LUFS
+
+  Loudness range:
+    LRA:         7.8 LU
+    Threshold: -36.4 LUFS
+    LRA low:   -21.9 LUFS
+    LRA high:  -14.1 LUFS
+`,
+			wantErr: "lacks",
+		},
+		{
+			name:    "no summary",
+			log:     "[in#0 @ 0x5613] [error] Error opening input file\n",
+			wantErr: "no summary",
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseLoudnessSummary(tt.log)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want one mentioning %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: loudness = %+v, %v, want %+v", tt.name, got, err, tt.want)
+		}
+	}
+}
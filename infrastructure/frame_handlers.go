@@ -0,0 +1,214 @@
+// infrastructure/frame_handlers.go
+package infrastructure
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/usecase"
+)
+
+type FrameHandlers struct {
+	ListFramesUC     *usecase.ListFramesUseCase
+	GetFrameUC       *usecase.GetFrameUseCase
+	DownloadFramesUC *usecase.DownloadFramesUseCase
+	GrabFrameUC      *usecase.GrabFrameUseCase
+	ManifestUC       *usecase.GetManifestUseCase
+}
+
+type FrameResponse struct {
+	Index       int    `json:"index"`
+	TimestampMs int64  `json:"timestamp_ms"`
+	Name        string `json:"name"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	SHA256      string `json:"sha256,omitempty"`
+}
+
+func NewFrameHandlers(listUC *usecase.ListFramesUseCase, getUC *usecase.GetFrameUseCase, downloadUC *usecase.DownloadFramesUseCase, grabUC *usecase.GrabFrameUseCase, manifestUC *usecase.GetManifestUseCase) *FrameHandlers {
+	return &FrameHandlers{ListFramesUC: listUC, GetFrameUC: getUC, DownloadFramesUC: downloadUC, GrabFrameUC: grabUC, ManifestUC: manifestUC}
+}
+
+func (h *FrameHandlers) ListFramesHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+
+	frames, err := h.ListFramesUC.Execute(c.Request.Context(), userID, videoID)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	response := make([]FrameResponse, 0, len(frames))
+	for _, f := range frames {
+		response = append(response, FrameResponse{
+			Index:       f.Index,
+			TimestampMs: f.Timestamp.Milliseconds(),
+			Name:        f.Name,
+			SizeBytes:   f.Size,
+			Width:       f.Width,
+			Height:      f.Height,
+			SHA256:      f.Checksum,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ManifestHandler serves the manifest packed with the artifact as
+// manifest.json.
+func (h *FrameHandlers) ManifestHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+
+	manifest, err := h.ManifestUC.Execute(c.Request.Context(), userID, videoID)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, manifest)
+}
+
+// GetFrameHandler serves one frame by index (/frames/:index) or the frame
+// nearest to ?t=, in seconds (/frames/at).
+func (h *FrameHandlers) GetFrameHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+	var query usecase.FrameQuery
+	if c.Param("index") == "" {
+		seconds, err := strconv.ParseFloat(c.Query("t"), 64)
+		if err != nil || seconds < 0 || math.IsInf(seconds, 0) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "t must be a non-negative number of seconds"})
+			return
+		}
+		query.At = time.Duration(seconds * float64(time.Second))
+	} else if query.Index, err = strconv.Atoi(c.Param("index")); err != nil || query.Index < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Frame index must be a positive integer"})
+		return
+	}
+
+	frame, file, err := h.GetFrameUC.Execute(c.Request.Context(), userID, videoID, query)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", frame.Name))
+	c.Header("X-Frame-Index", strconv.Itoa(frame.Index))
+	c.Header("X-Frame-Timestamp-Ms", strconv.FormatInt(frame.Timestamp.Milliseconds(), 10))
+	http.ServeContent(c.Writer, c.Request, frame.Name, file.ModTime, file)
+}
+
+// DownloadFramesHandler packs a subset of frames, picked with ?from=&to=
+// (to is optional) or ?indices=1,5,9, in ?format=.
+func (h *FrameHandlers) DownloadFramesHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+	format, err := domain.ParseArchiveFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be zip, tar or tar.gz"})
+		return
+	}
+	selection, err := parseFrameSelection(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	download, err := h.DownloadFramesUC.Execute(c.Request.Context(), userID, videoID, selection, format)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	defer download.Close()
+
+	serveDownload(c, download)
+}
+
+// GrabFrameHandler extracts the frame at ?t=, in seconds, from the stored
+// upload, optionally scaled to ?w= pixels wide and encoded as ?format=
+// (jpeg, png or webp).
+func (h *FrameHandlers) GrabFrameHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+	var query usecase.GrabFrameQuery
+	seconds, err := strconv.ParseFloat(c.Query("t"), 64)
+	if err != nil || seconds < 0 || math.IsInf(seconds, 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "t must be a non-negative number of seconds"})
+		return
+	}
+	query.At = time.Duration(seconds * float64(time.Second))
+	if value := c.Query("w"); value != "" {
+		if query.Width, err = strconv.Atoi(value); err != nil || query.Width < 1 || query.Width > usecase.MaxGrabWidth {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("w must be an integer between 1 and %d", usecase.MaxGrabWidth)})
+			return
+		}
+	}
+	if query.Format, err = domain.ParseImageFormat(c.Query("format")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be jpeg, png or webp"})
+		return
+	}
+
+	frame, err := h.GrabFrameUC.Execute(c.Request.Context(), userID, videoID, query)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	cache := "miss"
+	if frame.Cached {
+		cache = "hit"
+	}
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("X-Frame-Timestamp-Ms", strconv.FormatInt(frame.At.Milliseconds(), 10))
+	c.Header("X-Frame-Cache", cache)
+	c.Data(http.StatusOK, frame.Format.ContentType(), frame.Data)
+}
+
+func parseFrameSelection(c *gin.Context) (domain.FrameSelection, error) {
+	var selection domain.FrameSelection
+	if list := c.Query("indices"); list != "" {
+		for _, part := range strings.Split(list, ",") {
+			index, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || index < 1 {
+				return selection, fmt.Errorf("indices must be a comma-separated list of positive integers")
+			}
+			selection.Indices = append(selection.Indices, index)
+		}
+	}
+	for name, dst := range map[string]*int{"from": &selection.From, "to": &selection.To} {
+		if value := c.Query(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return selection, fmt.Errorf("%s must be an integer", name)
+			}
+			*dst = n
+		}
+	}
+	return selection, nil
+}
@@ -2,52 +2,256 @@
 package infrastructure
 
 import (
-    "your_project/usecase" 
-    "github.com/gin-gonic/gin"
-    "net/http"
-    "strconv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/usecase"
 )
 
 type VideoHandlers struct {
-    UploadVideoUC    *usecase.UploadVideoUseCase
-    ListVideoStatusUC *usecase.ListVideoStatusUseCase 
-    DownloadVideoUC  *usecase.DownloadVideoUseCase  
+	UploadVideoUC     *usecase.UploadVideoUseCase
+	ListVideoStatusUC *usecase.ListVideoStatusUseCase
+	DownloadVideoUC   *usecase.DownloadVideoUseCase
+	ListVideoEventsUC *usecase.ListVideoEventsUseCase
+	ReprocessVideoUC  *usecase.ReprocessVideoUseCase
+}
+
+type VideoStatusResponse struct {
+	ID                int    `json:"id"`
+	WorkspaceID       int    `json:"workspace_id,omitempty"`
+	OriginalFilename  string `json:"original_filename"`
+	Status            string `json:"status"`
+	ProcessedFilePath string `json:"processed_file_path,omitempty"`
+	// ProcessedFileChecksum is the hex SHA-256 of the artifact, also sent
+	// as the download's ETag.
+	ProcessedFileChecksum string `json:"processed_file_checksum,omitempty"`
+	ErrorMessage          string `json:"error_message,omitempty"`
+	ContentHash           string `json:"content_hash,omitempty"`
+	ReusedFromVideoID     int    `json:"reused_from_video_id,omitempty"`
+	// Audio is present once a job with audio outputs analysed the video.
+	Audio           *domain.AudioAnalysis   `json:"audio,omitempty"`
+	SubtitleStreams []domain.SubtitleStream `json:"subtitle_streams,omitempty"`
+	CreatedAt       time.Time               `json:"created_at"`
+	UpdatedAt       time.Time               `json:"updated_at"`
+}
+
+type VideoEventResponse struct {
+	ID           int       `json:"id"`
+	FromStatus   string    `json:"from_status,omitempty"`
+	ToStatus     string    `json:"to_status"`
+	WorkerID     string    `json:"worker_id,omitempty"`
+	Attempt      int       `json:"attempt"`
+	DurationMs   int64     `json:"duration_ms"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func NewVideoHandlers(uploadUC *usecase.UploadVideoUseCase, listUC *usecase.ListVideoStatusUseCase, downloadUC *usecase.DownloadVideoUseCase, eventsUC *usecase.ListVideoEventsUseCase, reprocessUC *usecase.ReprocessVideoUseCase) *VideoHandlers {
+	return &VideoHandlers{
+		UploadVideoUC:     uploadUC,
+		ListVideoStatusUC: listUC,
+		DownloadVideoUC:   downloadUC,
+		ListVideoEventsUC: eventsUC,
+		ReprocessVideoUC:  reprocessUC,
+	}
 }
 
-func NewVideoHandlers(uploadUC *usecase.UploadVideoUseCase, listUC *usecase.ListVideoStatusUseCase, downloadUC *usecase.DownloadVideoUseCase) *VideoHandlers {
-    return &VideoHandlers{
-        UploadVideoUC:    uploadUC,
-        ListVideoStatusUC: listUC,
-        DownloadVideoUC:  downloadUC,
-    }
+func newVideoStatusResponse(v domain.Video) VideoStatusResponse {
+	return VideoStatusResponse{
+		ID:                    v.ID,
+		WorkspaceID:           v.WorkspaceID,
+		OriginalFilename:      v.OriginalFilename,
+		Status:                string(v.Status),
+		ProcessedFilePath:     v.ProcessedFilePath,
+		ProcessedFileChecksum: v.ProcessedFileChecksum,
+		ErrorMessage:          v.ErrorMessage,
+		ContentHash:           v.ContentHash,
+		ReusedFromVideoID:     v.ReusedFromVideoID,
+		Audio:                 v.Audio,
+		SubtitleStreams:       v.SubtitleStreams,
+		CreatedAt:             v.CreatedAt,
+		UpdatedAt:             v.UpdatedAt,
+	}
 }
 
 func (h *VideoHandlers) UploadVideoHandler(c *gin.Context) {
-    userID := c.MustGet("user_id").(int)
-    fileHeader, err := c.FormFile("video")
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to get video file: %v", err)})
-        return
-    }
-
-    file, err := fileHeader.Open()
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to open uploaded file: %v", err)})
-        return
-    }
-    defer file.Close()
-
-    input := usecase.UploadVideoInput{
-        UserID:          userID,
-        FileContent:     file,
-        OriginalFilename: fileHeader.Filename,
-    }
-
-    output, err := h.UploadVideoUC.Execute(input)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-        return
-    }
-    c.JSON(http.StatusOK, gin.H{"message": output.Message, "filename": output.Filename, "video_status_id": output.VideoStatusID})
+	userID := c.MustGet("user_id").(int)
+	fileHeader, err := c.FormFile("video")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to get video file: %v", err)})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to open uploaded file: %v", err)})
+		return
+	}
+	defer file.Close()
+
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if len(idempotencyKey) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+		return
+	}
+
+	input := usecase.UploadVideoInput{
+		UserID:           userID,
+		WorkspaceID:      c.GetInt("workspace_id"),
+		FileContent:      file,
+		OriginalFilename: fileHeader.Filename,
+		IdempotencyKey:   idempotencyKey,
+	}
+
+	output, err := h.UploadVideoUC.Execute(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrIdempotencyKeyReused):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrIdempotencyKeyInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrQuotaExceeded):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrUploadTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if output.Replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":         output.Message,
+		"filename":        output.Filename,
+		"video_status_id": output.VideoStatusID,
+		"content_hash":    output.ContentHash,
+		"deduplicated":    output.Deduplicated,
+	})
+}
+
+func (h *VideoHandlers) ListVideoStatusHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	videos, err := h.ListVideoStatusUC.Execute(userID, c.GetInt("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statuses := make([]VideoStatusResponse, 0, len(videos))
+	for _, v := range videos {
+		statuses = append(statuses, newVideoStatusResponse(v))
+	}
+	c.JSON(http.StatusOK, statuses)
+}
+
+func (h *VideoHandlers) DownloadVideoHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+
+	format, err := domain.ParseArchiveFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be zip, tar or tar.gz"})
+		return
+	}
+
+	download, err := h.DownloadVideoUC.Execute(c.Request.Context(), userID, videoID, format)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	defer download.Close()
+
+	serveDownload(c, download)
+}
+
+func (h *VideoHandlers) ListVideoEventsHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+
+	events, err := h.ListVideoEventsUC.Execute(userID, videoID)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+
+	response := make([]VideoEventResponse, 0, len(events))
+	for _, e := range events {
+		response = append(response, VideoEventResponse{
+			ID:           e.ID,
+			FromStatus:   string(e.FromStatus),
+			ToStatus:     string(e.ToStatus),
+			WorkerID:     e.WorkerID,
+			Attempt:      e.Attempt,
+			DurationMs:   e.Duration.Milliseconds(),
+			ErrorMessage: e.ErrorMessage,
+			CreatedAt:    e.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, response)
 }
 
+func (h *VideoHandlers) ReprocessVideoHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+
+	video, err := h.ReprocessVideoUC.Execute(c.Request.Context(), userID, videoID)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, newVideoStatusResponse(*video))
+}
+
+// respondVideoError maps domain errors from the per-video use cases to HTTP
+// responses.
+func respondVideoError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrVideoNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Video not found"})
+	case errors.Is(err, domain.ErrVideoNotCompleted):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Processed video not found or not completed"})
+	case errors.Is(err, domain.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: Video does not belong to this user"})
+	case errors.Is(err, domain.ErrInsufficientWorkspaceRole):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: Workspace role does not allow this action"})
+	case errors.Is(err, domain.ErrProcessedFileEmpty):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Processed file path not found or invalid"})
+	case errors.Is(err, domain.ErrFileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Processed file not found on server storage"})
+	case errors.Is(err, domain.ErrFrameNotFound), errors.Is(err, domain.ErrOutputNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidFrameSelection):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidTransition), errors.Is(err, domain.ErrVideoProcessing), errors.Is(err, domain.ErrSourceUnavailable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrTooManyFrameRequests):
+		c.Header("Retry-After", "1")
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrFrameTimeout):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
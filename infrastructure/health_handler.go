@@ -0,0 +1,224 @@
+// infrastructure/health_handler.go
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitovidale/video-processor-service/logging"
+)
+
+const (
+	HealthStatusUp       = "UP"
+	HealthStatusDegraded = "DEGRADED"
+	HealthStatusDown     = "DOWN"
+
+	DefaultHealthCacheTTL     = 5 * time.Second
+	DefaultHealthCheckTimeout = 2 * time.Second
+)
+
+// HealthCheck is one dependency probe. Critical checks decide readiness: when
+// one fails the instance should stop receiving traffic. Non-critical failures
+// only mark /health as degraded.
+type HealthCheck struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+type HealthCheckResult struct {
+	Status     string `json:"status"`
+	Critical   bool   `json:"critical"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type HealthReport struct {
+	Status    string                       `json:"status"`
+	Checks    map[string]HealthCheckResult `json:"checks"`
+	CheckedAt time.Time                    `json:"checked_at"`
+}
+
+// ready reports whether every critical check passed.
+func (r HealthReport) ready() bool {
+	return r.Status != HealthStatusDown
+}
+
+// HealthChecker runs its checks concurrently and caches the report for TTL,
+// so frequent probes from several sources don't hammer the dependencies.
+type HealthChecker struct {
+	Checks  []HealthCheck
+	TTL     time.Duration
+	Timeout time.Duration
+
+	mu     sync.Mutex
+	report *HealthReport
+}
+
+func NewHealthChecker(ttl time.Duration, checks ...HealthCheck) *HealthChecker {
+	return &HealthChecker{Checks: checks, TTL: ttl, Timeout: DefaultHealthCheckTimeout}
+}
+
+// Report returns the cached report, refreshing it once it is older than TTL.
+// Concurrent callers wait for a single refresh.
+func (h *HealthChecker) Report(ctx context.Context) HealthReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.report != nil && time.Since(h.report.CheckedAt) < h.TTL {
+		return *h.report
+	}
+	// Detached from the probe's request so one impatient caller can't cache
+	// a spurious failure for everyone else.
+	report := h.run(context.WithoutCancel(ctx))
+	h.report = &report
+	return report
+}
+
+func (h *HealthChecker) run(ctx context.Context) HealthReport {
+	results := make([]HealthCheckResult, len(h.Checks))
+	var wg sync.WaitGroup
+	for i, check := range h.Checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, h.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Check(checkCtx)
+			results[i] = HealthCheckResult{
+				Status:     HealthStatusUp,
+				Critical:   check.Critical,
+				DurationMs: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				results[i].Status = HealthStatusDown
+				// The report is served unauthenticated, so filesystem checks
+				// must not reveal where the data directories live.
+				results[i].Error = logging.ScrubPaths(err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := HealthReport{
+		Status:    HealthStatusUp,
+		Checks:    make(map[string]HealthCheckResult, len(results)),
+		CheckedAt: time.Now(),
+	}
+	for i, result := range results {
+		report.Checks[h.Checks[i].Name] = result
+		if result.Status == HealthStatusUp {
+			continue
+		}
+		if result.Critical {
+			report.Status = HealthStatusDown
+		} else if report.Status == HealthStatusUp {
+			report.Status = HealthStatusDegraded
+		}
+	}
+	return report
+}
+
+// LivezHandler only proves the process is serving HTTP. It never touches
+// dependencies, so an outage elsewhere doesn't get the pod restarted.
+func (h *HealthChecker) LivezHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": HealthStatusUp})
+}
+
+// ReadyzHandler answers 503 while any critical dependency is down.
+func (h *HealthChecker) ReadyzHandler(c *gin.Context) {
+	report := h.Report(c.Request.Context())
+	failing := []string{}
+	for name, result := range report.Checks {
+		if result.Critical && result.Status != HealthStatusUp {
+			failing = append(failing, name)
+		}
+	}
+	if !report.ready() {
+		slices.Sort(failing)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": HealthStatusDown, "failing": failing})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": HealthStatusUp})
+}
+
+// HealthHandler returns the full report. Degraded instances still answer 200.
+func (h *HealthChecker) HealthHandler(c *gin.Context) {
+	report := h.Report(c.Request.Context())
+	status := http.StatusOK
+	if !report.ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+func DatabaseHealthCheck(db *sql.DB) HealthCheck {
+	return HealthCheck{Name: "database", Critical: true, Check: db.PingContext}
+}
+
+func MessageQueueHealthCheck(messageQueue *RabbitMQMessageQueue) HealthCheck {
+	return HealthCheck{Name: "rabbitmq", Critical: true, Check: func(context.Context) error {
+		return messageQueue.Ping()
+	}}
+}
+
+// ConsumerHealthCheck reports whether the worker is currently subscribed to
+// the processing queue. A detached consumer doesn't stop the API from
+// accepting uploads, so it only degrades the instance.
+func ConsumerHealthCheck(consumer interface{ Consuming() bool }) HealthCheck {
+	return HealthCheck{Name: "consumer", Check: func(context.Context) error {
+		if !consumer.Consuming() {
+			return errors.New("not attached to the processing queue")
+		}
+		return nil
+	}}
+}
+
+// ExecutableHealthCheck verifies that name can be found on PATH.
+func ExecutableHealthCheck(name string) HealthCheck {
+	return HealthCheck{Name: name, Check: func(context.Context) error {
+		_, err := exec.LookPath(name)
+		return err
+	}}
+}
+
+// WritableDirHealthCheck creates and removes a temporary file in dir.
+func WritableDirHealthCheck(name, dir string) HealthCheck {
+	return HealthCheck{Name: name, Critical: true, Check: func(context.Context) error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		f, err := os.CreateTemp(dir, ".healthcheck-*")
+		if err != nil {
+			return err
+		}
+		f.Close()
+		return os.Remove(f.Name())
+	}}
+}
+
+// FreeDiskHealthCheck fails when the filesystem holding dir has fewer than
+// minFree bytes available.
+func FreeDiskHealthCheck(dir string, minFree uint64) HealthCheck {
+	return HealthCheck{Name: "disk_space", Critical: true, Check: func(context.Context) error {
+		free, err := freeDiskSpace(dir)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("%d MiB free, want at least %d MiB", free>>20, minFree>>20)
+		}
+		return nil
+	}}
+}
@@ -0,0 +1,233 @@
+// infrastructure/jwks.go
+package infrastructure
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultJWKSRefreshInterval    = 10 * time.Minute
+	DefaultJWKSMinRefreshInterval = 30 * time.Second
+)
+
+var errUnknownKeyID = errors.New("unknown key id")
+
+// jwk is the subset of RFC 7517 fields needed for RSA and P-256 keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type publicKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// JWKSKeySet fetches public keys from a JWKS endpoint and caches them. Keys
+// are refreshed every RefreshInterval, and early when a token names a kid
+// that isn't cached yet, which is how a rotated key gets picked up.
+//
+// Lookups only take a read lock. Fetches run outside the lock, one at a
+// time: callers that need keys while one is in flight wait for its result
+// instead of starting another.
+type JWKSKeySet struct {
+	URL             string
+	Client          *http.Client
+	RefreshInterval time.Duration
+	// MinRefreshInterval bounds how often an unknown kid can force a
+	// refetch, so garbage tokens can't be used to flood the key server.
+	MinRefreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]publicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	inFlight    *jwksFetch
+}
+
+// jwksFetch is a fetch in progress; err is set before done is closed.
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+func NewJWKSKeySet(url string) *JWKSKeySet {
+	return &JWKSKeySet{
+		URL:                url,
+		Client:             &http.Client{Timeout: 5 * time.Second},
+		RefreshInterval:    DefaultJWKSRefreshInterval,
+		MinRefreshInterval: DefaultJWKSMinRefreshInterval,
+	}
+}
+
+// Key returns the public key registered under kid for alg.
+func (s *JWKSKeySet) Key(ctx context.Context, kid, alg string) (crypto.PublicKey, error) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	stale := time.Since(s.fetchedAt) > s.RefreshInterval
+	s.mu.RUnlock()
+
+	if stale || !ok {
+		if err := s.refresh(ctx, false); err != nil {
+			// Keep serving the keys we have; the issuer may be briefly down.
+			slog.WarnContext(ctx, "jwks refresh failed", "error", err)
+		}
+		s.mu.RLock()
+		key, ok = s.keys[kid]
+		s.mu.RUnlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errUnknownKeyID, kid)
+	}
+	if key.alg != alg {
+		return nil, fmt.Errorf("key %q is for %s, token uses %s", kid, key.alg, alg)
+	}
+	return key.key, nil
+}
+
+// Refresh fetches the key set now, or waits for a fetch already running.
+func (s *JWKSKeySet) Refresh(ctx context.Context) error {
+	return s.refresh(ctx, true)
+}
+
+// refresh joins the fetch in flight or starts one. Unless force is set, it
+// does nothing when the last attempt is more recent than
+// MinRefreshInterval. The fetch outlives the caller's cancellation, since
+// other callers may be waiting for it; Client's timeout bounds it.
+func (s *JWKSKeySet) refresh(ctx context.Context, force bool) error {
+	s.mu.Lock()
+	if fetch := s.inFlight; fetch != nil {
+		s.mu.Unlock()
+		select {
+		case <-fetch.done:
+			return fetch.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if !force && time.Since(s.lastAttempt) < s.MinRefreshInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	fetch := &jwksFetch{done: make(chan struct{})}
+	s.inFlight = fetch
+	s.lastAttempt = time.Now()
+	s.mu.Unlock()
+
+	keys, err := s.fetch(context.WithoutCancel(ctx))
+
+	s.mu.Lock()
+	if err == nil {
+		s.keys = keys
+		s.fetchedAt = time.Now()
+	}
+	s.inFlight = nil
+	s.mu.Unlock()
+	fetch.err = err
+	close(fetch.done)
+	return err
+}
+
+func (s *JWKSKeySet) fetch(ctx context.Context) (map[string]publicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks endpoint returned %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]publicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kid == "" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			slog.WarnContext(ctx, "skipping unusable jwk", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (publicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		if k.Alg != "" && k.Alg != "RS256" {
+			return publicKey{}, fmt.Errorf("unsupported alg %q", k.Alg)
+		}
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return publicKey{}, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return publicKey{}, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return publicKey{}, errors.New("invalid RSA exponent")
+		}
+		return publicKey{alg: "RS256", key: &rsa.PublicKey{N: n, E: int(e.Int64())}}, nil
+	case "EC":
+		if k.Crv != "P-256" || (k.Alg != "" && k.Alg != "ES256") {
+			return publicKey{}, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return publicKey{}, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return publicKey{}, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return publicKey{}, errors.New("point is not on P-256")
+		}
+		return publicKey{alg: "ES256", key: key}, nil
+	default:
+		return publicKey{}, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
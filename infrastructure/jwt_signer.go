@@ -0,0 +1,66 @@
+// infrastructure/jwt_signer.go
+package infrastructure
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// DefaultAccessTokenTTL is how long access tokens minted by JWTSigner last
+// when no TTL is given.
+const DefaultAccessTokenTTL = 15 * time.Minute
+
+// JWTSigner mints HS256 access tokens with one of a JWTVerifier's HMAC
+// keys, so they pass the same checks as those of the identity provider.
+type JWTSigner struct {
+	KeyID    string
+	Key      []byte
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+}
+
+// NewJWTSigner signs with the verifier's key keyID; "" is the key used for
+// tokens without a kid.
+func NewJWTSigner(verifier *JWTVerifier, keyID string, ttl time.Duration) (*JWTSigner, error) {
+	key, ok := verifier.HMACKeys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("no HMAC key %q to sign access tokens with", keyID)
+	}
+	if ttl <= 0 {
+		ttl = DefaultAccessTokenTTL
+	}
+	return &JWTSigner{KeyID: keyID, Key: key, Issuer: verifier.Issuer, Audience: verifier.Audience, TTL: ttl}, nil
+}
+
+func (s *JWTSigner) SignAccessToken(subject domain.TokenSubject, sessionID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.TTL)
+	claims := Claims{
+		Username:    subject.Username,
+		UserID:      subject.UserID,
+		Role:        string(subject.Role),
+		WorkspaceID: subject.WorkspaceID,
+		SessionID:   sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.Issuer,
+			Subject:   strconv.Itoa(subject.UserID),
+			Audience:  jwt.ClaimStrings{s.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if s.KeyID != "" {
+		token.Header["kid"] = s.KeyID
+	}
+	signed, err := token.SignedString(s.Key)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
@@ -0,0 +1,215 @@
+// infrastructure/local_file_storage.go
+package infrastructure
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type LocalFileStorage struct {
+	UploadDir    string
+	ProcessedDir string
+}
+
+func NewLocalFileStorage(uploadDir, processedDir string) *LocalFileStorage {
+	return &LocalFileStorage{UploadDir: uploadDir, ProcessedDir: processedDir}
+}
+
+func (s *LocalFileStorage) SaveUploadedFile(src io.Reader, filename string) (string, error) {
+	if err := os.MkdirAll(s.UploadDir, 0755); err != nil {
+		return "", err
+	}
+	filePath := filepath.Join(s.UploadDir, filename)
+	out, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		os.Remove(filePath)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+	return filePath, nil
+}
+
+func (s *LocalFileStorage) SaveFile(filePath string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+func (s *LocalFileStorage) OutputDir(userID, videoID, claim int) (string, error) {
+	outputDir := filepath.Join(s.ProcessedDir, strconv.Itoa(userID), strconv.Itoa(videoID), strconv.Itoa(claim))
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", err
+	}
+	return outputDir, nil
+}
+
+func (s *LocalFileStorage) DeleteOutputDir(outputDir string) error {
+	return os.RemoveAll(outputDir)
+}
+
+func (s *LocalFileStorage) GenerateProcessedFileName(userID int, originalFilename string) string {
+	return fmt.Sprintf("%d_%s_processed.zip", userID, filepath.Base(originalFilename))
+}
+
+func (s *LocalFileStorage) GenerateFramePattern(outputDir, originalFilename string) string {
+	return filepath.Join(outputDir, fmt.Sprintf("%s_%%04d.png", filepath.Base(originalFilename)))
+}
+
+func (s *LocalFileStorage) GetProcessedFilePath(outputDir string, userID int, originalFilename string) string {
+	return filepath.Join(outputDir, s.GenerateProcessedFileName(userID, originalFilename))
+}
+
+func (s *LocalFileStorage) GetManifestPath(outputDir string) string {
+	return filepath.Join(outputDir, domain.ManifestName)
+}
+
+func (s *LocalFileStorage) GetOutputPath(outputDir, name string) string {
+	return filepath.Join(outputDir, "outputs", filepath.Base(name))
+}
+
+func (s *LocalFileStorage) DeleteFile(filePath string) error {
+	err := os.Remove(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalFileStorage) listFrames(outputDir, originalFilename string) ([]string, error) {
+	return filepath.Glob(filepath.Join(outputDir, fmt.Sprintf("%s_*.png", filepath.Base(originalFilename))))
+}
+
+func (s *LocalFileStorage) ListFrames(outputDir string) ([]string, error) {
+	return filepath.Glob(filepath.Join(outputDir, "*.png"))
+}
+
+func (s *LocalFileStorage) DeleteFrames(outputDir, originalFilename string) error {
+	frames, err := s.listFrames(outputDir, originalFilename)
+	if err != nil {
+		return err
+	}
+	for _, framePath := range frames {
+		if err := s.DeleteFile(framePath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LocalFileStorage) ZipFrames(outputDir, originalFilename, zipFilePath string) (int, error) {
+	frames, err := s.listFrames(outputDir, originalFilename)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list frames for zipping: %w", err)
+	}
+	if len(frames) == 0 {
+		return 0, errors.New("no frames extracted to zip")
+	}
+
+	newZipFile, err := os.Create(zipFilePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create zip file: %w", err)
+	}
+	zipWriter := zip.NewWriter(newZipFile)
+	// A ZIP missing a frame must not become the artifact.
+	abort := func(err error) (int, error) {
+		zipWriter.Close()
+		newZipFile.Close()
+		os.Remove(zipFilePath)
+		return 0, err
+	}
+
+	manifestPath := s.GetManifestPath(outputDir)
+	if _, err := os.Stat(manifestPath); err == nil {
+		if err := addFileToZip(zipWriter, manifestPath); err != nil {
+			return abort(fmt.Errorf("failed to add manifest to zip: %w", err))
+		}
+	}
+
+	for _, framePath := range frames {
+		if err := addFileToZip(zipWriter, framePath); err != nil {
+			return abort(fmt.Errorf("failed to add %s to zip: %w", filepath.Base(framePath), err))
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return abort(fmt.Errorf("failed to close zip writer: %w", err))
+	}
+	if err := newZipFile.Close(); err != nil {
+		os.Remove(zipFilePath)
+		return 0, fmt.Errorf("failed to close zip file: %w", err)
+	}
+	return len(frames), nil
+}
+
+// zipMethod stores PNG and JPEG frames as they are; deflating them again
+// costs CPU and saves next to nothing.
+func zipMethod(name string) uint16 {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png", ".jpg", ".jpeg":
+		return zip.Store
+	}
+	return zip.Deflate
+}
+
+func addFileToZip(zipWriter *zip.Writer, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(fileInfo)
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.Base(filePath)
+	header.Method = zipMethod(header.Name)
+
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, file)
+	return err
+}
+
+func (s *LocalFileStorage) OpenFile(filePath string) (*domain.StoredFile, error) {
+	file, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, domain.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &domain.StoredFile{
+		ReadSeekCloser: file,
+		Name:           filepath.Base(filePath),
+		Size:           info.Size(),
+		ModTime:        info.ModTime(),
+	}, nil
+}
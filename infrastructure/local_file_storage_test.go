@@ -0,0 +1,28 @@
+// infrastructure/local_file_storage_test.go
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestZipFramesFailsOnAFrameItCannotRead(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewLocalFileStorage(dir, dir)
+	if err := os.WriteFile(filepath.Join(dir, "clip.mp4_0001.png"), []byte("frame"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Opens and stats like a frame but cannot be read.
+	if err := os.Mkdir(filepath.Join(dir, "clip.mp4_0002.png"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	zipPath := filepath.Join(dir, "clip.zip")
+	if n, err := storage.ZipFrames(dir, "clip.mp4", zipPath); err == nil {
+		t.Fatalf("zipped %d frames, want an error", n)
+	}
+	if _, err := os.Stat(zipPath); !os.IsNotExist(err) {
+		t.Errorf("partial zip was left behind: %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+// infrastructure/log_notification_service.go
+package infrastructure
+
+import (
+	"context"
+	"log/slog"
+)
+
+type LogNotificationService struct{}
+
+func NewLogNotificationService() *LogNotificationService {
+	return &LogNotificationService{}
+}
+
+// SendNotification logs with ctx so the entry carries the job's video_id,
+// attempt and trace fields like the rest of the worker's log.
+func (n *LogNotificationService) SendNotification(ctx context.Context, userID int, originalFilename, status, message string) {
+	slog.InfoContext(ctx, "notification sent", "user_id", userID, "status", status, "notification", message)
+}
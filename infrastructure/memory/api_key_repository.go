@@ -0,0 +1,85 @@
+// infrastructure/memory/api_key_repository.go
+package memory
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// APIKeyRepository is an in-memory domain.APIKeyRepository.
+type APIKeyRepository struct {
+	mu   sync.Mutex
+	keys []domain.APIKey
+}
+
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{}
+}
+
+func (r *APIKeyRepository) Create(key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key.ID = len(r.keys) + 1
+	key.CreatedAt = time.Now()
+	stored := *key
+	stored.Scopes = slices.Clone(key.Scopes)
+	r.keys = append(r.keys, stored)
+	return nil
+}
+
+func (r *APIKeyRepository) FindByHash(keyHash string) (*domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, k := range r.keys {
+		if k.KeyHash == keyHash {
+			return &k, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (r *APIKeyRepository) FindByUserID(userID int) ([]domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var keys []domain.APIKey
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if r.keys[i].UserID == userID {
+			keys = append(keys, r.keys[i])
+		}
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Revoke(userID, keyID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.keys {
+		if r.keys[i].ID == keyID && r.keys[i].UserID == userID {
+			if r.keys[i].RevokedAt == nil {
+				now := time.Now()
+				r.keys[i].RevokedAt = &now
+			}
+			return nil
+		}
+	}
+	return domain.ErrAPIKeyNotFound
+}
+
+func (r *APIKeyRepository) TouchLastUsed(keyID int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.keys {
+		if r.keys[i].ID == keyID {
+			r.keys[i].LastUsedAt = &at
+		}
+	}
+	return nil
+}
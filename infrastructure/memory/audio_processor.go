@@ -0,0 +1,94 @@
+// infrastructure/memory/audio_processor.go
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// audioHeaders start the stub tracks ExtractAudio writes, so each format is
+// recognizable by its magic bytes.
+var audioHeaders = map[domain.AudioFormat]string{
+	domain.AudioFormatWAV:  "RIFF\x00\x00\x00\x00WAVE",
+	domain.AudioFormatFLAC: "fLaC",
+	domain.AudioFormatMP3:  "ID3\x04\x00",
+	domain.AudioFormatOpus: "OggS\x00",
+}
+
+// AudioProcessor is a fake domain.AudioProcessor. Every video has the same
+// loudness unless NoAudio is set; waveforms are real PNGs of a flat line.
+type AudioProcessor struct {
+	Storage  *FileStorage
+	NoAudio  bool
+	Loudness domain.Loudness
+	// Err, when set, is returned from MeasureLoudness.
+	Err error
+}
+
+func NewAudioProcessor(storage *FileStorage) *AudioProcessor {
+	return &AudioProcessor{
+		Storage:  storage,
+		Loudness: domain.Loudness{IntegratedLUFS: -23, TruePeakDBTP: -1, RangeLU: 5},
+	}
+}
+
+func (p *AudioProcessor) HasAudio(ctx context.Context, videoPath string) (bool, error) {
+	if _, ok := p.Storage.ReadFile(videoPath); !ok {
+		return false, errors.New("input video not found")
+	}
+	return !p.NoAudio, nil
+}
+
+func (p *AudioProcessor) checkAudio(ctx context.Context, videoPath string) error {
+	hasAudio, err := p.HasAudio(ctx, videoPath)
+	if err != nil {
+		return err
+	}
+	if !hasAudio {
+		return domain.ErrNoAudioStream
+	}
+	return nil
+}
+
+func (p *AudioProcessor) ExtractAudio(ctx context.Context, videoPath, outputPath string, format domain.AudioFormat) error {
+	header, ok := audioHeaders[format]
+	if !ok {
+		return domain.ErrUnsupportedAudioFormat
+	}
+	if err := p.checkAudio(ctx, videoPath); err != nil {
+		return err
+	}
+	p.Storage.WriteFile(outputPath, []byte(header+"audio"))
+	return nil
+}
+
+func (p *AudioProcessor) RenderWaveform(ctx context.Context, videoPath string, width, height int) ([]byte, error) {
+	if err := p.checkAudio(ctx, videoPath); err != nil {
+		return nil, err
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		img.Set(x, height/2, color.NRGBA{0x3b, 0x82, 0xf6, 0xff})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *AudioProcessor) MeasureLoudness(ctx context.Context, videoPath string) (domain.Loudness, error) {
+	if err := p.checkAudio(ctx, videoPath); err != nil {
+		return domain.Loudness{}, err
+	}
+	if p.Err != nil {
+		return domain.Loudness{}, p.Err
+	}
+	return p.Loudness, nil
+}
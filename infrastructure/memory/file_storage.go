@@ -0,0 +1,214 @@
+// infrastructure/memory/file_storage.go
+package memory
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type storedObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+// FileStorage is an in-memory domain.FileStorageService. Paths are plain
+// slash-separated keys; no directory needs to exist before it is written to.
+type FileStorage struct {
+	mu    sync.Mutex
+	files map[string]storedObject
+}
+
+func NewFileStorage() *FileStorage {
+	return &FileStorage{files: make(map[string]storedObject)}
+}
+
+// WriteFile stores data at filePath, replacing any previous content.
+func (s *FileStorage) WriteFile(filePath string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[filePath] = storedObject{data: append([]byte(nil), data...), modTime: time.Now()}
+}
+
+// ReadFile returns the content stored at filePath.
+func (s *FileStorage) ReadFile(filePath string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.files[filePath]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), obj.data...), true
+}
+
+// Paths lists every stored path in lexical order.
+func (s *FileStorage) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.files))
+	for p := range s.files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (s *FileStorage) exists(filePath string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.files[filePath]
+	return ok
+}
+
+func (s *FileStorage) SaveUploadedFile(src io.Reader, filename string) (string, error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return "", err
+	}
+	filePath := path.Join("uploads", filename)
+	s.WriteFile(filePath, data)
+	return filePath, nil
+}
+
+func (s *FileStorage) SaveFile(filePath string, data []byte) error {
+	s.WriteFile(filePath, data)
+	return nil
+}
+
+func (s *FileStorage) OutputDir(userID, videoID, claim int) (string, error) {
+	return path.Join("processed_videos", strconv.Itoa(userID), strconv.Itoa(videoID), strconv.Itoa(claim)), nil
+}
+
+func (s *FileStorage) DeleteOutputDir(outputDir string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for p := range s.files {
+		if strings.HasPrefix(p, outputDir+"/") {
+			delete(s.files, p)
+		}
+	}
+	return nil
+}
+
+func (s *FileStorage) GenerateProcessedFileName(userID int, originalFilename string) string {
+	return fmt.Sprintf("%d_%s_processed.zip", userID, path.Base(originalFilename))
+}
+
+func (s *FileStorage) GenerateFramePattern(outputDir, originalFilename string) string {
+	return path.Join(outputDir, fmt.Sprintf("%s_%%04d.png", path.Base(originalFilename)))
+}
+
+func (s *FileStorage) GetProcessedFilePath(outputDir string, userID int, originalFilename string) string {
+	return path.Join(outputDir, s.GenerateProcessedFileName(userID, originalFilename))
+}
+
+func (s *FileStorage) GetManifestPath(outputDir string) string {
+	return path.Join(outputDir, domain.ManifestName)
+}
+
+func (s *FileStorage) GetOutputPath(outputDir, name string) string {
+	return path.Join(outputDir, "outputs", path.Base(name))
+}
+
+func (s *FileStorage) DeleteFile(filePath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, filePath)
+	return nil
+}
+
+func (s *FileStorage) listFrames(outputDir, originalFilename string) []string {
+	prefix := path.Join(outputDir, path.Base(originalFilename)+"_")
+	var frames []string
+	for _, p := range s.Paths() {
+		if strings.HasPrefix(p, prefix) && strings.HasSuffix(p, ".png") {
+			frames = append(frames, p)
+		}
+	}
+	return frames
+}
+
+func (s *FileStorage) ListFrames(outputDir string) ([]string, error) {
+	var frames []string
+	for _, p := range s.Paths() {
+		if path.Dir(p) == outputDir && strings.HasSuffix(p, ".png") {
+			frames = append(frames, p)
+		}
+	}
+	return frames, nil
+}
+
+func (s *FileStorage) DeleteFrames(outputDir, originalFilename string) error {
+	for _, framePath := range s.listFrames(outputDir, originalFilename) {
+		s.DeleteFile(framePath)
+	}
+	return nil
+}
+
+func (s *FileStorage) ZipFrames(outputDir, originalFilename, zipFilePath string) (int, error) {
+	frames := s.listFrames(outputDir, originalFilename)
+	if len(frames) == 0 {
+		return 0, errors.New("no frames extracted to zip")
+	}
+
+	entries := frames
+	if manifestPath := s.GetManifestPath(outputDir); s.exists(manifestPath) {
+		entries = append([]string{manifestPath}, frames...)
+	}
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for _, framePath := range entries {
+		data, ok := s.ReadFile(framePath)
+		if !ok {
+			return 0, fmt.Errorf("failed to add %s to zip: %w", path.Base(framePath), domain.ErrFileNotFound)
+		}
+		method := uint16(zip.Deflate)
+		if path.Ext(framePath) == ".png" {
+			method = zip.Store
+		}
+		writer, err := zipWriter.CreateHeader(&zip.FileHeader{Name: path.Base(framePath), Method: method})
+		if err != nil {
+			return 0, err
+		}
+		if _, err := writer.Write(data); err != nil {
+			return 0, err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close zip writer: %w", err)
+	}
+	s.WriteFile(zipFilePath, buf.Bytes())
+	return len(frames), nil
+}
+
+type nopCloseReader struct {
+	*bytes.Reader
+}
+
+func (nopCloseReader) Close() error { return nil }
+
+func (s *FileStorage) OpenFile(filePath string) (*domain.StoredFile, error) {
+	s.mu.Lock()
+	obj, ok := s.files[filePath]
+	s.mu.Unlock()
+	if !ok {
+		return nil, domain.ErrFileNotFound
+	}
+	return &domain.StoredFile{
+		ReadSeekCloser: nopCloseReader{bytes.NewReader(obj.data)},
+		Name:           path.Base(filePath),
+		Size:           int64(len(obj.data)),
+		ModTime:        obj.modTime,
+	}, nil
+}
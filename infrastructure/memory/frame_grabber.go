@@ -0,0 +1,82 @@
+// infrastructure/memory/frame_grabber.go
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// FrameGrabber is a fake domain.FrameGrabber that renders a flat image
+// instead of seeking with ffmpeg. WebP output is a stub RIFF header, since
+// the standard library has no WebP encoder.
+type FrameGrabber struct {
+	Storage *FileStorage
+	// Duration is the length of every video; later timestamps have no frame.
+	Duration time.Duration
+	// Hold, when set, blocks each grab until it is closed or the context ends.
+	Hold chan struct{}
+	// Err, when set, is returned from GrabFrame.
+	Err   error
+	calls atomic.Int64
+}
+
+func NewFrameGrabber(storage *FileStorage, duration time.Duration) *FrameGrabber {
+	return &FrameGrabber{Storage: storage, Duration: duration}
+}
+
+// Calls reports how many grabs reached the fake.
+func (g *FrameGrabber) Calls() int {
+	return int(g.calls.Load())
+}
+
+func (g *FrameGrabber) GrabFrame(ctx context.Context, videoPath string, at time.Duration, width int, format domain.ImageFormat) ([]byte, error) {
+	g.calls.Add(1)
+	if g.Hold != nil {
+		select {
+		case <-g.Hold:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	if _, ok := g.Storage.ReadFile(videoPath); !ok {
+		return nil, errors.New("input video not found")
+	}
+	if at >= g.Duration {
+		return nil, domain.ErrFrameNotFound
+	}
+
+	if width == 0 {
+		width = 16
+	}
+	img := image.NewGray(image.Rect(0, 0, width, width*9/16))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(at / time.Second)
+	}
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case domain.ImageFormatJPEG:
+		err = jpeg.Encode(&buf, img, nil)
+	case domain.ImageFormatPNG:
+		err = png.Encode(&buf, img)
+	case domain.ImageFormatWebP:
+		buf.WriteString("RIFF\x00\x00\x00\x00WEBPVP8 ")
+	default:
+		err = domain.ErrUnsupportedImageType
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
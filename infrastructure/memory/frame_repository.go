@@ -0,0 +1,38 @@
+// infrastructure/memory/frame_repository.go
+package memory
+
+import (
+	"sync"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// FrameRepository is an in-memory domain.FrameRepository.
+type FrameRepository struct {
+	mu     sync.Mutex
+	frames map[string][]domain.Frame
+}
+
+func NewFrameRepository() *FrameRepository {
+	return &FrameRepository{frames: make(map[string][]domain.Frame)}
+}
+
+func (r *FrameRepository) ReplaceFrames(artifactPath string, frames []domain.Frame) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frames[artifactPath] = append([]domain.Frame(nil), frames...)
+	return nil
+}
+
+func (r *FrameRepository) FindByArtifact(artifactPath string) ([]domain.Frame, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.Frame(nil), r.frames[artifactPath]...), nil
+}
+
+func (r *FrameRepository) DeleteByArtifact(artifactPath string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.frames, artifactPath)
+	return nil
+}
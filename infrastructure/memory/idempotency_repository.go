@@ -0,0 +1,65 @@
+// infrastructure/memory/idempotency_repository.go
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type idempotencyKey struct {
+	userID int
+	key    string
+}
+
+// IdempotencyRepository is an in-memory domain.IdempotencyRepository.
+type IdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[idempotencyKey]domain.IdempotencyRecord
+}
+
+func NewIdempotencyRepository() *IdempotencyRepository {
+	return &IdempotencyRepository{records: make(map[idempotencyKey]domain.IdempotencyRecord)}
+}
+
+func (r *IdempotencyRepository) Reserve(record *domain.IdempotencyRecord, window, claimTimeout time.Duration) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := idempotencyKey{record.UserID, record.Key}
+	if existing, ok := r.records[k]; ok && time.Since(existing.CreatedAt) < window {
+		if existing.VideoStatusID != 0 || time.Since(existing.CreatedAt) < claimTimeout {
+			return &existing, nil
+		}
+	}
+	record.CreatedAt = time.Now()
+	record.VideoStatusID = 0
+	r.records[k] = *record
+	return nil, nil
+}
+
+func (r *IdempotencyRepository) Complete(record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := idempotencyKey{record.UserID, record.Key}
+	if rec, ok := r.records[k]; ok {
+		rec.VideoStatusID = record.VideoStatusID
+		rec.Message = record.Message
+		rec.Deduplicated = record.Deduplicated
+		r.records[k] = rec
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Release(userID int, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := idempotencyKey{userID, key}
+	if rec, ok := r.records[k]; ok && rec.VideoStatusID == 0 {
+		delete(r.records, k)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// infrastructure/memory/message_queue.go
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vitovidale/video-processor-service/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var errQueueClosed = errors.New("message queue closed")
+
+// MessageQueue is an in-memory domain.MessageQueueService backed by a
+// buffered channel. Published messages are delivered in order to whichever
+// consumer is running.
+type MessageQueue struct {
+	mu        sync.Mutex
+	closed    bool
+	messages  chan envelope
+	published []domain.VideoProcessingMessage
+	consuming atomic.Bool
+	// Err, when set, is returned by PublishVideoProcessing instead of
+	// publishing.
+	Err error
+}
+
+// envelope pairs a message with its propagated trace context, like the
+// headers of a real broker message.
+type envelope struct {
+	headers propagation.MapCarrier
+	message domain.VideoProcessingMessage
+}
+
+func NewMessageQueue(capacity int) *MessageQueue {
+	return &MessageQueue{messages: make(chan envelope, capacity)}
+}
+
+func (q *MessageQueue) PublishVideoProcessing(ctx context.Context, message domain.VideoProcessingMessage) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return errQueueClosed
+	}
+	if q.Err != nil {
+		return q.Err
+	}
+	headers := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, headers)
+	q.published = append(q.published, message)
+	q.messages <- envelope{headers: headers, message: message}
+	return nil
+}
+
+// ConsumeVideoProcessing blocks until Close is called.
+func (q *MessageQueue) ConsumeVideoProcessing(handler func(context.Context, domain.VideoProcessingMessage)) error {
+	q.consuming.Store(true)
+	defer q.consuming.Store(false)
+	for env := range q.messages {
+		handler(otel.GetTextMapPropagator().Extract(context.Background(), env.headers), env.message)
+	}
+	return nil
+}
+
+// Consuming reports whether ConsumeVideoProcessing is running.
+func (q *MessageQueue) Consuming() bool {
+	return q.consuming.Load()
+}
+
+// QueueStats reports messages published but not yet handed to the consumer.
+func (q *MessageQueue) QueueStats(context.Context) (domain.QueueStats, error) {
+	consumers := 0
+	if q.Consuming() {
+		consumers = 1
+	}
+	return domain.QueueStats{Name: "memory", Messages: len(q.messages), Consumers: consumers}, nil
+}
+
+// Published returns every message published so far.
+func (q *MessageQueue) Published() []domain.VideoProcessingMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]domain.VideoProcessingMessage(nil), q.published...)
+}
+
+func (q *MessageQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		close(q.messages)
+	}
+}
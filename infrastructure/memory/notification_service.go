@@ -0,0 +1,41 @@
+// infrastructure/memory/notification_service.go
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+type Notification struct {
+	UserID           int
+	OriginalFilename string
+	Status           string
+	Message          string
+}
+
+// NotificationService records notifications instead of delivering them.
+type NotificationService struct {
+	mu            sync.Mutex
+	notifications []Notification
+}
+
+func NewNotificationService() *NotificationService {
+	return &NotificationService{}
+}
+
+func (n *NotificationService) SendNotification(_ context.Context, userID int, originalFilename, status, message string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, Notification{
+		UserID:           userID,
+		OriginalFilename: originalFilename,
+		Status:           status,
+		Message:          message,
+	})
+}
+
+func (n *NotificationService) Notifications() []Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Notification(nil), n.notifications...)
+}
@@ -0,0 +1,48 @@
+// infrastructure/memory/output_repository.go
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// OutputRepository is an in-memory domain.OutputRepository.
+type OutputRepository struct {
+	mu      sync.Mutex
+	outputs map[string][]domain.Output
+}
+
+func NewOutputRepository() *OutputRepository {
+	return &OutputRepository{outputs: make(map[string][]domain.Output)}
+}
+
+func (r *OutputRepository) ReplaceOutputs(artifactPath string, outputs []domain.Output) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := make([]domain.Output, len(outputs))
+	for i, o := range outputs {
+		if o.CreatedAt.IsZero() {
+			o.CreatedAt = time.Now()
+		}
+		stored[i] = o
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Name < stored[j].Name })
+	r.outputs[artifactPath] = stored
+	return nil
+}
+
+func (r *OutputRepository) FindByArtifact(artifactPath string) ([]domain.Output, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.Output(nil), r.outputs[artifactPath]...), nil
+}
+
+func (r *OutputRepository) DeleteByArtifact(artifactPath string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.outputs, artifactPath)
+	return nil
+}
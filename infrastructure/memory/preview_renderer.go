@@ -0,0 +1,70 @@
+// infrastructure/memory/preview_renderer.go
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"sync"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// PreviewRenderer is a fake domain.PreviewRenderer. GIFs are real, filled
+// with noise so their size grows with the area and frame count like a real
+// clip's; WebP output is a stub RIFF header.
+type PreviewRenderer struct {
+	Storage *FileStorage
+	// Err, when set, is returned from RenderPreview.
+	Err   error
+	mu    sync.Mutex
+	calls []domain.PreviewSettings
+}
+
+func NewPreviewRenderer(storage *FileStorage) *PreviewRenderer {
+	return &PreviewRenderer{Storage: storage}
+}
+
+// Calls returns the settings of every render, in order.
+func (r *PreviewRenderer) Calls() []domain.PreviewSettings {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.PreviewSettings(nil), r.calls...)
+}
+
+func (r *PreviewRenderer) RenderPreview(ctx context.Context, videoPath string, settings domain.PreviewSettings) ([]byte, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, settings)
+	r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	if _, ok := r.Storage.ReadFile(videoPath); !ok {
+		return nil, errors.New("input video not found")
+	}
+	if settings.Format == domain.PreviewFormatWebP {
+		return []byte("RIFF\x00\x00\x00\x00WEBPVP8X"), nil
+	}
+
+	frames := max(1, int(float64(settings.Segments)*settings.SegmentLength.Seconds()*settings.FPS))
+	bounds := image.Rect(0, 0, settings.Width, max(2, settings.Width*9/16&^1))
+	anim := &gif.GIF{}
+	seed := uint32(1)
+	for range frames {
+		img := image.NewPaletted(bounds, palette.Plan9)
+		for i := range img.Pix {
+			seed = seed*1664525 + 1013904223
+			img.Pix[i] = uint8(seed >> 24)
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, int(100/settings.FPS))
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,39 @@
+// infrastructure/memory/quota_repository.go
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// QuotaRepository is an in-memory domain.QuotaRepository.
+type QuotaRepository struct {
+	mu     sync.Mutex
+	quotas map[int]domain.UserQuota
+}
+
+func NewQuotaRepository() *QuotaRepository {
+	return &QuotaRepository{quotas: make(map[int]domain.UserQuota)}
+}
+
+func (r *QuotaRepository) Get(userID int) (*domain.UserQuota, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	q, ok := r.quotas[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &q, nil
+}
+
+func (r *QuotaRepository) Set(quota *domain.UserQuota) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	quota.UpdatedAt = time.Now()
+	r.quotas[quota.UserID] = *quota
+	return nil
+}
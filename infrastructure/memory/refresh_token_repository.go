@@ -0,0 +1,86 @@
+// infrastructure/memory/refresh_token_repository.go
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// RefreshTokenRepository is an in-memory domain.RefreshTokenRepository.
+type RefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens []domain.RefreshToken
+}
+
+func NewRefreshTokenRepository() *RefreshTokenRepository {
+	return &RefreshTokenRepository{}
+}
+
+func (r *RefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.create(token)
+	return nil
+}
+
+func (r *RefreshTokenRepository) create(token *domain.RefreshToken) {
+	token.ID = len(r.tokens) + 1
+	token.CreatedAt = time.Now()
+	r.tokens = append(r.tokens, *token)
+}
+
+func (r *RefreshTokenRepository) FindByHash(tokenHash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash {
+			return &t, nil
+		}
+	}
+	return nil, domain.ErrRefreshTokenNotFound
+}
+
+func (r *RefreshTokenRepository) Rotate(tokenID int, next *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.tokens {
+		t := &r.tokens[i]
+		if t.ID != tokenID {
+			continue
+		}
+		if t.RotatedAt != nil || t.RevokedAt != nil {
+			return domain.ErrInvalidRefreshToken
+		}
+		now := time.Now()
+		t.RotatedAt = &now
+		r.create(next)
+		return nil
+	}
+	return domain.ErrInvalidRefreshToken
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(familyID string) error {
+	r.revokeWhere(func(t domain.RefreshToken) bool { return t.FamilyID == familyID })
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeByUserID(userID int) error {
+	r.revokeWhere(func(t domain.RefreshToken) bool { return t.UserID == userID })
+	return nil
+}
+
+func (r *RefreshTokenRepository) revokeWhere(match func(domain.RefreshToken) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for i := range r.tokens {
+		if match(r.tokens[i]) && r.tokens[i].RevokedAt == nil {
+			r.tokens[i].RevokedAt = &now
+		}
+	}
+}
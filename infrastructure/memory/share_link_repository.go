@@ -0,0 +1,143 @@
+// infrastructure/memory/share_link_repository.go
+package memory
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// ShareLinkRepository is an in-memory domain.ShareLinkRepository.
+type ShareLinkRepository struct {
+	mu        sync.Mutex
+	links     []domain.ShareLink
+	accesses  []domain.ShareAccess
+	transfers map[string]*domain.ShareTransfer
+}
+
+func NewShareLinkRepository() *ShareLinkRepository {
+	return &ShareLinkRepository{transfers: make(map[string]*domain.ShareTransfer)}
+}
+
+func (r *ShareLinkRepository) Create(link *domain.ShareLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link.ID = len(r.links) + 1
+	link.CreatedAt = time.Now()
+	r.links = append(r.links, *link)
+	return nil
+}
+
+func (r *ShareLinkRepository) FindByID(linkID int) (*domain.ShareLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if linkID <= 0 || linkID > len(r.links) {
+		return nil, domain.ErrShareLinkNotFound
+	}
+	link := r.links[linkID-1]
+	return &link, nil
+}
+
+func (r *ShareLinkRepository) FindByVideoID(videoID int) ([]domain.ShareLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var links []domain.ShareLink
+	for _, l := range r.links {
+		if l.VideoID == videoID {
+			links = append(links, l)
+		}
+	}
+	slices.Reverse(links)
+	return links, nil
+}
+
+func (r *ShareLinkRepository) Revoke(videoID, linkID int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if linkID <= 0 || linkID > len(r.links) || r.links[linkID-1].VideoID != videoID {
+		return domain.ErrShareLinkNotFound
+	}
+	if l := &r.links[linkID-1]; l.RevokedAt == nil {
+		l.RevokedAt = &at
+	}
+	return nil
+}
+
+func (r *ShareLinkRepository) ConsumeDownload(linkID int, now time.Time) (*domain.ShareLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if linkID <= 0 || linkID > len(r.links) {
+		return nil, domain.ErrShareLinkNotFound
+	}
+	l := &r.links[linkID-1]
+	if err := l.Check(now); err != nil {
+		return nil, err
+	}
+	l.DownloadCount++
+	link := *l
+	return &link, nil
+}
+
+func (r *ShareLinkRepository) CreateTransfer(transfer *domain.ShareTransfer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transfer.CreatedAt = time.Now()
+	stored := *transfer
+	r.transfers[transfer.Token] = &stored
+	return nil
+}
+
+func (r *ShareLinkRepository) ResumeTransfer(linkID int, token, checksum string, start int64) (*domain.ShareTransfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.transfers[token]
+	if !ok || t.ShareLinkID != linkID || !t.CanResume(checksum, start) {
+		return nil, domain.ErrShareTransferNotFound
+	}
+	t.Resent += t.Sent - start
+	transfer := *t
+	return &transfer, nil
+}
+
+func (r *ShareLinkRepository) AdvanceTransfer(linkID int, token string, start, end int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.transfers[token]; ok && t.ShareLinkID == linkID && t.Sent >= start {
+		t.Sent = min(t.Size, max(t.Sent, end))
+	}
+	return nil
+}
+
+func (r *ShareLinkRepository) LogAccess(access *domain.ShareAccess) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	access.ID = len(r.accesses) + 1
+	access.AccessedAt = time.Now()
+	r.accesses = append(r.accesses, *access)
+	return nil
+}
+
+func (r *ShareLinkRepository) FindAccesses(linkID int) ([]domain.ShareAccess, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var accesses []domain.ShareAccess
+	for _, a := range r.accesses {
+		if a.ShareLinkID == linkID {
+			accesses = append(accesses, a)
+		}
+	}
+	slices.Reverse(accesses)
+	return accesses, nil
+}
@@ -0,0 +1,50 @@
+// infrastructure/memory/subtitle_extractor.go
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// SubtitleExtractor is a fake domain.SubtitleExtractor: every video has
+// Streams, and each text stream holds a single cue naming its language.
+type SubtitleExtractor struct {
+	Storage *FileStorage
+	Streams []domain.SubtitleStream
+}
+
+func NewSubtitleExtractor(storage *FileStorage) *SubtitleExtractor {
+	return &SubtitleExtractor{Storage: storage}
+}
+
+func (e *SubtitleExtractor) ProbeSubtitles(ctx context.Context, videoPath string) ([]domain.SubtitleStream, error) {
+	if _, ok := e.Storage.ReadFile(videoPath); !ok {
+		return nil, errors.New("input video not found")
+	}
+	return append([]domain.SubtitleStream{}, e.Streams...), nil
+}
+
+func (e *SubtitleExtractor) ExtractSubtitles(ctx context.Context, videoPath string, index int, format domain.SubtitleFormat) ([]byte, error) {
+	streams, err := e.ProbeSubtitles(ctx, videoPath)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(streams) {
+		return nil, fmt.Errorf("no subtitle stream %d", index)
+	}
+	if !streams[index].IsText() {
+		return nil, fmt.Errorf("%w: %s", domain.ErrBitmapSubtitles, streams[index].Codec)
+	}
+	text := "subtitle " + streams[index].Language
+	switch format {
+	case domain.SubtitleFormatSRT:
+		return []byte("1\n00:00:01,000 --> 00:00:02,000\n" + text + "\n"), nil
+	case domain.SubtitleFormatWebVTT:
+		return []byte("WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n" + text + "\n"), nil
+	default:
+		return nil, domain.ErrUnsupportedSubtitleFormat
+	}
+}
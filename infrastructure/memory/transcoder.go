@@ -0,0 +1,50 @@
+// infrastructure/memory/transcoder.go
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// Transcoder is a fake domain.Transcoder that writes a stub MP4: an ftyp
+// and moov box ahead of a media box of Size bytes, as a faststart file has.
+type Transcoder struct {
+	Storage *FileStorage
+	Size    int
+	// Err, when set, is returned from TranscodeProxy after a partial file
+	// is written, as a failed ffmpeg run leaves one.
+	Err   error
+	mu    sync.Mutex
+	calls []domain.ProxySettings
+}
+
+func NewTranscoder(storage *FileStorage) *Transcoder {
+	return &Transcoder{Storage: storage, Size: 64 << 10}
+}
+
+// Calls returns the settings of every transcode, in order.
+func (t *Transcoder) Calls() []domain.ProxySettings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]domain.ProxySettings(nil), t.calls...)
+}
+
+func (t *Transcoder) TranscodeProxy(ctx context.Context, videoPath, outputPath string, settings domain.ProxySettings) error {
+	t.mu.Lock()
+	t.calls = append(t.calls, settings)
+	t.mu.Unlock()
+	if _, ok := t.Storage.ReadFile(videoPath); !ok {
+		return errors.New("input video not found")
+	}
+	header := "\x00\x00\x00\x18ftypisom\x00\x00\x02\x00isomiso2\x00\x00\x00\x08moov"
+	if t.Err != nil {
+		t.Storage.WriteFile(outputPath, []byte(header))
+		return t.Err
+	}
+	t.Storage.WriteFile(outputPath, append([]byte(header+"mdat"), bytes.Repeat([]byte{0x42}, t.Size)...))
+	return nil
+}
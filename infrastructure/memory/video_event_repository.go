@@ -0,0 +1,42 @@
+// infrastructure/memory/video_event_repository.go
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// VideoEventRepository is an in-memory domain.VideoEventRepository.
+type VideoEventRepository struct {
+	mu     sync.Mutex
+	events []domain.VideoEvent
+}
+
+func NewVideoEventRepository() *VideoEventRepository {
+	return &VideoEventRepository{}
+}
+
+func (r *VideoEventRepository) Append(event *domain.VideoEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.ID = len(r.events) + 1
+	event.CreatedAt = time.Now()
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *VideoEventRepository) FindByVideoID(videoID int) ([]domain.VideoEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []domain.VideoEvent
+	for _, e := range r.events {
+		if e.VideoID == videoID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
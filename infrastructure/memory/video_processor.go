@@ -0,0 +1,72 @@
+// infrastructure/memory/video_processor.go
+package memory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// VideoProcessor is a fake domain.VideoProcessor that writes synthetic PNG
+// frames into a FileStorage instead of running ffmpeg.
+type VideoProcessor struct {
+	Storage    *FileStorage
+	FrameCount int
+	// Err, when set, is returned from ExtractFrames without writing frames.
+	Err error
+	// Hold, when set, blocks each extraction until it is closed.
+	Hold chan struct{}
+	// Timestamps, when set, are reported for the frames instead of the
+	// nominal ones derived from the extraction rate.
+	Timestamps []time.Duration
+}
+
+func NewVideoProcessor(storage *FileStorage, frameCount int) *VideoProcessor {
+	return &VideoProcessor{Storage: storage, FrameCount: frameCount}
+}
+
+func (p *VideoProcessor) ExtractFrames(videoPath, framePattern string, settings domain.ExtractionSettings) ([]time.Duration, error) {
+	if p.Hold != nil {
+		<-p.Hold
+	}
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	if _, ok := p.Storage.ReadFile(videoPath); !ok {
+		return nil, errors.New("input video not found")
+	}
+	fps := settings.OrDefault().FPS
+	timestamps := make([]time.Duration, p.FrameCount)
+	for i := 1; i <= p.FrameCount; i++ {
+		frame, err := syntheticFrame(i)
+		if err != nil {
+			return nil, err
+		}
+		p.Storage.WriteFile(fmt.Sprintf(framePattern, i), frame)
+		timestamps[i-1] = time.Duration(float64(i-1) / fps * float64(time.Second))
+		if i <= len(p.Timestamps) {
+			timestamps[i-1] = p.Timestamps[i-1]
+		}
+	}
+	return timestamps, nil
+}
+
+func syntheticFrame(index int) ([]byte, error) {
+	img := image.NewGray(image.Rect(0, 0, 16, 16))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(index * 16)
+	}
+	img.Set(0, 0, color.White)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,321 @@
+// infrastructure/memory/video_repository.go
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// VideoRepository is an in-memory domain.VideoRepository.
+type VideoRepository struct {
+	mu     sync.Mutex
+	nextID int
+	videos map[int]domain.Video
+}
+
+func NewVideoRepository() *VideoRepository {
+	return &VideoRepository{nextID: 1, videos: make(map[int]domain.Video)}
+}
+
+func (r *VideoRepository) Save(video *domain.Video) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.save(video)
+	return nil
+}
+
+func (r *VideoRepository) SaveWithinQuota(video *domain.Video, maxActive int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if maxActive > 0 && r.countActive(video.UserID) >= maxActive {
+		return domain.ErrQuotaExceeded
+	}
+	r.save(video)
+	return nil
+}
+
+func (r *VideoRepository) RequeueWithinQuota(videoID, maxActive int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.videos[videoID]
+	if !ok {
+		return domain.ErrVideoNotFound
+	}
+	if v.Status != domain.VideoStatusFailed {
+		return &domain.InvalidTransitionError{VideoID: videoID, From: v.Status, To: domain.VideoStatusPending}
+	}
+	if maxActive > 0 && r.countActive(v.UserID) >= maxActive {
+		return domain.ErrQuotaExceeded
+	}
+	r.setStatus(&v, domain.VideoStatusPending, "", "")
+	return nil
+}
+
+func (r *VideoRepository) save(video *domain.Video) {
+	now := time.Now()
+	video.ID = r.nextID
+	video.ExtractionSettings = video.ExtractionSettings.OrDefault()
+	video.ArtifactLayout = video.ArtifactLayout.OrDefault()
+	video.CreatedAt = now
+	video.UpdatedAt = now
+	r.nextID++
+	r.videos[video.ID] = *video
+}
+
+func (r *VideoRepository) TransitionStatus(videoID int, from, to domain.VideoStatus, processedFilePath, errorMessage string) error {
+	if err := domain.ValidateTransition(videoID, from, to); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.videos[videoID]
+	if !ok {
+		return domain.ErrVideoNotFound
+	}
+	if v.Status != from {
+		return &domain.InvalidTransitionError{VideoID: videoID, From: v.Status, To: to}
+	}
+	if from == domain.VideoStatusProcessing {
+		v.Claim++
+	}
+	r.setStatus(&v, to, processedFilePath, errorMessage)
+	return nil
+}
+
+func (r *VideoRepository) ClaimVideo(videoID int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.videos[videoID]
+	if !ok {
+		return 0, domain.ErrVideoNotFound
+	}
+	if v.Status != domain.VideoStatusPending {
+		return 0, &domain.InvalidTransitionError{VideoID: videoID, From: v.Status, To: domain.VideoStatusProcessing}
+	}
+	v.Claim++
+	r.setStatus(&v, domain.VideoStatusProcessing, "", "")
+	return v.Claim, nil
+}
+
+func (r *VideoRepository) FinishClaim(videoID, claim int, to domain.VideoStatus, processedFilePath, errorMessage string) error {
+	if err := domain.ValidateTransition(videoID, domain.VideoStatusProcessing, to); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, err := r.claimed(videoID, claim)
+	if err != nil {
+		return err
+	}
+	if v.Status != domain.VideoStatusProcessing {
+		return domain.ErrClaimLost
+	}
+	r.setStatus(&v, to, processedFilePath, errorMessage)
+	return nil
+}
+
+func (r *VideoRepository) setStatus(v *domain.Video, to domain.VideoStatus, processedFilePath, errorMessage string) {
+	v.Status = to
+	v.ProcessedFilePath = processedFilePath
+	v.ErrorMessage = errorMessage
+	if to != domain.VideoStatusCompleted {
+		v.ProcessedFileChecksum = ""
+	}
+	v.UpdatedAt = time.Now()
+	r.videos[v.ID] = *v
+}
+
+// claimed returns the video while claim is still its claim.
+func (r *VideoRepository) claimed(videoID, claim int) (domain.Video, error) {
+	v, ok := r.videos[videoID]
+	if !ok {
+		return v, domain.ErrVideoNotFound
+	}
+	if v.Claim != claim {
+		return v, domain.ErrClaimLost
+	}
+	return v, nil
+}
+
+func (r *VideoRepository) FindByID(videoID int) (*domain.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.videos[videoID]
+	if !ok {
+		return nil, domain.ErrVideoNotFound
+	}
+	return &v, nil
+}
+
+func (r *VideoRepository) FindByUserID(userID int) ([]domain.Video, error) {
+	return r.find(func(v domain.Video) bool { return v.UserID == userID && v.WorkspaceID == 0 }), nil
+}
+
+func (r *VideoRepository) FindByWorkspaceID(workspaceID int) ([]domain.Video, error) {
+	return r.find(func(v domain.Video) bool { return v.WorkspaceID == workspaceID }), nil
+}
+
+func (r *VideoRepository) FindAll(filter domain.VideoFilter) ([]domain.Video, error) {
+	videos := r.find(func(v domain.Video) bool {
+		return (filter.UserID == 0 || v.UserID == filter.UserID) && (filter.Status == "" || v.Status == filter.Status)
+	})
+	videos = videos[min(filter.Offset, len(videos)):]
+	if filter.Limit > 0 && filter.Limit < len(videos) {
+		videos = videos[:filter.Limit]
+	}
+	return videos, nil
+}
+
+// find returns the videos matching match, newest first.
+func (r *VideoRepository) find(match func(domain.Video) bool) []domain.Video {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var videos []domain.Video
+	for _, v := range r.videos {
+		if match(v) {
+			videos = append(videos, v)
+		}
+	}
+	sort.Slice(videos, func(i, j int) bool {
+		if videos[i].CreatedAt.Equal(videos[j].CreatedAt) {
+			return videos[i].ID > videos[j].ID
+		}
+		return videos[i].CreatedAt.After(videos[j].CreatedAt)
+	})
+	return videos
+}
+
+func (r *VideoRepository) CountActiveByUserID(userID int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.countActive(userID), nil
+}
+
+func (r *VideoRepository) countActive(userID int) int {
+	n := 0
+	for _, v := range r.videos {
+		if v.UserID == userID && (v.Status == domain.VideoStatusPending || v.Status == domain.VideoStatusProcessing) {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *VideoRepository) ProcessedFileInUse(path string, exceptVideoID int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, v := range r.videos {
+		if v.ID != exceptVideoID && v.ProcessedFilePath == path {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *VideoRepository) Delete(videoID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.videos[videoID]; !ok {
+		return domain.ErrVideoNotFound
+	}
+	delete(r.videos, videoID)
+	for id, v := range r.videos {
+		if v.ReusedFromVideoID == videoID {
+			v.ReusedFromVideoID = 0
+			r.videos[id] = v
+		}
+	}
+	return nil
+}
+
+func (r *VideoRepository) FindCompletedByContentHash(contentHash string, settings domain.ExtractionSettings, userID int) (*domain.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found *domain.Video
+	for _, v := range r.videos {
+		if v.Status != domain.VideoStatusCompleted || v.ContentHash != contentHash || v.ExtractionSettings != settings.OrDefault() {
+			continue
+		}
+		if userID != 0 && v.UserID != userID {
+			continue
+		}
+		if found == nil || v.ID > found.ID {
+			v := v
+			found = &v
+		}
+	}
+	if found == nil {
+		return nil, domain.ErrVideoNotFound
+	}
+	return found, nil
+}
+
+func (r *VideoRepository) SetProcessedFileChecksum(videoID, claim int, checksum string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, err := r.claimed(videoID, claim)
+	if err != nil {
+		return err
+	}
+	v.ProcessedFileChecksum = checksum
+	r.videos[videoID] = v
+	return nil
+}
+
+func (r *VideoRepository) SetArtifactLayout(videoID, claim int, layout domain.ArtifactLayout) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, err := r.claimed(videoID, claim)
+	if err != nil {
+		return err
+	}
+	v.ArtifactLayout = layout.OrDefault()
+	r.videos[videoID] = v
+	return nil
+}
+
+func (r *VideoRepository) SetAudioAnalysis(videoID, claim int, analysis domain.AudioAnalysis) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, err := r.claimed(videoID, claim)
+	if err != nil {
+		return err
+	}
+	if analysis.Loudness != nil {
+		loudness := *analysis.Loudness
+		analysis.Loudness = &loudness
+	}
+	v.Audio = &analysis
+	r.videos[videoID] = v
+	return nil
+}
+
+func (r *VideoRepository) SetSubtitleStreams(videoID, claim int, streams []domain.SubtitleStream) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, err := r.claimed(videoID, claim)
+	if err != nil {
+		return err
+	}
+	v.SubtitleStreams = append([]domain.SubtitleStream(nil), streams...)
+	r.videos[videoID] = v
+	return nil
+}
@@ -0,0 +1,105 @@
+// infrastructure/memory/workspace_repository.go
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type memberKey struct{ workspaceID, userID int }
+
+// WorkspaceRepository is an in-memory domain.WorkspaceRepository.
+type WorkspaceRepository struct {
+	mu         sync.Mutex
+	workspaces []domain.Workspace
+	members    map[memberKey]domain.WorkspaceMember
+}
+
+func NewWorkspaceRepository() *WorkspaceRepository {
+	return &WorkspaceRepository{members: make(map[memberKey]domain.WorkspaceMember)}
+}
+
+func (r *WorkspaceRepository) Create(workspace *domain.Workspace, ownerID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	workspace.ID = len(r.workspaces) + 1
+	workspace.CreatedAt = time.Now()
+	r.workspaces = append(r.workspaces, *workspace)
+	r.members[memberKey{workspace.ID, ownerID}] = domain.WorkspaceMember{
+		WorkspaceID: workspace.ID, UserID: ownerID, Role: domain.WorkspaceRoleOwner, CreatedAt: workspace.CreatedAt,
+	}
+	return nil
+}
+
+func (r *WorkspaceRepository) FindByUserID(userID int) ([]domain.WorkspaceMembership, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var memberships []domain.WorkspaceMembership
+	for _, w := range r.workspaces {
+		if m, ok := r.members[memberKey{w.ID, userID}]; ok {
+			memberships = append(memberships, domain.WorkspaceMembership{Workspace: w, Role: m.Role})
+		}
+	}
+	return memberships, nil
+}
+
+func (r *WorkspaceRepository) FindMember(workspaceID, userID int) (*domain.WorkspaceMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.members[memberKey{workspaceID, userID}]
+	if !ok {
+		return nil, domain.ErrNotWorkspaceMember
+	}
+	return &m, nil
+}
+
+func (r *WorkspaceRepository) ListMembers(workspaceID int) ([]domain.WorkspaceMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var members []domain.WorkspaceMember
+	for key, m := range r.members {
+		if key.workspaceID == workspaceID {
+			members = append(members, m)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].CreatedAt.Equal(members[j].CreatedAt) {
+			return members[i].UserID < members[j].UserID
+		}
+		return members[i].CreatedAt.Before(members[j].CreatedAt)
+	})
+	return members, nil
+}
+
+func (r *WorkspaceRepository) SetMember(member *domain.WorkspaceMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{member.WorkspaceID, member.UserID}
+	if existing, ok := r.members[key]; ok {
+		member.CreatedAt = existing.CreatedAt
+	} else {
+		member.CreatedAt = time.Now()
+	}
+	r.members[key] = *member
+	return nil
+}
+
+func (r *WorkspaceRepository) RemoveMember(workspaceID, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{workspaceID, userID}
+	if _, ok := r.members[key]; !ok {
+		return domain.ErrNotWorkspaceMember
+	}
+	delete(r.members, key)
+	return nil
+}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS video_processing_statuses (
+    id                      SERIAL PRIMARY KEY,
+    user_id                 INTEGER NOT NULL,
+    video_original_filename TEXT NOT NULL,
+    status                  VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    processed_file_path     TEXT,
+    error_message           TEXT,
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_video_processing_statuses_status;
+DROP INDEX IF EXISTS idx_video_processing_statuses_user_created;
+ALTER TABLE video_processing_statuses
+    DROP CONSTRAINT IF EXISTS video_processing_statuses_status_check;
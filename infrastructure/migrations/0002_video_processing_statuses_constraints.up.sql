@@ -0,0 +1,10 @@
+ALTER TABLE video_processing_statuses
+    DROP CONSTRAINT IF EXISTS video_processing_statuses_status_check;
+ALTER TABLE video_processing_statuses
+    ADD CONSTRAINT video_processing_statuses_status_check
+    CHECK (status IN ('PENDING', 'PROCESSING', 'COMPLETED', 'FAILED'));
+
+CREATE INDEX IF NOT EXISTS idx_video_processing_statuses_user_created
+    ON video_processing_statuses (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_video_processing_statuses_status
+    ON video_processing_statuses (status);
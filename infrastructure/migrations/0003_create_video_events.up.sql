@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS video_events (
+    id            BIGSERIAL PRIMARY KEY,
+    video_id      INTEGER NOT NULL REFERENCES video_processing_statuses (id) ON DELETE CASCADE,
+    from_status   VARCHAR(20),
+    to_status     VARCHAR(20) NOT NULL,
+    worker_id     TEXT,
+    attempt       INTEGER NOT NULL DEFAULT 1,
+    duration_ms   BIGINT NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_events_video_created
+    ON video_events (video_id, created_at);
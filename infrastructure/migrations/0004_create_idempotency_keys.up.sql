@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id         INTEGER NOT NULL,
+    key             VARCHAR(255) NOT NULL,
+    request_hash    CHAR(64) NOT NULL,
+    video_status_id INTEGER REFERENCES video_processing_statuses (id) ON DELETE CASCADE,
+    filename        TEXT NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created
+    ON idempotency_keys (created_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_video_processing_statuses_completed_hash;
+ALTER TABLE video_processing_statuses
+    DROP COLUMN IF EXISTS reused_from_video_id,
+    DROP COLUMN IF EXISTS extraction_settings,
+    DROP COLUMN IF EXISTS content_hash;
@@ -0,0 +1,8 @@
+ALTER TABLE video_processing_statuses
+    ADD COLUMN IF NOT EXISTS content_hash CHAR(64),
+    ADD COLUMN IF NOT EXISTS extraction_settings JSONB NOT NULL DEFAULT '{"fps": 1}',
+    ADD COLUMN IF NOT EXISTS reused_from_video_id INTEGER REFERENCES video_processing_statuses (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_video_processing_statuses_completed_hash
+    ON video_processing_statuses (content_hash)
+    WHERE status = 'COMPLETED';
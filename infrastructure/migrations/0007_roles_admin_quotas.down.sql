@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_quotas;
+
+ALTER TABLE video_processing_statuses
+    DROP COLUMN IF EXISTS source_path;
@@ -0,0 +1,9 @@
+ALTER TABLE video_processing_statuses
+    ADD COLUMN IF NOT EXISTS source_path TEXT;
+
+CREATE TABLE IF NOT EXISTS user_quotas (
+    user_id          INTEGER PRIMARY KEY,
+    max_active_jobs  INTEGER NOT NULL DEFAULT 0 CHECK (max_active_jobs >= 0),
+    max_upload_bytes BIGINT NOT NULL DEFAULT 0 CHECK (max_upload_bytes >= 0),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
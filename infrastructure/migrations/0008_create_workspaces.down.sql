@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_video_processing_statuses_workspace_created;
+
+ALTER TABLE video_processing_statuses
+    DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_members;
+
+DROP TABLE IF EXISTS workspaces;
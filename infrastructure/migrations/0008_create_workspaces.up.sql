@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id         SERIAL PRIMARY KEY,
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id INTEGER NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    user_id      INTEGER NOT NULL,
+    role         VARCHAR(16) NOT NULL CHECK (role IN ('viewer', 'member', 'owner')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user
+    ON workspace_members (user_id);
+
+ALTER TABLE video_processing_statuses
+    ADD COLUMN IF NOT EXISTS workspace_id INTEGER REFERENCES workspaces (id);
+
+CREATE INDEX IF NOT EXISTS idx_video_processing_statuses_workspace_created
+    ON video_processing_statuses (workspace_id, created_at);
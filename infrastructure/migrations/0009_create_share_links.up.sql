@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS share_links (
+    id             SERIAL PRIMARY KEY,
+    video_id       INTEGER NOT NULL REFERENCES video_processing_statuses (id) ON DELETE CASCADE,
+    created_by     INTEGER NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL,
+    max_downloads  INTEGER NOT NULL DEFAULT 0 CHECK (max_downloads >= 0),
+    download_count INTEGER NOT NULL DEFAULT 0,
+    revoked_at     TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_video
+    ON share_links (video_id);
+
+CREATE TABLE IF NOT EXISTS share_link_accesses (
+    id            SERIAL PRIMARY KEY,
+    share_link_id INTEGER NOT NULL REFERENCES share_links (id) ON DELETE CASCADE,
+    remote_addr   TEXT NOT NULL DEFAULT '',
+    user_agent    TEXT NOT NULL DEFAULT '',
+    outcome       VARCHAR(32) NOT NULL,
+    accessed_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_link_accesses_link
+    ON share_link_accesses (share_link_id, accessed_at);
@@ -0,0 +1,2 @@
+ALTER TABLE video_processing_statuses
+    ADD COLUMN IF NOT EXISTS processed_file_checksum CHAR(64);
@@ -0,0 +1,2 @@
+ALTER TABLE video_processing_statuses
+    DROP COLUMN IF EXISTS artifact_layout;
@@ -0,0 +1,2 @@
+ALTER TABLE video_processing_statuses
+    ADD COLUMN IF NOT EXISTS artifact_layout VARCHAR(16) NOT NULL DEFAULT 'zip';
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS video_frames (
+    artifact_path TEXT NOT NULL,
+    frame_index   INTEGER NOT NULL CHECK (frame_index > 0),
+    timestamp_ms  BIGINT NOT NULL CHECK (timestamp_ms >= 0),
+    name          TEXT NOT NULL,
+    path          TEXT,
+    size_bytes    BIGINT NOT NULL,
+    width         INTEGER NOT NULL,
+    height        INTEGER NOT NULL,
+    PRIMARY KEY (artifact_path, frame_index)
+);
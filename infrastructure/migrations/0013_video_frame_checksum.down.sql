@@ -0,0 +1,2 @@
+ALTER TABLE video_frames
+    DROP COLUMN IF EXISTS checksum;
@@ -0,0 +1,2 @@
+ALTER TABLE video_frames
+    ADD COLUMN IF NOT EXISTS checksum CHAR(64);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS video_outputs (
+    artifact_path TEXT NOT NULL,
+    name          TEXT NOT NULL,
+    kind          VARCHAR(32) NOT NULL,
+    path          TEXT NOT NULL,
+    content_type  TEXT NOT NULL,
+    size_bytes    BIGINT NOT NULL,
+    checksum      CHAR(64) NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (artifact_path, name)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE video_outputs
+    DROP COLUMN IF EXISTS language;
+
+ALTER TABLE video_processing_statuses
+    DROP COLUMN IF EXISTS subtitle_streams;
@@ -0,0 +1,5 @@
+ALTER TABLE video_processing_statuses
+    ADD COLUMN IF NOT EXISTS subtitle_streams JSONB;
+
+ALTER TABLE video_outputs
+    ADD COLUMN IF NOT EXISTS language TEXT;
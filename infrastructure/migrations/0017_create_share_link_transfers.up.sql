@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS share_link_transfers (
+    token         TEXT PRIMARY KEY,
+    share_link_id INTEGER NOT NULL REFERENCES share_links (id) ON DELETE CASCADE,
+    checksum      TEXT NOT NULL,
+    size          BIGINT NOT NULL,
+    sent          BIGINT NOT NULL DEFAULT 0,
+    resent        BIGINT NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_link_transfers_link
+    ON share_link_transfers (share_link_id);
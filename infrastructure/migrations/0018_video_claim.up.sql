@@ -0,0 +1,2 @@
+ALTER TABLE video_processing_statuses
+    ADD COLUMN IF NOT EXISTS claim INTEGER NOT NULL DEFAULT 0;
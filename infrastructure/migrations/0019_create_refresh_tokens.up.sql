@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id           SERIAL PRIMARY KEY,
+    family_id    CHAR(32) NOT NULL,
+    user_id      INTEGER NOT NULL,
+    username     TEXT NOT NULL DEFAULT '',
+    role         TEXT NOT NULL,
+    workspace_id INTEGER,
+    token_hash   CHAR(64) NOT NULL UNIQUE,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    rotated_at   TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family
+    ON refresh_tokens (family_id);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user
+    ON refresh_tokens (user_id);
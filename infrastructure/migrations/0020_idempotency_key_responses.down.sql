@@ -0,0 +1,3 @@
+ALTER TABLE idempotency_keys
+    DROP COLUMN IF EXISTS deduplicated,
+    DROP COLUMN IF EXISTS message;
@@ -0,0 +1,80 @@
+// infrastructure/output_handlers.go
+package infrastructure
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitovidale/video-processor-service/usecase"
+)
+
+type OutputHandlers struct {
+	ListOutputsUC *usecase.ListOutputsUseCase
+	GetOutputUC   *usecase.GetOutputUseCase
+}
+
+type OutputResponse struct {
+	Name        string    `json:"name"`
+	Kind        string    `json:"kind"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	SHA256      string    `json:"sha256"`
+	Language    string    `json:"language,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NewOutputHandlers(listUC *usecase.ListOutputsUseCase, getUC *usecase.GetOutputUseCase) *OutputHandlers {
+	return &OutputHandlers{ListOutputsUC: listUC, GetOutputUC: getUC}
+}
+
+func (h *OutputHandlers) ListOutputsHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+
+	outputs, err := h.ListOutputsUC.Execute(c.Request.Context(), userID, videoID)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	response := make([]OutputResponse, 0, len(outputs))
+	for _, o := range outputs {
+		response = append(response, OutputResponse{
+			Name:        o.Name,
+			Kind:        string(o.Kind),
+			ContentType: o.ContentType,
+			SizeBytes:   o.Size,
+			SHA256:      o.Checksum,
+			Language:    o.Language,
+			CreatedAt:   o.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetOutputHandler serves one output inline, so players can load sprite
+// sheets and tracks straight from it, with the same Range and validator
+// support as downloads.
+func (h *OutputHandlers) GetOutputHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+
+	output, file, err := h.GetOutputUC.Execute(c.Request.Context(), userID, videoID, c.Param("name"))
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", output.ContentType)
+	serveStoredFile(c, file, "inline")
+}
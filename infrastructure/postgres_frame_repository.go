@@ -0,0 +1,74 @@
+// infrastructure/postgres_frame_repository.go
+package infrastructure
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type PostgresFrameRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresFrameRepository(db *sql.DB) *PostgresFrameRepository {
+	return &PostgresFrameRepository{DB: db}
+}
+
+func (r *PostgresFrameRepository) ReplaceFrames(artifactPath string, frames []domain.Frame) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM video_frames WHERE artifact_path = $1`, artifactPath); err != nil {
+		return fmt.Errorf("failed to clear frames: %w", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO video_frames (artifact_path, frame_index, timestamp_ms, name, path, size_bytes, width, height, checksum)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, NULLIF($9, ''))`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare frame insert: %w", err)
+	}
+	defer stmt.Close()
+	for _, f := range frames {
+		_, err := stmt.Exec(artifactPath, f.Index, f.Timestamp.Milliseconds(), f.Name, f.Path, f.Size, f.Width, f.Height, f.Checksum)
+		if err != nil {
+			return fmt.Errorf("failed to record frame %d: %w", f.Index, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresFrameRepository) FindByArtifact(artifactPath string) ([]domain.Frame, error) {
+	rows, err := r.DB.Query(`SELECT frame_index, timestamp_ms, name, path, size_bytes, width, height, checksum
+		FROM video_frames WHERE artifact_path = $1 ORDER BY frame_index`, artifactPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query frames: %w", err)
+	}
+	defer rows.Close()
+
+	var frames []domain.Frame
+	for rows.Next() {
+		var f domain.Frame
+		var timestampMs int64
+		var path, checksum sql.NullString
+		if err := rows.Scan(&f.Index, &timestampMs, &f.Name, &path, &f.Size, &f.Width, &f.Height, &checksum); err != nil {
+			return nil, fmt.Errorf("failed to scan frame: %w", err)
+		}
+		f.Timestamp = time.Duration(timestampMs) * time.Millisecond
+		f.Path = path.String
+		f.Checksum = checksum.String
+		frames = append(frames, f)
+	}
+	return frames, rows.Err()
+}
+
+func (r *PostgresFrameRepository) DeleteByArtifact(artifactPath string) error {
+	if _, err := r.DB.Exec(`DELETE FROM video_frames WHERE artifact_path = $1`, artifactPath); err != nil {
+		return fmt.Errorf("failed to delete frames: %w", err)
+	}
+	return nil
+}
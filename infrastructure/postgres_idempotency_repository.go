@@ -0,0 +1,59 @@
+// infrastructure/postgres_idempotency_repository.go
+package infrastructure
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type PostgresIdempotencyRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresIdempotencyRepository(db *sql.DB) *PostgresIdempotencyRepository {
+	return &PostgresIdempotencyRepository{DB: db}
+}
+
+func (r *PostgresIdempotencyRepository) Reserve(record *domain.IdempotencyRecord, window, claimTimeout time.Duration) (*domain.IdempotencyRecord, error) {
+	// Insert, or take over a row whose window has expired or whose request
+	// died before recording a video. No row back means a live record
+	// already holds the key.
+	query := `INSERT INTO idempotency_keys (user_id, key, request_hash, filename) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, key) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash, filename = EXCLUDED.filename, video_status_id = NULL, message = '', deduplicated = FALSE, created_at = NOW()
+			WHERE idempotency_keys.created_at < NOW() - make_interval(secs => $5)
+				OR (idempotency_keys.video_status_id IS NULL AND idempotency_keys.created_at < NOW() - make_interval(secs => $6))
+		RETURNING created_at`
+	err := r.DB.QueryRow(query, record.UserID, record.Key, record.RequestHash, record.Filename, window.Seconds(), claimTimeout.Seconds()).Scan(&record.CreatedAt)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	var existing domain.IdempotencyRecord
+	var videoStatusID sql.NullInt64
+	err = r.DB.QueryRow(`SELECT user_id, key, request_hash, video_status_id, filename, message, deduplicated, created_at FROM idempotency_keys WHERE user_id = $1 AND key = $2`, record.UserID, record.Key).
+		Scan(&existing.UserID, &existing.Key, &existing.RequestHash, &videoStatusID, &existing.Filename, &existing.Message, &existing.Deduplicated, &existing.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+	}
+	existing.VideoStatusID = int(videoStatusID.Int64)
+	return &existing, nil
+}
+
+func (r *PostgresIdempotencyRepository) Complete(record *domain.IdempotencyRecord) error {
+	_, err := r.DB.Exec(`UPDATE idempotency_keys SET video_status_id = $1, message = $2, deduplicated = $3 WHERE user_id = $4 AND key = $5`,
+		record.VideoStatusID, record.Message, record.Deduplicated, record.UserID, record.Key)
+	return err
+}
+
+func (r *PostgresIdempotencyRepository) Release(userID int, key string) error {
+	_, err := r.DB.Exec(`DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND video_status_id IS NULL`, userID, key)
+	return err
+}
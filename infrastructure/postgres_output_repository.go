@@ -0,0 +1,67 @@
+// infrastructure/postgres_output_repository.go
+package infrastructure
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type PostgresOutputRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresOutputRepository(db *sql.DB) *PostgresOutputRepository {
+	return &PostgresOutputRepository{DB: db}
+}
+
+func (r *PostgresOutputRepository) ReplaceOutputs(artifactPath string, outputs []domain.Output) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM video_outputs WHERE artifact_path = $1`, artifactPath); err != nil {
+		return fmt.Errorf("failed to clear outputs: %w", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO video_outputs (artifact_path, name, kind, path, content_type, size_bytes, checksum, language)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare output insert: %w", err)
+	}
+	defer stmt.Close()
+	for _, o := range outputs {
+		if _, err := stmt.Exec(artifactPath, o.Name, o.Kind, o.Path, o.ContentType, o.Size, o.Checksum, o.Language); err != nil {
+			return fmt.Errorf("failed to record output %s: %w", o.Name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresOutputRepository) FindByArtifact(artifactPath string) ([]domain.Output, error) {
+	rows, err := r.DB.Query(`SELECT name, kind, path, content_type, size_bytes, checksum, COALESCE(language, ''), created_at
+		FROM video_outputs WHERE artifact_path = $1 ORDER BY name`, artifactPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outputs: %w", err)
+	}
+	defer rows.Close()
+
+	var outputs []domain.Output
+	for rows.Next() {
+		var o domain.Output
+		if err := rows.Scan(&o.Name, &o.Kind, &o.Path, &o.ContentType, &o.Size, &o.Checksum, &o.Language, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan output: %w", err)
+		}
+		outputs = append(outputs, o)
+	}
+	return outputs, rows.Err()
+}
+
+func (r *PostgresOutputRepository) DeleteByArtifact(artifactPath string) error {
+	if _, err := r.DB.Exec(`DELETE FROM video_outputs WHERE artifact_path = $1`, artifactPath); err != nil {
+		return fmt.Errorf("failed to delete outputs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// infrastructure/postgres_quota_repository.go
+package infrastructure
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type PostgresQuotaRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresQuotaRepository(db *sql.DB) *PostgresQuotaRepository {
+	return &PostgresQuotaRepository{DB: db}
+}
+
+func (r *PostgresQuotaRepository) Get(userID int) (*domain.UserQuota, error) {
+	q := domain.UserQuota{UserID: userID}
+	err := r.DB.QueryRow(`SELECT max_active_jobs, max_upload_bytes, updated_at FROM user_quotas WHERE user_id = $1`, userID).
+		Scan(&q.MaxActiveJobs, &q.MaxUploadBytes, &q.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user quota: %w", err)
+	}
+	return &q, nil
+}
+
+func (r *PostgresQuotaRepository) Set(quota *domain.UserQuota) error {
+	query := `INSERT INTO user_quotas (user_id, max_active_jobs, max_upload_bytes) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+			SET max_active_jobs = EXCLUDED.max_active_jobs, max_upload_bytes = EXCLUDED.max_upload_bytes, updated_at = NOW()
+		RETURNING updated_at`
+	return r.DB.QueryRow(query, quota.UserID, quota.MaxActiveJobs, quota.MaxUploadBytes).Scan(&quota.UpdatedAt)
+}
@@ -0,0 +1,90 @@
+// infrastructure/postgres_refresh_token_repository.go
+package infrastructure
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+const refreshTokenColumns = `id, family_id, user_id, username, role, COALESCE(workspace_id, 0), token_hash, expires_at, rotated_at, revoked_at, created_at`
+
+type PostgresRefreshTokenRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresRefreshTokenRepository(db *sql.DB) *PostgresRefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{DB: db}
+}
+
+func scanRefreshToken(row rowScanner) (*domain.RefreshToken, error) {
+	var t domain.RefreshToken
+	var rotatedAt, revokedAt sql.NullTime
+	if err := row.Scan(&t.ID, &t.FamilyID, &t.UserID, &t.Username, &t.Role, &t.WorkspaceID, &t.TokenHash, &t.ExpiresAt, &rotatedAt, &revokedAt, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	t.RotatedAt = nullTimePtr(rotatedAt)
+	t.RevokedAt = nullTimePtr(revokedAt)
+	return &t, nil
+}
+
+func (r *PostgresRefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	return insertRefreshToken(r.DB, token)
+}
+
+func insertRefreshToken(q queryRower, token *domain.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (family_id, user_id, username, role, workspace_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6, $7) RETURNING id, created_at`
+	return q.QueryRow(query, token.FamilyID, token.UserID, token.Username, token.Role, token.WorkspaceID, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+}
+
+func (r *PostgresRefreshTokenRepository) FindByHash(tokenHash string) (*domain.RefreshToken, error) {
+	token, err := scanRefreshToken(r.DB.QueryRow(`SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE token_hash = $1`, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refresh token: %w", err)
+	}
+	return token, nil
+}
+
+func (r *PostgresRefreshTokenRepository) Rotate(tokenID int, next *domain.RefreshToken) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Concurrent exchanges of the same token wait on the row lock; only
+	// the first finds it unrotated.
+	result, err := tx.Exec(`UPDATE refresh_tokens SET rotated_at = NOW() WHERE id = $1 AND rotated_at IS NULL AND revoked_at IS NULL`, tokenID)
+	if err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.ErrInvalidRefreshToken
+	}
+	if err := insertRefreshToken(tx, next); err != nil {
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeFamily(familyID string) error {
+	if _, err := r.DB.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`, familyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeByUserID(userID int) error {
+	if _, err := r.DB.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
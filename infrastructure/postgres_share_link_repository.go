@@ -0,0 +1,162 @@
+// infrastructure/postgres_share_link_repository.go
+package infrastructure
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+const shareLinkColumns = `id, video_id, created_by, expires_at, max_downloads, download_count, revoked_at, created_at`
+
+type PostgresShareLinkRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresShareLinkRepository(db *sql.DB) *PostgresShareLinkRepository {
+	return &PostgresShareLinkRepository{DB: db}
+}
+
+func scanShareLink(row rowScanner) (*domain.ShareLink, error) {
+	var l domain.ShareLink
+	var revokedAt sql.NullTime
+	if err := row.Scan(&l.ID, &l.VideoID, &l.CreatedBy, &l.ExpiresAt, &l.MaxDownloads, &l.DownloadCount, &revokedAt, &l.CreatedAt); err != nil {
+		return nil, err
+	}
+	l.RevokedAt = nullTimePtr(revokedAt)
+	return &l, nil
+}
+
+func (r *PostgresShareLinkRepository) Create(link *domain.ShareLink) error {
+	query := `INSERT INTO share_links (video_id, created_by, expires_at, max_downloads) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	return r.DB.QueryRow(query, link.VideoID, link.CreatedBy, link.ExpiresAt, link.MaxDownloads).Scan(&link.ID, &link.CreatedAt)
+}
+
+func (r *PostgresShareLinkRepository) FindByID(linkID int) (*domain.ShareLink, error) {
+	link, err := scanShareLink(r.DB.QueryRow(`SELECT `+shareLinkColumns+` FROM share_links WHERE id = $1`, linkID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrShareLinkNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query share link: %w", err)
+	}
+	return link, nil
+}
+
+func (r *PostgresShareLinkRepository) FindByVideoID(videoID int) ([]domain.ShareLink, error) {
+	rows, err := r.DB.Query(`SELECT `+shareLinkColumns+` FROM share_links WHERE video_id = $1 ORDER BY created_at DESC, id DESC`, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query share links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []domain.ShareLink
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share link: %w", err)
+		}
+		links = append(links, *link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over share links: %w", err)
+	}
+	return links, nil
+}
+
+func (r *PostgresShareLinkRepository) Revoke(videoID, linkID int, at time.Time) error {
+	result, err := r.DB.Exec(`UPDATE share_links SET revoked_at = COALESCE(revoked_at, $1) WHERE id = $2 AND video_id = $3`, at, linkID, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.ErrShareLinkNotFound
+	}
+	return nil
+}
+
+func (r *PostgresShareLinkRepository) ConsumeDownload(linkID int, now time.Time) (*domain.ShareLink, error) {
+	query := `UPDATE share_links SET download_count = download_count + 1
+		WHERE id = $1 AND revoked_at IS NULL AND expires_at > $2 AND (max_downloads = 0 OR download_count < max_downloads)
+		RETURNING ` + shareLinkColumns
+	link, err := scanShareLink(r.DB.QueryRow(query, linkID, now))
+	if err == nil {
+		return link, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to count share link download: %w", err)
+	}
+
+	// Nothing matched: find out why.
+	link, err = r.FindByID(linkID)
+	if err != nil {
+		return nil, err
+	}
+	if err := link.Check(now); err != nil {
+		return nil, err
+	}
+	return nil, domain.ErrShareLinkExhausted
+}
+
+func (r *PostgresShareLinkRepository) CreateTransfer(transfer *domain.ShareTransfer) error {
+	query := `INSERT INTO share_link_transfers (token, share_link_id, checksum, size) VALUES ($1, $2, $3, $4) RETURNING created_at`
+	return r.DB.QueryRow(query, transfer.Token, transfer.ShareLinkID, transfer.Checksum, transfer.Size).Scan(&transfer.CreatedAt)
+}
+
+// ResumeTransfer mirrors ShareTransfer.CanResume in its WHERE clause.
+func (r *PostgresShareLinkRepository) ResumeTransfer(linkID int, token, checksum string, start int64) (*domain.ShareTransfer, error) {
+	query := `UPDATE share_link_transfers SET resent = resent + sent - $4
+		WHERE token = $1 AND share_link_id = $2 AND checksum = $3 AND sent < size
+			AND $4 > 0 AND $4 <= sent AND resent + sent - $4 <= $5
+		RETURNING token, share_link_id, checksum, size, sent, resent, created_at`
+	var t domain.ShareTransfer
+	err := r.DB.QueryRow(query, token, linkID, checksum, start, domain.ShareResumeBudget).
+		Scan(&t.Token, &t.ShareLinkID, &t.Checksum, &t.Size, &t.Sent, &t.Resent, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrShareTransferNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume share link transfer: %w", err)
+	}
+	return &t, nil
+}
+
+func (r *PostgresShareLinkRepository) AdvanceTransfer(linkID int, token string, start, end int64) error {
+	query := `UPDATE share_link_transfers SET sent = LEAST(size, GREATEST(sent, $4))
+		WHERE token = $1 AND share_link_id = $2 AND sent >= $3`
+	if _, err := r.DB.Exec(query, token, linkID, start, end); err != nil {
+		return fmt.Errorf("failed to record share link transfer progress: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresShareLinkRepository) LogAccess(access *domain.ShareAccess) error {
+	query := `INSERT INTO share_link_accesses (share_link_id, remote_addr, user_agent, outcome) VALUES ($1, $2, $3, $4) RETURNING id, accessed_at`
+	return r.DB.QueryRow(query, access.ShareLinkID, access.RemoteAddr, access.UserAgent, access.Outcome).Scan(&access.ID, &access.AccessedAt)
+}
+
+func (r *PostgresShareLinkRepository) FindAccesses(linkID int) ([]domain.ShareAccess, error) {
+	rows, err := r.DB.Query(`SELECT id, remote_addr, user_agent, outcome, accessed_at FROM share_link_accesses WHERE share_link_id = $1 ORDER BY accessed_at DESC, id DESC`, linkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query share link accesses: %w", err)
+	}
+	defer rows.Close()
+
+	var accesses []domain.ShareAccess
+	for rows.Next() {
+		a := domain.ShareAccess{ShareLinkID: linkID}
+		if err := rows.Scan(&a.ID, &a.RemoteAddr, &a.UserAgent, &a.Outcome, &a.AccessedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan share link access: %w", err)
+		}
+		accesses = append(accesses, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over share link accesses: %w", err)
+	}
+	return accesses, nil
+}
@@ -0,0 +1,53 @@
+// infrastructure/postgres_video_event_repository.go
+package infrastructure
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type PostgresVideoEventRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresVideoEventRepository(db *sql.DB) *PostgresVideoEventRepository {
+	return &PostgresVideoEventRepository{DB: db}
+}
+
+func (r *PostgresVideoEventRepository) Append(event *domain.VideoEvent) error {
+	query := `INSERT INTO video_events (video_id, from_status, to_status, worker_id, attempt, duration_ms, error_message) VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), $5, $6, NULLIF($7, '')) RETURNING id, created_at`
+	return r.DB.QueryRow(query,
+		event.VideoID, string(event.FromStatus), string(event.ToStatus), event.WorkerID,
+		event.Attempt, event.Duration.Milliseconds(), event.ErrorMessage,
+	).Scan(&event.ID, &event.CreatedAt)
+}
+
+func (r *PostgresVideoEventRepository) FindByVideoID(videoID int) ([]domain.VideoEvent, error) {
+	rows, err := r.DB.Query(`SELECT id, video_id, from_status, to_status, worker_id, attempt, duration_ms, error_message, created_at FROM video_events WHERE video_id = $1 ORDER BY created_at, id`, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query video events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.VideoEvent
+	for rows.Next() {
+		var e domain.VideoEvent
+		var fromStatus, workerID, errorMessage sql.NullString
+		var durationMs int64
+		if err := rows.Scan(&e.ID, &e.VideoID, &fromStatus, &e.ToStatus, &workerID, &e.Attempt, &durationMs, &errorMessage, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan video event: %w", err)
+		}
+		e.FromStatus = domain.VideoStatus(fromStatus.String)
+		e.WorkerID = workerID.String
+		e.ErrorMessage = errorMessage.String
+		e.Duration = time.Duration(durationMs) * time.Millisecond
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over video events: %w", err)
+	}
+	return events, nil
+}
@@ -2,69 +2,410 @@
 package infrastructure
 
 import (
-    "database/sql"
-    "fmt"
-    "your_project/domain"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/vitovidale/video-processor-service/domain"
 )
 
+const videoColumns = `id, user_id, workspace_id, video_original_filename, status, processed_file_path, processed_file_checksum, artifact_layout, error_message, claim, content_hash, extraction_settings, reused_from_video_id, source_path, audio_analysis, subtitle_streams, created_at, updated_at`
+
 type PostgresVideoRepository struct {
-    DB *sql.DB
+	DB *sql.DB
 }
 
 func NewPostgresVideoRepository(db *sql.DB) *PostgresVideoRepository {
-    return &PostgresVideoRepository{DB: db}
+	return &PostgresVideoRepository{DB: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanVideo(row rowScanner) (*domain.Video, error) {
+	var v domain.Video
+	var processedFilePath, checksum, errorMessage, contentHash, sourcePath sql.NullString
+	var settings, audio, subtitles []byte
+	var workspaceID, reusedFrom sql.NullInt64
+	err := row.Scan(
+		&v.ID, &v.UserID, &workspaceID, &v.OriginalFilename, &v.Status,
+		&processedFilePath, &checksum, &v.ArtifactLayout, &errorMessage, &v.Claim, &contentHash, &settings, &reusedFrom, &sourcePath, &audio, &subtitles,
+		&v.CreatedAt, &v.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	v.WorkspaceID = int(workspaceID.Int64)
+	v.ProcessedFilePath = processedFilePath.String
+	v.ProcessedFileChecksum = checksum.String
+	v.ErrorMessage = errorMessage.String
+	v.ContentHash = contentHash.String
+	v.ReusedFromVideoID = int(reusedFrom.Int64)
+	v.SourcePath = sourcePath.String
+	if err := json.Unmarshal(settings, &v.ExtractionSettings); err != nil {
+		return nil, fmt.Errorf("invalid extraction_settings for video %d: %w", v.ID, err)
+	}
+	if audio != nil {
+		v.Audio = &domain.AudioAnalysis{}
+		if err := json.Unmarshal(audio, v.Audio); err != nil {
+			return nil, fmt.Errorf("invalid audio_analysis for video %d: %w", v.ID, err)
+		}
+	}
+	if subtitles != nil {
+		if err := json.Unmarshal(subtitles, &v.SubtitleStreams); err != nil {
+			return nil, fmt.Errorf("invalid subtitle_streams for video %d: %w", v.ID, err)
+		}
+	}
+	return &v, nil
+}
+
+// queryRower is satisfied by *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
 }
 
 func (r *PostgresVideoRepository) Save(video *domain.Video) error {
-    query := `INSERT INTO video_processing_statuses (user_id, video_original_filename, status) VALUES ($1, $2, $3) RETURNING id`
-    err := r.DB.QueryRow(query, video.UserID, video.OriginalFilename, video.Status).Scan(&video.ID)
-    return err
+	return insertVideo(r.DB, video)
+}
+
+// quotaLockClass namespaces the per-user advisory locks SaveWithinQuota
+// takes; the second key is the user ID.
+const quotaLockClass = 0x71756f74 // "quot"
+
+func (r *PostgresVideoRepository) SaveWithinQuota(video *domain.Video, maxActive int) error {
+	if maxActive <= 0 {
+		return r.Save(video)
+	}
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Concurrent uploads of the user wait here until this transaction ends,
+	// so none can insert between the count and the insert below.
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1, $2)`, quotaLockClass, video.UserID); err != nil {
+		return fmt.Errorf("failed to lock quota: %w", err)
+	}
+	var active int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM video_processing_statuses WHERE user_id = $1 AND status IN ('PENDING', 'PROCESSING')`, video.UserID).Scan(&active)
+	if err != nil {
+		return fmt.Errorf("failed to count active videos: %w", err)
+	}
+	if active >= maxActive {
+		return domain.ErrQuotaExceeded
+	}
+	if err := insertVideo(tx, video); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresVideoRepository) RequeueWithinQuota(videoID, maxActive int) error {
+	if maxActive <= 0 {
+		return r.TransitionStatus(videoID, domain.VideoStatusFailed, domain.VideoStatusPending, "", "")
+	}
+	var userID int
+	err := r.DB.QueryRow(`SELECT user_id FROM video_processing_statuses WHERE id = $1`, videoID).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrVideoNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find video: %w", err)
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1, $2)`, quotaLockClass, userID); err != nil {
+		return fmt.Errorf("failed to lock quota: %w", err)
+	}
+	var active int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM video_processing_statuses WHERE user_id = $1 AND status IN ('PENDING', 'PROCESSING')`, userID).Scan(&active)
+	if err != nil {
+		return fmt.Errorf("failed to count active videos: %w", err)
+	}
+	if active >= maxActive {
+		return domain.ErrQuotaExceeded
+	}
+	result, err := tx.Exec(`UPDATE video_processing_statuses SET status = 'PENDING', processed_file_path = '', error_message = '', updated_at = NOW(),
+		processed_file_checksum = NULL
+		WHERE id = $1 AND status = 'FAILED'`, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to update video status: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update video status: %w", err)
+	}
+	if rows != 1 {
+		return r.transitionMissed(videoID, domain.VideoStatusPending)
+	}
+	return tx.Commit()
+}
+
+func insertVideo(q queryRower, video *domain.Video) error {
+	settings, err := json.Marshal(video.ExtractionSettings.OrDefault())
+	if err != nil {
+		return err
+	}
+	// A nil interface, unlike a nil slice, is stored as NULL.
+	var audio, subtitles any
+	if video.Audio != nil {
+		if audio, err = json.Marshal(video.Audio); err != nil {
+			return err
+		}
+	}
+	if len(video.SubtitleStreams) > 0 {
+		if subtitles, err = json.Marshal(video.SubtitleStreams); err != nil {
+			return err
+		}
+	}
+	query := `INSERT INTO video_processing_statuses (user_id, video_original_filename, status, processed_file_path, content_hash, extraction_settings, reused_from_video_id, source_path, workspace_id, processed_file_checksum, artifact_layout, audio_analysis, subtitle_streams)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, NULLIF($7, 0), NULLIF($8, ''), NULLIF($9, 0), NULLIF($10, ''), $11, $12, $13) RETURNING id, created_at, updated_at`
+	return q.QueryRow(query,
+		video.UserID, video.OriginalFilename, video.Status, video.ProcessedFilePath,
+		video.ContentHash, settings, video.ReusedFromVideoID, video.SourcePath, video.WorkspaceID, video.ProcessedFileChecksum, video.ArtifactLayout.OrDefault(), audio, subtitles,
+	).Scan(&video.ID, &video.CreatedAt, &video.UpdatedAt)
+}
+
+func (r *PostgresVideoRepository) TransitionStatus(videoID int, from, to domain.VideoStatus, processedFilePath, errorMessage string) error {
+	if err := domain.ValidateTransition(videoID, from, to); err != nil {
+		return err
+	}
+
+	// A checksum only survives into COMPLETED; it was recorded for the
+	// artifact the job just produced.
+	query := `UPDATE video_processing_statuses SET status = $1, processed_file_path = $2, error_message = $3, updated_at = NOW(),
+		processed_file_checksum = CASE WHEN $1 = 'COMPLETED' THEN processed_file_checksum END,
+		claim = CASE WHEN $5 = 'PROCESSING' THEN claim + 1 ELSE claim END
+		WHERE id = $4 AND status = $5`
+	result, err := r.DB.Exec(query, to, processedFilePath, errorMessage, videoID, from)
+	if err != nil {
+		return fmt.Errorf("failed to update video status: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update video status: %w", err)
+	}
+	if rows == 1 {
+		return nil
+	}
+	return r.transitionMissed(videoID, to)
+}
+
+func (r *PostgresVideoRepository) ClaimVideo(videoID int) (int, error) {
+	query := `UPDATE video_processing_statuses SET status = 'PROCESSING', processed_file_path = '', error_message = '', updated_at = NOW(),
+		processed_file_checksum = NULL, claim = claim + 1
+		WHERE id = $1 AND status = 'PENDING' RETURNING claim`
+	var claim int
+	err := r.DB.QueryRow(query, videoID).Scan(&claim)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, r.transitionMissed(videoID, domain.VideoStatusProcessing)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim video: %w", err)
+	}
+	return claim, nil
+}
+
+func (r *PostgresVideoRepository) FinishClaim(videoID, claim int, to domain.VideoStatus, processedFilePath, errorMessage string) error {
+	if err := domain.ValidateTransition(videoID, domain.VideoStatusProcessing, to); err != nil {
+		return err
+	}
+	query := `UPDATE video_processing_statuses SET status = $1, processed_file_path = $2, error_message = $3, updated_at = NOW(),
+		processed_file_checksum = CASE WHEN $1 = 'COMPLETED' THEN processed_file_checksum END
+		WHERE id = $4 AND status = 'PROCESSING' AND claim = $5`
+	result, err := r.DB.Exec(query, to, processedFilePath, errorMessage, videoID, claim)
+	if err != nil {
+		return fmt.Errorf("failed to update video status: %w", err)
+	}
+	return r.claimedWrite(result, videoID)
 }
 
-func (r *PostgresVideoRepository) UpdateStatus(videoID int, status domain.VideoStatus, processedFilePath, errorMessage string) error {
-    query := `UPDATE video_processing_statuses SET status = $1, processed_file_path = $2, error_message = $3, updated_at = NOW() WHERE id = $4`
-    _, err := r.DB.Exec(query, status, processedFilePath, errorMessage, videoID)
-    return err
+// transitionMissed explains why a transition to to matched nothing: either
+// the video is gone or someone else moved it.
+func (r *PostgresVideoRepository) transitionMissed(videoID int, to domain.VideoStatus) error {
+	var current domain.VideoStatus
+	err := r.DB.QueryRow(`SELECT status FROM video_processing_statuses WHERE id = $1`, videoID).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrVideoNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read current video status: %w", err)
+	}
+	return &domain.InvalidTransitionError{VideoID: videoID, From: current, To: to}
+}
+
+// claimedWrite checks the result of a write guarded by a claim: when it
+// matched nothing, the video is gone or the claim was lost.
+func (r *PostgresVideoRepository) claimedWrite(result sql.Result, videoID int) error {
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 1 {
+		return nil
+	}
+	var exists bool
+	if err := r.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM video_processing_statuses WHERE id = $1)`, videoID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up video: %w", err)
+	}
+	if !exists {
+		return domain.ErrVideoNotFound
+	}
+	return domain.ErrClaimLost
 }
 
 func (r *PostgresVideoRepository) FindByID(videoID int) (*domain.Video, error) {
-    var v domain.Video
-    var processedFilePath, errorMessage sql.NullString
-    query := `SELECT id, user_id, video_original_filename, status, processed_file_path, error_message, created_at, updated_at FROM video_processing_statuses WHERE id = $1`
-    err := r.DB.QueryRow(query, videoID).Scan(
-        &v.ID, &v.UserID, &v.OriginalFilename, &v.Status,
-        &processedFilePath, &errorMessage, &v.CreatedAt, &v.UpdatedAt,
-    )
-    if err != nil {
-        return nil, err
-    }
-    v.ProcessedFilePath = processedFilePath.String
-    v.ErrorMessage = errorMessage.String
-    return &v, nil
+	v, err := scanVideo(r.DB.QueryRow(`SELECT `+videoColumns+` FROM video_processing_statuses WHERE id = $1`, videoID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrVideoNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query video status: %w", err)
+	}
+	return v, nil
 }
 
 func (r *PostgresVideoRepository) FindByUserID(userID int) ([]domain.Video, error) {
-    rows, err := r.DB.Query(`SELECT id, video_original_filename, status, processed_file_path, error_message, created_at, updated_at FROM video_processing_statuses WHERE user_id = $1 ORDER BY created_at DESC`, userID)
-    if err != nil {
-        return nil, fmt.Errorf("failed to query video statuses: %w", err)
-    }
-    defer rows.Close()
-
-    var videos []domain.Video
-    for rows.Next() {
-        var v domain.Video
-        var processedFilePath, errorMessage sql.NullString
-        err := rows.Scan(&v.ID, &v.OriginalFilename, &v.Status, &processedFilePath, &errorMessage, &v.CreatedAt, &v.UpdatedAt)
-        if err != nil {
-            log.Printf("Error scanning video status row: %v", err)
-            continue
-        }
-        v.ProcessedFilePath = processedFilePath.String
-        v.ErrorMessage = errorMessage.String
-        videos = append(videos, v)
-    }
-    if err = rows.Err(); err != nil {
-        return nil, fmt.Errorf("error iterating over video statuses: %w", err)
-    }
-    return videos, nil
+	rows, err := r.DB.Query(`SELECT `+videoColumns+` FROM video_processing_statuses WHERE user_id = $1 AND workspace_id IS NULL ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query video statuses: %w", err)
+	}
+	return scanVideos(rows)
+}
+
+func (r *PostgresVideoRepository) FindByWorkspaceID(workspaceID int) ([]domain.Video, error) {
+	rows, err := r.DB.Query(`SELECT `+videoColumns+` FROM video_processing_statuses WHERE workspace_id = $1 ORDER BY created_at DESC`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query video statuses: %w", err)
+	}
+	return scanVideos(rows)
+}
+
+func (r *PostgresVideoRepository) FindAll(filter domain.VideoFilter) ([]domain.Video, error) {
+	query := `SELECT ` + videoColumns + ` FROM video_processing_statuses
+		WHERE ($1 = 0 OR user_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, id DESC LIMIT NULLIF($3, 0) OFFSET $4`
+	rows, err := r.DB.Query(query, filter.UserID, string(filter.Status), filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query video statuses: %w", err)
+	}
+	return scanVideos(rows)
+}
+
+func scanVideos(rows *sql.Rows) ([]domain.Video, error) {
+	defer rows.Close()
+
+	var videos []domain.Video
+	for rows.Next() {
+		v, err := scanVideo(rows)
+		if err != nil {
+			slog.Error("failed to scan video status row", "error", err)
+			continue
+		}
+		videos = append(videos, *v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over video statuses: %w", err)
+	}
+	return videos, nil
+}
+
+func (r *PostgresVideoRepository) FindCompletedByContentHash(contentHash string, settings domain.ExtractionSettings, userID int) (*domain.Video, error) {
+	settingsJSON, err := json.Marshal(settings.OrDefault())
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + videoColumns + ` FROM video_processing_statuses
+		WHERE status = 'COMPLETED' AND content_hash = $1 AND extraction_settings = $2::jsonb AND ($3 = 0 OR user_id = $3)
+		ORDER BY created_at DESC LIMIT 1`
+	v, err := scanVideo(r.DB.QueryRow(query, contentHash, settingsJSON, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrVideoNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query video by content hash: %w", err)
+	}
+	return v, nil
+}
+
+func (r *PostgresVideoRepository) CountActiveByUserID(userID int) (int, error) {
+	var n int
+	err := r.DB.QueryRow(`SELECT COUNT(*) FROM video_processing_statuses WHERE user_id = $1 AND status IN ('PENDING', 'PROCESSING')`, userID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count active videos: %w", err)
+	}
+	return n, nil
+}
+
+func (r *PostgresVideoRepository) ProcessedFileInUse(path string, exceptVideoID int) (bool, error) {
+	var inUse bool
+	err := r.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM video_processing_statuses WHERE processed_file_path = $1 AND id <> $2)`, path, exceptVideoID).Scan(&inUse)
+	if err != nil {
+		return false, fmt.Errorf("failed to check artifact references: %w", err)
+	}
+	return inUse, nil
+}
+
+func (r *PostgresVideoRepository) Delete(videoID int) error {
+	result, err := r.DB.Exec(`DELETE FROM video_processing_statuses WHERE id = $1`, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to delete video: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.ErrVideoNotFound
+	}
+	return nil
+}
+
+func (r *PostgresVideoRepository) SetProcessedFileChecksum(videoID, claim int, checksum string) error {
+	result, err := r.DB.Exec(`UPDATE video_processing_statuses SET processed_file_checksum = $1 WHERE id = $2 AND claim = $3`, checksum, videoID, claim)
+	if err != nil {
+		return fmt.Errorf("failed to store artifact checksum: %w", err)
+	}
+	return r.claimedWrite(result, videoID)
+}
+
+func (r *PostgresVideoRepository) SetArtifactLayout(videoID, claim int, layout domain.ArtifactLayout) error {
+	result, err := r.DB.Exec(`UPDATE video_processing_statuses SET artifact_layout = $1 WHERE id = $2 AND claim = $3`, layout.OrDefault(), videoID, claim)
+	if err != nil {
+		return fmt.Errorf("failed to store artifact layout: %w", err)
+	}
+	return r.claimedWrite(result, videoID)
+}
+
+func (r *PostgresVideoRepository) SetAudioAnalysis(videoID, claim int, analysis domain.AudioAnalysis) error {
+	audio, err := json.Marshal(analysis)
+	if err != nil {
+		return err
+	}
+	result, err := r.DB.Exec(`UPDATE video_processing_statuses SET audio_analysis = $1 WHERE id = $2 AND claim = $3`, audio, videoID, claim)
+	if err != nil {
+		return fmt.Errorf("failed to store audio analysis: %w", err)
+	}
+	return r.claimedWrite(result, videoID)
+}
+
+func (r *PostgresVideoRepository) SetSubtitleStreams(videoID, claim int, streams []domain.SubtitleStream) error {
+	var subtitles any
+	if len(streams) > 0 {
+		data, err := json.Marshal(streams)
+		if err != nil {
+			return err
+		}
+		subtitles = data
+	}
+	result, err := r.DB.Exec(`UPDATE video_processing_statuses SET subtitle_streams = $1 WHERE id = $2 AND claim = $3`, subtitles, videoID, claim)
+	if err != nil {
+		return fmt.Errorf("failed to store subtitle streams: %w", err)
+	}
+	return r.claimedWrite(result, videoID)
 }
@@ -0,0 +1,116 @@
+// infrastructure/postgres_workspace_repository.go
+package infrastructure
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type PostgresWorkspaceRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresWorkspaceRepository(db *sql.DB) *PostgresWorkspaceRepository {
+	return &PostgresWorkspaceRepository{DB: db}
+}
+
+func (r *PostgresWorkspaceRepository) Create(workspace *domain.Workspace, ownerID int) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`INSERT INTO workspaces (name) VALUES ($1) RETURNING id, created_at`, workspace.Name).
+		Scan(&workspace.ID, &workspace.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create workspace: %w", err)
+	}
+	_, err = tx.Exec(`INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)`,
+		workspace.ID, ownerID, domain.WorkspaceRoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to add workspace owner: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresWorkspaceRepository) FindByUserID(userID int) ([]domain.WorkspaceMembership, error) {
+	query := `SELECT w.id, w.name, w.created_at, m.role FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1 ORDER BY w.name, w.id`
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	var memberships []domain.WorkspaceMembership
+	for rows.Next() {
+		var m domain.WorkspaceMembership
+		if err := rows.Scan(&m.Workspace.ID, &m.Workspace.Name, &m.Workspace.CreatedAt, &m.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		memberships = append(memberships, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over workspaces: %w", err)
+	}
+	return memberships, nil
+}
+
+func (r *PostgresWorkspaceRepository) FindMember(workspaceID, userID int) (*domain.WorkspaceMember, error) {
+	m := domain.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID}
+	err := r.DB.QueryRow(`SELECT role, created_at FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID).
+		Scan(&m.Role, &m.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotWorkspaceMember
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspace member: %w", err)
+	}
+	return &m, nil
+}
+
+func (r *PostgresWorkspaceRepository) ListMembers(workspaceID int) ([]domain.WorkspaceMember, error) {
+	rows, err := r.DB.Query(`SELECT user_id, role, created_at FROM workspace_members WHERE workspace_id = $1 ORDER BY created_at, user_id`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspace members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []domain.WorkspaceMember
+	for rows.Next() {
+		m := domain.WorkspaceMember{WorkspaceID: workspaceID}
+		if err := rows.Scan(&m.UserID, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace member: %w", err)
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over workspace members: %w", err)
+	}
+	return members, nil
+}
+
+func (r *PostgresWorkspaceRepository) SetMember(member *domain.WorkspaceMember) error {
+	query := `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING created_at`
+	return r.DB.QueryRow(query, member.WorkspaceID, member.UserID, member.Role).Scan(&member.CreatedAt)
+}
+
+func (r *PostgresWorkspaceRepository) RemoveMember(workspaceID, userID int) error {
+	result, err := r.DB.Exec(`DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove workspace member: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.ErrNotWorkspaceMember
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// infrastructure/prometheus_metrics.go
+package infrastructure
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+const metricsNamespace = "video_processor"
+
+// PrometheusMetrics implements domain.MetricsRecorder and instruments the
+// HTTP API. Each instance owns its registry, so several can coexist in tests.
+type PrometheusMetrics struct {
+	registry *prometheus.Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	uploadBytes         prometheus.Counter
+	jobsFinished        *prometheus.CounterVec
+	jobsInFlight        prometheus.Gauge
+	queueLag            prometheus.Histogram
+	ffmpegDuration      prometheus.Histogram
+	zipDuration         prometheus.Histogram
+	framesExtracted     prometheus.Histogram
+	queueReconnects     prometheus.Counter
+}
+
+func NewPrometheusMetrics() *PrometheusMetrics {
+	m := &PrometheusMetrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		uploadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "upload_bytes_total",
+			Help:      "Bytes received through video uploads.",
+		}),
+		jobsFinished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "jobs_finished_total",
+			Help:      "Processing jobs by final status.",
+		}, []string{"status"}),
+		jobsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "jobs_in_flight",
+			Help:      "Processing jobs currently running in this worker.",
+		}),
+		queueLag: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "queue_consume_lag_seconds",
+			Help:      "Time between a job being queued and a worker picking it up.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		}),
+		ffmpegDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "ffmpeg_duration_seconds",
+			Help:      "Duration of ffmpeg frame extraction.",
+			Buckets:   prometheus.ExponentialBuckets(0.5, 2, 12),
+		}),
+		zipDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "zip_duration_seconds",
+			Help:      "Duration of packing extracted frames into the ZIP artifact.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+		}),
+		framesExtracted: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "frames_extracted",
+			Help:      "Frames extracted per job.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+		}),
+		queueReconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rabbitmq_reconnects_total",
+			Help:      "Times the RabbitMQ connection had to be re-established.",
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpRequestDuration, m.uploadBytes,
+		m.jobsFinished, m.jobsInFlight, m.queueLag,
+		m.ffmpegDuration, m.zipDuration, m.framesExtracted, m.queueReconnects,
+	)
+	return m
+}
+
+// Middleware records request counts and latency per route template, so
+// /videos/1/download and /videos/2/download share one series.
+func (m *PrometheusMetrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.httpRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *PrometheusMetrics) Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+func (m *PrometheusMetrics) UploadReceived(bytes int64) {
+	m.uploadBytes.Add(float64(bytes))
+}
+
+func (m *PrometheusMetrics) JobStarted(queueLag time.Duration) {
+	m.jobsInFlight.Inc()
+	m.queueLag.Observe(queueLag.Seconds())
+}
+
+func (m *PrometheusMetrics) JobFinished(status domain.VideoStatus) {
+	m.jobsInFlight.Dec()
+	m.jobsFinished.WithLabelValues(string(status)).Inc()
+}
+
+func (m *PrometheusMetrics) FFmpegDuration(d time.Duration) {
+	m.ffmpegDuration.Observe(d.Seconds())
+}
+
+func (m *PrometheusMetrics) ZipDuration(d time.Duration) {
+	m.zipDuration.Observe(d.Seconds())
+}
+
+func (m *PrometheusMetrics) FramesExtracted(count int) {
+	m.framesExtracted.Observe(float64(count))
+}
+
+func (m *PrometheusMetrics) QueueReconnected() {
+	m.queueReconnects.Inc()
+}
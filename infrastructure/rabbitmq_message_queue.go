@@ -0,0 +1,269 @@
+// infrastructure/rabbitmq_message_queue.go
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/vitovidale/video-processor-service/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const VideoProcessingQueue = "video_processing_queue"
+
+// The reconnect delays bound the backoff between attempts to re-establish a lost
+// RabbitMQ connection.
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+var errQueueClosed = errors.New("message queue closed")
+
+// RabbitMQMessageQueue owns its AMQP connection and transparently redials it
+// when the broker drops it.
+type RabbitMQMessageQueue struct {
+	URL       string
+	QueueName string
+	Metrics   domain.MetricsRecorder
+
+	mu        sync.Mutex
+	conn      *amqp.Connection
+	dialed    bool
+	closed    bool
+	consuming atomic.Bool
+}
+
+func NewRabbitMQMessageQueue(url string) *RabbitMQMessageQueue {
+	return &RabbitMQMessageQueue{URL: url, QueueName: VideoProcessingQueue}
+}
+
+// Connect dials the broker if there is no open connection.
+func (q *RabbitMQMessageQueue) Connect() error {
+	_, err := q.connection()
+	return err
+}
+
+func (q *RabbitMQMessageQueue) connection() (*amqp.Connection, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, errQueueClosed
+	}
+	if q.conn != nil && !q.conn.IsClosed() {
+		return q.conn, nil
+	}
+	conn, err := amqp.Dial(q.URL)
+	if err != nil {
+		return nil, err
+	}
+	if q.dialed {
+		slog.Info("rabbitmq connection re-established")
+		if q.Metrics != nil {
+			q.Metrics.QueueReconnected()
+		}
+	}
+	q.conn = conn
+	q.dialed = true
+	return conn, nil
+}
+
+// Ping opens and closes a channel to verify the connection is usable.
+func (q *RabbitMQMessageQueue) Ping() error {
+	q.mu.Lock()
+	conn := q.conn
+	q.mu.Unlock()
+
+	if conn == nil || conn.IsClosed() {
+		return errors.New("disconnected")
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	return ch.Close()
+}
+
+// Close stops consumers and closes the connection for good.
+func (q *RabbitMQMessageQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	if q.conn != nil && !q.conn.IsClosed() {
+		return q.conn.Close()
+	}
+	return nil
+}
+
+// Consuming reports whether a consumer is currently registered on the queue.
+func (q *RabbitMQMessageQueue) Consuming() bool {
+	return q.consuming.Load()
+}
+
+func (q *RabbitMQMessageQueue) isClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+func (q *RabbitMQMessageQueue) declare(ch *amqp.Channel) (amqp.Queue, error) {
+	return ch.QueueDeclare(
+		q.QueueName,
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+}
+
+func (q *RabbitMQMessageQueue) PublishVideoProcessing(ctx context.Context, message domain.VideoProcessingMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	conn, err := q.connection()
+	if err != nil {
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open a channel: %w", err)
+	}
+	defer ch.Close()
+
+	queue, err := q.declare(ch)
+	if err != nil {
+		return fmt.Errorf("failed to declare a queue: %w", err)
+	}
+
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, amqpHeaderCarrier(headers))
+
+	err = ch.PublishWithContext(
+		ctx,
+		"",
+		queue.Name,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Headers:      headers,
+			Body:         body,
+		})
+	if err != nil {
+		return fmt.Errorf("failed to publish a message: %w", err)
+	}
+	return nil
+}
+
+// QueueStats reports the ready messages and consumers of the processing
+// queue without declaring it.
+func (q *RabbitMQMessageQueue) QueueStats(ctx context.Context) (domain.QueueStats, error) {
+	conn, err := q.connection()
+	if err != nil {
+		return domain.QueueStats{}, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return domain.QueueStats{}, fmt.Errorf("failed to open a channel: %w", err)
+	}
+	defer ch.Close()
+
+	queue, err := ch.QueueDeclarePassive(q.QueueName, true, false, false, false, nil)
+	if err != nil {
+		return domain.QueueStats{}, fmt.Errorf("failed to inspect queue: %w", err)
+	}
+	return domain.QueueStats{Name: queue.Name, Messages: queue.Messages, Consumers: queue.Consumers}, nil
+}
+
+// ConsumeVideoProcessing blocks, handing every delivery to handler. When the
+// connection or channel drops it reconnects with exponential backoff; it
+// only returns once Close has been called.
+func (q *RabbitMQMessageQueue) ConsumeVideoProcessing(handler func(context.Context, domain.VideoProcessingMessage)) error {
+	delay := minReconnectDelay
+	for {
+		err := q.consumeOnce(handler)
+		if q.isClosed() {
+			return nil
+		}
+		if err == nil {
+			// The delivery channel closed after a healthy session.
+			delay = minReconnectDelay
+			err = errors.New("delivery channel closed")
+		}
+		slog.Warn("rabbitmq consumer interrupted, reconnecting", "error", err, "retry_in", delay.String())
+		time.Sleep(delay)
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+func (q *RabbitMQMessageQueue) consumeOnce(handler func(context.Context, domain.VideoProcessingMessage)) error {
+	conn, err := q.connection()
+	if err != nil {
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open a channel for consumer: %w", err)
+	}
+	defer ch.Close()
+
+	queue, err := q.declare(ch)
+	if err != nil {
+		return fmt.Errorf("failed to declare a queue for consumer: %w", err)
+	}
+
+	msgs, err := ch.Consume(
+		queue.Name, // queue
+		"",         // consumer
+		true,       // auto-ack
+		false,      // exclusive
+		false,      // no-local
+		false,      // no-wait
+		nil,        // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register a consumer: %w", err)
+	}
+
+	q.consuming.Store(true)
+	defer q.consuming.Store(false)
+
+	slog.Info("waiting for messages", "queue", queue.Name)
+	for d := range msgs {
+		ctx := otel.GetTextMapPropagator().Extract(context.Background(), amqpHeaderCarrier(d.Headers))
+		ctx, span := otel.Tracer(tracerName).Start(ctx, queue.Name+" process",
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(
+				attribute.String("messaging.system", "rabbitmq"),
+				attribute.String("messaging.destination.name", queue.Name),
+			))
+
+		var msg domain.VideoProcessingMessage
+		if err := json.Unmarshal(d.Body, &msg); err != nil {
+			slog.ErrorContext(ctx, "failed to unmarshal message", "error", err, "bytes", len(d.Body))
+			span.RecordError(err)
+			span.End()
+			continue
+		}
+		span.SetAttributes(attribute.Int("video.id", msg.VideoStatusID))
+		slog.DebugContext(ctx, "message received", "video_id", msg.VideoStatusID, "attempt", msg.Attempt)
+		handler(ctx, msg)
+		span.End()
+	}
+	return nil
+}
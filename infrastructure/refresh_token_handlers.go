@@ -0,0 +1,121 @@
+// infrastructure/refresh_token_handlers.go
+package infrastructure
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/usecase"
+)
+
+type RefreshTokenHandlers struct {
+	IssueRefreshTokenUC       *usecase.IssueRefreshTokenUseCase
+	RefreshAccessTokenUC      *usecase.RefreshAccessTokenUseCase
+	RevokeRefreshTokenUC      *usecase.RevokeRefreshTokenUseCase
+	RevokeUserRefreshTokensUC *usecase.RevokeUserRefreshTokensUseCase
+}
+
+// RefreshTokenRequest carries a refresh token to exchange or revoke.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type RefreshTokenResponse struct {
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"refresh_token_expires_at"`
+}
+
+// TokenResponse follows the OAuth 2.0 token response, plus the expiry of
+// the new refresh token.
+type TokenResponse struct {
+	AccessToken           string    `json:"access_token"`
+	TokenType             string    `json:"token_type"`
+	ExpiresIn             int       `json:"expires_in"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+func NewRefreshTokenHandlers(issueUC *usecase.IssueRefreshTokenUseCase, refreshUC *usecase.RefreshAccessTokenUseCase, revokeUC *usecase.RevokeRefreshTokenUseCase, revokeAllUC *usecase.RevokeUserRefreshTokensUseCase) *RefreshTokenHandlers {
+	return &RefreshTokenHandlers{
+		IssueRefreshTokenUC:       issueUC,
+		RefreshAccessTokenUC:      refreshUC,
+		RevokeRefreshTokenUC:      revokeUC,
+		RevokeUserRefreshTokensUC: revokeAllUC,
+	}
+}
+
+// IssueRefreshTokenHandler starts a session for the caller, carrying the
+// user, username, role and workspace of the access token presented.
+func (h *RefreshTokenHandlers) IssueRefreshTokenHandler(c *gin.Context) {
+	subject := domain.TokenSubject{
+		UserID:      c.MustGet("user_id").(int),
+		Username:    c.GetString("username"),
+		Role:        c.MustGet("role").(domain.Role),
+		WorkspaceID: c.GetInt("workspace_id"),
+	}
+	output, err := h.IssueRefreshTokenUC.Execute(subject, c.GetString("session_id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenChained) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, RefreshTokenResponse{RefreshToken: output.Secret, ExpiresAt: output.Token.ExpiresAt})
+}
+
+// RefreshHandler exchanges a refresh token for a new access token and a new
+// refresh token. It needs no other credentials.
+func (h *RefreshTokenHandlers) RefreshHandler(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token is required"})
+		return
+	}
+	output, err := h.RefreshAccessTokenUC.Execute(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, TokenResponse{
+		AccessToken:           output.AccessToken,
+		TokenType:             "Bearer",
+		ExpiresIn:             int(time.Until(output.AccessTokenExpiresAt).Seconds()),
+		RefreshToken:          output.RefreshToken,
+		RefreshTokenExpiresAt: output.RefreshTokenExpiresAt,
+	})
+}
+
+// RevokeHandler ends the session of a refresh token. Like OAuth 2.0 token
+// revocation it succeeds for unknown tokens too.
+func (h *RefreshTokenHandlers) RevokeHandler(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token is required"})
+		return
+	}
+	if err := h.RevokeRefreshTokenUC.Execute(req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeAllHandler ends every session of the caller.
+func (h *RefreshTokenHandlers) RevokeAllHandler(c *gin.Context) {
+	if err := h.RevokeUserRefreshTokensUC.Execute(c.MustGet("user_id").(int)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,109 @@
+// infrastructure/router.go
+package infrastructure
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// RouterConfig carries everything NewRouter needs to mount the HTTP API.
+type RouterConfig struct {
+	VideoHandlers     *VideoHandlers
+	APIKeyHandlers    *APIKeyHandlers
+	AdminHandlers     *AdminHandlers
+	WorkspaceHandlers *WorkspaceHandlers
+	ShareHandlers     *ShareHandlers
+	FrameHandlers     *FrameHandlers
+	OutputHandlers    *OutputHandlers
+	// RefreshTokenHandlers is optional; refresh tokens are only offered
+	// when the service can sign access tokens.
+	RefreshTokenHandlers *RefreshTokenHandlers
+	Health               *HealthChecker
+	AuthMiddleware       gin.HandlerFunc
+	// WorkspaceMiddleware resolves the active workspace for the video
+	// routes.
+	WorkspaceMiddleware gin.HandlerFunc
+	// Metrics is optional; when set every request is instrumented and
+	// /metrics is exposed.
+	Metrics *PrometheusMetrics
+}
+
+func NewRouter(cfg RouterConfig) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery(), TracingMiddleware(), RequestIDMiddleware(), AccessLogMiddleware())
+	if cfg.Metrics != nil {
+		router.Use(cfg.Metrics.Middleware())
+		router.GET("/metrics", cfg.Metrics.Handler())
+	}
+
+	router.GET("/livez", cfg.Health.LivezHandler)
+	router.GET("/readyz", cfg.Health.ReadyzHandler)
+	router.GET("/health", cfg.Health.HealthHandler)
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "Video Processor Service is running!"})
+	})
+
+	// Share URLs carry their own signature instead of credentials.
+	router.GET("/share/:id", cfg.ShareHandlers.DownloadSharedVideoHandler)
+	router.HEAD("/share/:id", cfg.ShareHandlers.DownloadSharedVideoHandler)
+
+	// The refresh token is the credential.
+	if cfg.RefreshTokenHandlers != nil {
+		router.POST("/auth/refresh", cfg.RefreshTokenHandlers.RefreshHandler)
+		router.POST("/auth/revoke", cfg.RefreshTokenHandlers.RevokeHandler)
+	}
+
+	authRoutes := router.Group("/")
+	authRoutes.Use(cfg.AuthMiddleware)
+	{
+		videos := authRoutes.Group("/", cfg.WorkspaceMiddleware)
+		videos.POST("/upload", RequireScope(domain.ScopeUpload), RequireWorkspaceRole(domain.WorkspaceRoleMember), cfg.VideoHandlers.UploadVideoHandler)
+		videos.GET("/videos/status", RequireScope(domain.ScopeRead), cfg.VideoHandlers.ListVideoStatusHandler)
+		videos.GET("/videos/:id/download", RequireScope(domain.ScopeRead), cfg.VideoHandlers.DownloadVideoHandler)
+		videos.GET("/videos/:id/events", RequireScope(domain.ScopeRead), cfg.VideoHandlers.ListVideoEventsHandler)
+		videos.GET("/videos/:id/manifest", RequireScope(domain.ScopeRead), cfg.FrameHandlers.ManifestHandler)
+		videos.GET("/videos/:id/frames", RequireScope(domain.ScopeRead), cfg.FrameHandlers.ListFramesHandler)
+		videos.GET("/videos/:id/frames/at", RequireScope(domain.ScopeRead), cfg.FrameHandlers.GetFrameHandler)
+		videos.GET("/videos/:id/frames/archive", RequireScope(domain.ScopeRead), cfg.FrameHandlers.DownloadFramesHandler)
+		videos.GET("/videos/:id/frames/:index", RequireScope(domain.ScopeRead), cfg.FrameHandlers.GetFrameHandler)
+		videos.GET("/videos/:id/frame", RequireScope(domain.ScopeRead), cfg.FrameHandlers.GrabFrameHandler)
+		videos.GET("/videos/:id/outputs", RequireScope(domain.ScopeRead), cfg.OutputHandlers.ListOutputsHandler)
+		videos.GET("/videos/:id/outputs/:name", RequireScope(domain.ScopeRead), cfg.OutputHandlers.GetOutputHandler)
+		videos.POST("/videos/:id/reprocess", RequireScope(domain.ScopeUpload), cfg.VideoHandlers.ReprocessVideoHandler)
+		videos.POST("/videos/:id/share", RequireScope(domain.ScopeShare), cfg.ShareHandlers.CreateShareLinkHandler)
+		videos.GET("/videos/:id/shares", RequireScope(domain.ScopeRead), cfg.ShareHandlers.ListShareLinksHandler)
+		videos.GET("/videos/:id/shares/:share_id/accesses", RequireScope(domain.ScopeRead), cfg.ShareHandlers.ListShareAccessesHandler)
+		videos.DELETE("/videos/:id/shares/:share_id", RequireScope(domain.ScopeShare), cfg.ShareHandlers.RevokeShareLinkHandler)
+
+		apiKeys := authRoutes.Group("/api-keys", RequireScope(domain.ScopeManageAPIKeys))
+		apiKeys.POST("", cfg.APIKeyHandlers.CreateAPIKeyHandler)
+		apiKeys.GET("", cfg.APIKeyHandlers.ListAPIKeysHandler)
+		apiKeys.DELETE("/:id", cfg.APIKeyHandlers.RevokeAPIKeyHandler)
+
+		if cfg.RefreshTokenHandlers != nil {
+			sessions := authRoutes.Group("/auth/refresh-tokens", RequireScope(domain.ScopeSessions))
+			sessions.POST("", cfg.RefreshTokenHandlers.IssueRefreshTokenHandler)
+			sessions.DELETE("", cfg.RefreshTokenHandlers.RevokeAllHandler)
+		}
+
+		workspaces := authRoutes.Group("/workspaces")
+		workspaces.POST("", RequireScope(domain.ScopeManageWorkspaces), cfg.WorkspaceHandlers.CreateWorkspaceHandler)
+		workspaces.GET("", RequireScope(domain.ScopeRead), cfg.WorkspaceHandlers.ListWorkspacesHandler)
+		workspaces.GET("/:id/members", RequireScope(domain.ScopeRead), cfg.WorkspaceHandlers.ListMembersHandler)
+		workspaces.PUT("/:id/members/:user_id", RequireScope(domain.ScopeManageWorkspaces), cfg.WorkspaceHandlers.SetMemberHandler)
+		workspaces.DELETE("/:id/members/:user_id", RequireScope(domain.ScopeManageWorkspaces), cfg.WorkspaceHandlers.RemoveMemberHandler)
+
+		admin := authRoutes.Group("/admin", RequireRole(domain.RoleOperator))
+		admin.GET("/videos", cfg.AdminHandlers.ListVideosHandler)
+		admin.POST("/videos/:id/fail", RequireRole(domain.RoleAdmin), cfg.AdminHandlers.FailVideoHandler)
+		admin.POST("/videos/:id/requeue", RequireRole(domain.RoleAdmin), cfg.AdminHandlers.RequeueVideoHandler)
+		admin.DELETE("/videos/:id", RequireRole(domain.RoleAdmin), cfg.AdminHandlers.DeleteVideoHandler)
+		admin.GET("/queue", cfg.AdminHandlers.QueueStatsHandler)
+		admin.GET("/users/:id/quota", cfg.AdminHandlers.GetUserQuotaHandler)
+		admin.PUT("/users/:id/quota", RequireRole(domain.RoleAdmin), cfg.AdminHandlers.SetUserQuotaHandler)
+	}
+
+	return router
+}
@@ -0,0 +1,281 @@
+// infrastructure/share_handlers.go
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/usecase"
+)
+
+type ShareHandlers struct {
+	CreateShareLinkUC     *usecase.CreateShareLinkUseCase
+	ListShareLinksUC      *usecase.ListShareLinksUseCase
+	ListShareAccessesUC   *usecase.ListShareAccessesUseCase
+	RevokeShareLinkUC     *usecase.RevokeShareLinkUseCase
+	DownloadSharedVideoUC *usecase.DownloadSharedVideoUseCase
+	// PublicBaseURL prefixes share URLs, e.g. https://videos.example.com.
+	// When empty the scheme and host of the creating request are used.
+	PublicBaseURL string
+}
+
+type CreateShareLinkRequest struct {
+	// ExpiresIn is the link lifetime in seconds.
+	ExpiresIn    int `json:"expires_in"`
+	MaxDownloads int `json:"max_downloads"`
+}
+
+type ShareLinkResponse struct {
+	ID            int        `json:"id"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	MaxDownloads  int        `json:"max_downloads,omitempty"`
+	DownloadCount int        `json:"download_count"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	// URL is present only in the creation response.
+	URL string `json:"url,omitempty"`
+}
+
+type ShareAccessResponse struct {
+	RemoteAddr string    `json:"remote_addr"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	Outcome    string    `json:"outcome"`
+	AccessedAt time.Time `json:"accessed_at"`
+}
+
+func NewShareHandlers(
+	createUC *usecase.CreateShareLinkUseCase,
+	listUC *usecase.ListShareLinksUseCase,
+	accessesUC *usecase.ListShareAccessesUseCase,
+	revokeUC *usecase.RevokeShareLinkUseCase,
+	downloadUC *usecase.DownloadSharedVideoUseCase,
+	publicBaseURL string,
+) *ShareHandlers {
+	return &ShareHandlers{
+		CreateShareLinkUC:     createUC,
+		ListShareLinksUC:      listUC,
+		ListShareAccessesUC:   accessesUC,
+		RevokeShareLinkUC:     revokeUC,
+		DownloadSharedVideoUC: downloadUC,
+		PublicBaseURL:         strings.TrimSuffix(publicBaseURL, "/"),
+	}
+}
+
+func newShareLinkResponse(l domain.ShareLink) ShareLinkResponse {
+	return ShareLinkResponse{
+		ID:            l.ID,
+		ExpiresAt:     l.ExpiresAt,
+		MaxDownloads:  l.MaxDownloads,
+		DownloadCount: l.DownloadCount,
+		RevokedAt:     l.RevokedAt,
+		CreatedAt:     l.CreatedAt,
+	}
+}
+
+func (h *ShareHandlers) shareURL(c *gin.Context, link *domain.ShareLink, signature string) string {
+	base := h.PublicBaseURL
+	if base == "" {
+		scheme := "http"
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		base = scheme + "://" + c.Request.Host
+	}
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(link.ExpiresAt.Unix(), 10))
+	query.Set("sig", signature)
+	return fmt.Sprintf("%s/share/%d?%s", base, link.ID, query.Encode())
+}
+
+// respondShareError maps share link errors, falling back to the per-video
+// mapping.
+func respondShareError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidShareLinkRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrShareLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+	default:
+		respondVideoError(c, err)
+	}
+}
+
+func (h *ShareHandlers) CreateShareLinkHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+	var req CreateShareLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	output, err := h.CreateShareLinkUC.Execute(usecase.CreateShareLinkInput{
+		UserID:       userID,
+		VideoID:      videoID,
+		ExpiresIn:    time.Duration(req.ExpiresIn) * time.Second,
+		MaxDownloads: req.MaxDownloads,
+	})
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	response := newShareLinkResponse(*output.Link)
+	response.URL = h.shareURL(c, output.Link, output.Signature)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *ShareHandlers) ListShareLinksHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+
+	links, err := h.ListShareLinksUC.Execute(userID, videoID)
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+	response := make([]ShareLinkResponse, 0, len(links))
+	for _, l := range links {
+		response = append(response, newShareLinkResponse(l))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ShareHandlers) ListShareAccessesHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+	linkID, err := strconv.Atoi(c.Param("share_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share link ID"})
+		return
+	}
+
+	accesses, err := h.ListShareAccessesUC.Execute(userID, videoID, linkID)
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+	response := make([]ShareAccessResponse, 0, len(accesses))
+	for _, a := range accesses {
+		response = append(response, ShareAccessResponse{
+			RemoteAddr: a.RemoteAddr,
+			UserAgent:  a.UserAgent,
+			Outcome:    a.Outcome,
+			AccessedAt: a.AccessedAt,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ShareHandlers) RevokeShareLinkHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	videoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+	linkID, err := strconv.Atoi(c.Param("share_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share link ID"})
+		return
+	}
+
+	if err := h.RevokeShareLinkUC.Execute(userID, videoID, linkID); err != nil {
+		respondShareError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DownloadSharedVideoHandler serves an artifact through a signed share URL.
+// It is mounted without authentication, for GET and HEAD; only requests
+// that start a transfer count as downloads. Counted transfers get their own
+// ETag: a range whose If-Range carries it continues the transfer for free,
+// from no further back than what was already sent.
+func (h *ShareHandlers) DownloadSharedVideoHandler(c *gin.Context) {
+	linkID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid share link"})
+		return
+	}
+
+	format, err := domain.ParseArchiveFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be zip, tar or tar.gz"})
+		return
+	}
+
+	download, err := h.DownloadSharedVideoUC.Execute(c.Request.Context(), usecase.DownloadSharedVideoInput{
+		LinkID:     linkID,
+		Expires:    expires,
+		Signature:  c.Query("sig"),
+		Format:     format,
+		RemoteAddr: c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		Classify: func(download *usecase.ArtifactDownload) usecase.ShareRequest {
+			return classifyShareRequest(c.Request, download)
+		},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidShareSignature):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid share link"})
+		case errors.Is(err, domain.ErrShareLinkExpired), errors.Is(err, domain.ErrShareLinkRevoked), errors.Is(err, domain.ErrShareLinkExhausted):
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrShareLinkNotFound), errors.Is(err, domain.ErrVideoNotFound),
+			errors.Is(err, domain.ErrVideoNotCompleted), errors.Is(err, domain.ErrFileNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shared video is no longer available"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	defer download.Close()
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	transfer := download.Transfer
+	if transfer == nil {
+		serveDownload(c, download.ArtifactDownload)
+		return
+	}
+	c.Header("ETag", shareTransferETag(transfer))
+	serveDownload(c, download.ArtifactDownload)
+
+	// Only whole responses and single ranges from a given offset are
+	// contiguous runs of the artifact that a resume can follow.
+	start, ok := int64(0), c.Writer.Status() == http.StatusOK
+	if c.Writer.Status() == http.StatusPartialContent {
+		start, ok = rangeStart(c.Request.Header.Get("Range"))
+	}
+	if ok && c.Writer.Size() > 0 {
+		h.DownloadSharedVideoUC.RecordProgress(c.Request.Context(), transfer, start, start+int64(c.Writer.Size()))
+	}
+}
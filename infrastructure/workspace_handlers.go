@@ -0,0 +1,187 @@
+// infrastructure/workspace_handlers.go
+package infrastructure
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/usecase"
+)
+
+type WorkspaceHandlers struct {
+	CreateWorkspaceUC       *usecase.CreateWorkspaceUseCase
+	ListWorkspacesUC        *usecase.ListWorkspacesUseCase
+	ListWorkspaceMembersUC  *usecase.ListWorkspaceMembersUseCase
+	SetWorkspaceMemberUC    *usecase.SetWorkspaceMemberUseCase
+	RemoveWorkspaceMemberUC *usecase.RemoveWorkspaceMemberUseCase
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+type WorkspaceResponse struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkspaceMemberRequest struct {
+	Role domain.WorkspaceRole `json:"role"`
+}
+
+type WorkspaceMemberResponse struct {
+	UserID    int       `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewWorkspaceHandlers(
+	createUC *usecase.CreateWorkspaceUseCase,
+	listUC *usecase.ListWorkspacesUseCase,
+	listMembersUC *usecase.ListWorkspaceMembersUseCase,
+	setMemberUC *usecase.SetWorkspaceMemberUseCase,
+	removeMemberUC *usecase.RemoveWorkspaceMemberUseCase,
+) *WorkspaceHandlers {
+	return &WorkspaceHandlers{
+		CreateWorkspaceUC:       createUC,
+		ListWorkspacesUC:        listUC,
+		ListWorkspaceMembersUC:  listMembersUC,
+		SetWorkspaceMemberUC:    setMemberUC,
+		RemoveWorkspaceMemberUC: removeMemberUC,
+	}
+}
+
+func newWorkspaceMemberResponse(m domain.WorkspaceMember) WorkspaceMemberResponse {
+	return WorkspaceMemberResponse{UserID: m.UserID, Role: string(m.Role), CreatedAt: m.CreatedAt}
+}
+
+// respondWorkspaceError maps domain errors from the workspace use cases to
+// HTTP responses. Non-members get 404 so they can't probe which workspaces
+// exist.
+func respondWorkspaceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotWorkspaceMember), errors.Is(err, domain.ErrWorkspaceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace or member not found"})
+	case errors.Is(err, domain.ErrInsufficientWorkspaceRole):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only workspace owners can manage members"})
+	case errors.Is(err, domain.ErrLastWorkspaceOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidWorkspaceRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *WorkspaceHandlers) CreateWorkspaceHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	var req CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	workspace, err := h.CreateWorkspaceUC.Execute(userID, req.Name)
+	if err != nil {
+		respondWorkspaceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, WorkspaceResponse{
+		ID:        workspace.ID,
+		Name:      workspace.Name,
+		Role:      string(domain.WorkspaceRoleOwner),
+		CreatedAt: workspace.CreatedAt,
+	})
+}
+
+func (h *WorkspaceHandlers) ListWorkspacesHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	memberships, err := h.ListWorkspacesUC.Execute(userID)
+	if err != nil {
+		respondWorkspaceError(c, err)
+		return
+	}
+	response := make([]WorkspaceResponse, 0, len(memberships))
+	for _, m := range memberships {
+		response = append(response, WorkspaceResponse{
+			ID:        m.Workspace.ID,
+			Name:      m.Workspace.Name,
+			Role:      string(m.Role),
+			CreatedAt: m.Workspace.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *WorkspaceHandlers) ListMembersHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	workspaceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	members, err := h.ListWorkspaceMembersUC.Execute(userID, workspaceID)
+	if err != nil {
+		respondWorkspaceError(c, err)
+		return
+	}
+	response := make([]WorkspaceMemberResponse, 0, len(members))
+	for _, m := range members {
+		response = append(response, newWorkspaceMemberResponse(m))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *WorkspaceHandlers) SetMemberHandler(c *gin.Context) {
+	actorID := c.MustGet("user_id").(int)
+	workspaceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	var req WorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	member, err := h.SetWorkspaceMemberUC.Execute(actorID, workspaceID, userID, req.Role)
+	if err != nil {
+		respondWorkspaceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newWorkspaceMemberResponse(*member))
+}
+
+func (h *WorkspaceHandlers) RemoveMemberHandler(c *gin.Context) {
+	actorID := c.MustGet("user_id").(int)
+	workspaceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.RemoveWorkspaceMemberUC.Execute(actorID, workspaceID, userID); err != nil {
+		respondWorkspaceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,113 @@
+// logging/logging.go
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedactedValue replaces the value of sensitive attributes.
+const RedactedValue = "[REDACTED]"
+
+// redactedKeys never reach the output with their real value.
+var redactedKeys = map[string]bool{
+	"username":            true,
+	"path":                true,
+	"file_path":           true,
+	"video_path":          true,
+	"processed_file_path": true,
+}
+
+// pathPattern matches filesystem paths embedded in free text such as error
+// messages ("open ./uploads/1_x.mp4: no such file").
+var pathPattern = regexp.MustCompile(`(?:\.{1,2}/|/)[^\s:"']+|[\w.-]+(?:/[\w.-]+)+`)
+
+type ctxKey struct{}
+
+// With returns a context whose log records carry attrs in addition to any
+// attributes already attached to ctx.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, ctxKey{}, merged)
+}
+
+// ParseLevel maps LOG_LEVEL style names to slog levels, defaulting to info.
+func ParseLevel(name string) slog.Level {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// New returns a JSON logger writing to w that adds context attributes and
+// redacts sensitive values.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(NewHandler(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})))
+}
+
+// Handler decorates another handler with the attributes stored by With and
+// the trace ID of the active span, so HTTP and worker lines for one upload
+// can be joined.
+type Handler struct {
+	next slog.Handler
+}
+
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	attrs, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs[:len(attrs):len(attrs)], slog.String("trace_id", sc.TraceID().String()))
+	}
+	if len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if redactedKeys[a.Key] {
+		return slog.String(a.Key, RedactedValue)
+	}
+	if a.Key == "error" {
+		return slog.String(a.Key, ScrubPaths(a.Value.String()))
+	}
+	return a
+}
+
+// ScrubPaths replaces filesystem paths in s with a placeholder.
+func ScrubPaths(s string) string {
+	return pathPattern.ReplaceAllString(s, RedactedValue)
+}
@@ -0,0 +1,49 @@
+// logging/logging_test.go
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLoggerAddsContextAndRedacts(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+
+	ctx := With(context.Background(), slog.String("request_id", "req-1"))
+	ctx = With(ctx, slog.Int("video_id", 42))
+	logger.ErrorContext(ctx, "frame extraction failed",
+		"username", "alice",
+		"video_path", "./uploads/1_2024.mp4",
+		"error", errors.New("open /app/processed_videos/1/42/clip.png: permission denied"),
+	)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	if entry["request_id"] != "req-1" || entry["video_id"] != float64(42) {
+		t.Errorf("context attributes missing: %v", entry)
+	}
+	if entry["username"] != RedactedValue || entry["video_path"] != RedactedValue {
+		t.Errorf("sensitive keys not redacted: %v", entry)
+	}
+	if msg := entry["error"].(string); strings.Contains(msg, "processed_videos") || !strings.Contains(msg, "permission denied") {
+		t.Errorf("error = %q, want path scrubbed and reason kept", msg)
+	}
+}
+
+func TestLevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, ParseLevel("warn"))
+	logger.Info("hidden")
+	logger.Warn("shown")
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "shown") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
@@ -0,0 +1,190 @@
+// usecase/archive.go
+package usecase
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// Archive is a set of stored files packed into an archive while it is
+// written, so the packed copy never touches storage.
+type Archive struct {
+	// Name is the download filename, with the format's extension.
+	Name    string
+	Format  domain.ArchiveFormat
+	ModTime time.Time
+	entries []archiveEntry
+}
+
+type archiveEntry struct {
+	name    string
+	size    int64
+	modTime time.Time
+	open    func() (io.ReadCloser, error)
+}
+
+// frameEntries describes stored frames as archive entries. Each frame is
+// opened only when its turn comes.
+func frameEntries(storage domain.FileStorageService, framePaths []string) ([]archiveEntry, time.Time, error) {
+	entries := make([]archiveEntry, 0, len(framePaths))
+	var latest time.Time
+	for _, framePath := range framePaths {
+		file, err := storage.OpenFile(framePath)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to open frame: %w", err)
+		}
+		file.Close()
+		entries = append(entries, archiveEntry{
+			name:    file.Name,
+			size:    file.Size,
+			modTime: file.ModTime,
+			open:    func() (io.ReadCloser, error) { return storage.OpenFile(framePath) },
+		})
+		if file.ModTime.After(latest) {
+			latest = file.ModTime
+		}
+	}
+	return entries, latest, nil
+}
+
+// openZip reads the directory of a stored ZIP.
+func openZip(file *domain.StoredFile) (*zip.Reader, error) {
+	readerAt, ok := file.ReadSeekCloser.(io.ReaderAt)
+	if !ok {
+		readerAt = &seekingReaderAt{r: file}
+	}
+	reader, err := zip.NewReader(readerAt, file.Size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read processed file: %w", err)
+	}
+	return reader, nil
+}
+
+// zipEntries describes the files inside a stored ZIP as archive entries, for
+// repacking an artifact in another format. When names is not nil only the
+// files it lists are included.
+func zipEntries(file *domain.StoredFile, names map[string]bool) ([]archiveEntry, error) {
+	reader, err := openZip(file)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]archiveEntry, 0, len(reader.File))
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() || (names != nil && !names[path.Base(f.Name)]) {
+			continue
+		}
+		entries = append(entries, archiveEntry{
+			name:    path.Base(f.Name),
+			size:    int64(f.UncompressedSize64),
+			modTime: f.Modified,
+			open:    f.Open,
+		})
+	}
+	return entries, nil
+}
+
+// seekingReaderAt adapts storage handles that can only seek.
+type seekingReaderAt struct {
+	mu sync.Mutex
+	r  io.ReadSeeker
+}
+
+func (s *seekingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.r.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.ReadFull(s.r, p)
+}
+
+// WriteTo writes the archive to w. An error after the first byte leaves a
+// truncated archive that unpackers reject.
+func (a *Archive) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{}
+	out := io.MultiWriter(w, counter)
+	var err error
+	switch a.Format {
+	case domain.ArchiveFormatTar:
+		err = a.writeTar(out)
+	case domain.ArchiveFormatTarGz:
+		gz := gzip.NewWriter(out)
+		if err = a.writeTar(gz); err == nil {
+			err = gz.Close()
+		}
+	default:
+		err = a.writeZip(out)
+	}
+	return counter.n, err
+}
+
+// writeZip stores PNG and JPEG entries without compressing them again, which
+// would cost CPU for no gain. Large sets switch to ZIP64 on their own.
+func (a *Archive) writeZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, entry := range a.entries {
+		header := &zip.FileHeader{Name: entry.name, Modified: entry.modTime, Method: zip.Deflate}
+		if isCompressedImage(entry.name) {
+			header.Method = zip.Store
+		}
+		header.SetMode(0644)
+		dst, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if err := copyEntry(dst, entry); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (a *Archive) writeTar(w io.Writer) error {
+	tw := tar.NewWriter(w)
+	for _, entry := range a.entries {
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     entry.name,
+			Size:     entry.size,
+			Mode:     0644,
+			ModTime:  entry.modTime,
+			Format:   tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if err := copyEntry(tw, entry); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func copyEntry(dst io.Writer, entry archiveEntry) error {
+	src, err := entry.open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", entry.name, err)
+	}
+	defer src.Close()
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to pack %s: %w", entry.name, err)
+	}
+	return nil
+}
+
+func isCompressedImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+// usecase/artifact.go
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// checksumStoredFile returns the hex SHA-256 of a file kept by storage.
+// Reading it back through the storage service keeps the checksum independent
+// of where the artifact lives.
+func checksumStoredFile(storage domain.FileStorageService, path string) (string, error) {
+	file, err := storage.OpenFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func fileExists(storage domain.FileStorageService, filePath string) bool {
+	file, err := storage.OpenFile(filePath)
+	if err != nil {
+		return false
+	}
+	file.Close()
+	return true
+}
+
+// openArtifact opens the processed artifact of a completed video with its
+// checksum set. Artifacts stored before checksums were recorded are hashed
+// once here and the result is saved on the video.
+// The caller is responsible for closing the returned file.
+func openArtifact(ctx context.Context, videos domain.VideoRepository, storage domain.FileStorageService, video *domain.Video) (*domain.StoredFile, error) {
+	file, err := storage.OpenFile(video.ProcessedFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open processed file: %w", err)
+	}
+	if video.ProcessedFileChecksum != "" {
+		file.Checksum = video.ProcessedFileChecksum
+		return file, nil
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to checksum processed file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to rewind processed file: %w", err)
+	}
+	file.Checksum = hex.EncodeToString(hasher.Sum(nil))
+	if err := videos.SetProcessedFileChecksum(video.ID, video.Claim, file.Checksum); err != nil {
+		slog.WarnContext(ctx, "failed to store artifact checksum", "video_id", video.ID, "error", err)
+	}
+	return file, nil
+}
+
+// ArtifactDownload is a completed video's artifact in the requested format:
+// either the stored file, served as is, or an archive built while it is sent.
+type ArtifactDownload struct {
+	File    *domain.StoredFile
+	Archive *Archive
+	// source is the stored ZIP an Archive repacks, if any.
+	source io.Closer
+}
+
+func (d *ArtifactDownload) Close() error {
+	if d.File != nil {
+		return d.File.Close()
+	}
+	if d.source != nil {
+		return d.source.Close()
+	}
+	return nil
+}
+
+// openDownload prepares a completed video's artifact in format. Frames kept
+// in storage are always packed on the fly; a stored ZIP is served directly
+// or repacked when another format is asked for.
+// The caller is responsible for closing the returned download.
+func openDownload(ctx context.Context, videos domain.VideoRepository, storage domain.FileStorageService, video *domain.Video, format domain.ArchiveFormat) (*ArtifactDownload, error) {
+	archive := &Archive{
+		Name:   fmt.Sprintf("%d_%s_processed.%s", video.UserID, path.Base(video.OriginalFilename), format),
+		Format: format,
+	}
+
+	if video.ArtifactLayout.OrDefault() == domain.ArtifactLayoutFrames {
+		frames, err := storage.ListFrames(video.ProcessedFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list frames: %w", err)
+		}
+		if len(frames) == 0 {
+			return nil, domain.ErrFileNotFound
+		}
+		if manifestPath := storage.GetManifestPath(video.ProcessedFilePath); fileExists(storage, manifestPath) {
+			frames = append([]string{manifestPath}, frames...)
+		}
+		if archive.entries, archive.ModTime, err = frameEntries(storage, frames); err != nil {
+			return nil, err
+		}
+		return &ArtifactDownload{Archive: archive}, nil
+	}
+
+	file, err := openArtifact(ctx, videos, storage, video)
+	if err != nil {
+		return nil, err
+	}
+	if format == domain.ArchiveFormatZip {
+		return &ArtifactDownload{File: file}, nil
+	}
+	if archive.entries, err = zipEntries(file, nil); err != nil {
+		file.Close()
+		return nil, err
+	}
+	archive.ModTime = file.ModTime
+	return &ArtifactDownload{Archive: archive, source: file}, nil
+}
@@ -0,0 +1,89 @@
+// usecase/audio.go
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// WaveformName is the waveform image among a job's outputs.
+const WaveformName = "waveform.png"
+
+// processAudio extracts the audio track of the upload, draws its waveform
+// and measures its loudness, which is stored on the video. Each step fails
+// on its own; videos without audio are recorded as such and get no
+// outputs.
+func (uc *ProcessVideoUseCase) processAudio(ctx context.Context, msg job, outputDir string) []domain.Output {
+	settings := uc.Audio.OrDefault()
+	hasAudio, err := uc.AudioProcessor.HasAudio(ctx, msg.VideoPath)
+	if err != nil {
+		slog.WarnContext(ctx, "could not probe audio", "error", err)
+		return nil
+	}
+	analysis := domain.AudioAnalysis{HasAudio: hasAudio}
+	if !hasAudio {
+		slog.InfoContext(ctx, "video has no audio stream")
+		uc.recordAudioAnalysis(ctx, msg, analysis)
+		return nil
+	}
+
+	var outputs []domain.Output
+	name := "audio." + string(settings.Format)
+	trackCtx, span := tracer.Start(ctx, "ffmpeg.extract_audio")
+	output, err := uc.extractAudio(trackCtx, msg, outputDir, name, settings.Format)
+	endSpan(span, err)
+	if err != nil {
+		slog.WarnContext(ctx, "could not extract audio", "error", err)
+	} else {
+		outputs = append(outputs, output)
+	}
+
+	waveformCtx, span := tracer.Start(ctx, "ffmpeg.render_waveform")
+	output, err = uc.renderWaveform(waveformCtx, msg, outputDir, settings)
+	endSpan(span, err)
+	if err != nil {
+		slog.WarnContext(ctx, "could not render waveform", "error", err)
+	} else {
+		outputs = append(outputs, output)
+	}
+
+	loudnessCtx, span := tracer.Start(ctx, "ffmpeg.measure_loudness")
+	loudness, err := uc.AudioProcessor.MeasureLoudness(loudnessCtx, msg.VideoPath)
+	endSpan(span, err)
+	if err != nil {
+		slog.WarnContext(ctx, "could not measure loudness", "error", err)
+	} else {
+		analysis.Loudness = &loudness
+	}
+	uc.recordAudioAnalysis(ctx, msg, analysis)
+	return outputs
+}
+
+func (uc *ProcessVideoUseCase) extractAudio(ctx context.Context, msg job, outputDir, name string, format domain.AudioFormat) (domain.Output, error) {
+	outputPath := uc.FileStorage.GetOutputPath(outputDir, name)
+	if err := uc.AudioProcessor.ExtractAudio(ctx, msg.VideoPath, outputPath, format); err != nil {
+		// ffmpeg may leave a partial track behind.
+		if !errors.Is(err, domain.ErrNoAudioStream) {
+			_ = uc.FileStorage.DeleteFile(outputPath)
+		}
+		return domain.Output{}, err
+	}
+	return recordOutput(uc.FileStorage, domain.OutputKindAudio, name, outputPath, format.ContentType())
+}
+
+func (uc *ProcessVideoUseCase) renderWaveform(ctx context.Context, msg job, outputDir string, settings domain.AudioSettings) (domain.Output, error) {
+	data, err := uc.AudioProcessor.RenderWaveform(ctx, msg.VideoPath, settings.WaveformWidth, settings.WaveformHeight)
+	if err != nil {
+		return domain.Output{}, err
+	}
+	return saveOutput(uc.FileStorage, outputDir, domain.OutputKindWaveform, WaveformName, "image/png", data)
+}
+
+func (uc *ProcessVideoUseCase) recordAudioAnalysis(ctx context.Context, msg job, analysis domain.AudioAnalysis) {
+	if err := uc.VideoRepo.SetAudioAnalysis(msg.VideoStatusID, msg.claim, analysis); err != nil {
+		slog.WarnContext(ctx, "could not record audio analysis", "error", err)
+	}
+}
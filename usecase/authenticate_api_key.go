@@ -0,0 +1,47 @@
+// usecase/authenticate_api_key.go
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// lastUsedResolution limits last-used writes to one per key per minute
+// instead of one per request.
+const lastUsedResolution = time.Minute
+
+type AuthenticateAPIKeyUseCase struct {
+	Repo domain.APIKeyRepository
+}
+
+// Execute resolves a plaintext key to its record. Unknown, revoked and
+// expired keys all fail with ErrInvalidAPIKey.
+func (uc *AuthenticateAPIKeyUseCase) Execute(ctx context.Context, secret string) (*domain.APIKey, error) {
+	if !strings.HasPrefix(secret, APIKeyPrefix) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	key, err := uc.Repo.FindByHash(hashSecret(secret))
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.Usable(now) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := uc.Repo.TouchLastUsed(key.ID, now); err != nil {
+			slog.WarnContext(ctx, "failed to record api key use", "api_key_id", key.ID, "error", err)
+		}
+		key.LastUsedAt = &now
+	}
+	return key, nil
+}
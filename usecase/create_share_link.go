@@ -0,0 +1,72 @@
+// usecase/create_share_link.go
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+const (
+	// DefaultShareLinkTTL applies when a share link is created without an
+	// explicit lifetime.
+	DefaultShareLinkTTL = 24 * time.Hour
+	MaxShareLinkTTL     = 7 * 24 * time.Hour
+)
+
+type CreateShareLinkInput struct {
+	UserID  int
+	VideoID int
+	// ExpiresIn defaults to DefaultShareLinkTTL when zero.
+	ExpiresIn time.Duration
+	// MaxDownloads is optional; zero means unlimited.
+	MaxDownloads int
+}
+
+type CreateShareLinkOutput struct {
+	Link *domain.ShareLink
+	// Signature must accompany the link ID and expiry in the share URL.
+	Signature string
+}
+
+type CreateShareLinkUseCase struct {
+	VideoRepo  domain.VideoRepository
+	Workspaces domain.WorkspaceRepository
+	Repo       domain.ShareLinkRepository
+	Signer     *ShareLinkSigner
+}
+
+// Execute creates a share link for a completed video. Sharing hands the
+// artifact to people outside the service, so workspace viewers can't do it.
+func (uc *CreateShareLinkUseCase) Execute(input CreateShareLinkInput) (*CreateShareLinkOutput, error) {
+	ttl := input.ExpiresIn
+	if ttl == 0 {
+		ttl = DefaultShareLinkTTL
+	}
+	if ttl < time.Minute || ttl > MaxShareLinkTTL {
+		return nil, fmt.Errorf("%w: lifetime must be between 1 minute and %s", domain.ErrInvalidShareLinkRequest, MaxShareLinkTTL)
+	}
+	if input.MaxDownloads < 0 {
+		return nil, fmt.Errorf("%w: max_downloads must not be negative", domain.ErrInvalidShareLinkRequest)
+	}
+
+	video, err := findAccessibleVideo(uc.VideoRepo, uc.Workspaces, input.UserID, input.VideoID, domain.WorkspaceRoleMember)
+	if err != nil {
+		return nil, err
+	}
+	if video.Status != domain.VideoStatusCompleted {
+		return nil, domain.ErrVideoNotCompleted
+	}
+
+	link := &domain.ShareLink{
+		VideoID:      video.ID,
+		CreatedBy:    input.UserID,
+		ExpiresAt:    time.Now().Add(ttl).Truncate(time.Second),
+		MaxDownloads: input.MaxDownloads,
+	}
+	if err := uc.Repo.Create(link); err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+	return &CreateShareLinkOutput{Link: link, Signature: uc.Signer.Sign(link.ID, link.ExpiresAt)}, nil
+}
@@ -0,0 +1,30 @@
+// usecase/create_workspace.go
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// MaxWorkspaceNameLength is the longest workspace name accepted, in runes.
+const MaxWorkspaceNameLength = 100
+
+type CreateWorkspaceUseCase struct {
+	Repo domain.WorkspaceRepository
+}
+
+// Execute creates a workspace owned by userID.
+func (uc *CreateWorkspaceUseCase) Execute(userID int, name string) (*domain.Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxWorkspaceNameLength {
+		return nil, fmt.Errorf("%w: name must have 1 to %d characters", domain.ErrInvalidWorkspaceRequest, MaxWorkspaceNameLength)
+	}
+	workspace := &domain.Workspace{Name: name}
+	if err := uc.Repo.Create(workspace, userID); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	return workspace, nil
+}
@@ -0,0 +1,117 @@
+// usecase/delete_video.go
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type DeleteVideoUseCase struct {
+	VideoRepo   domain.VideoRepository
+	FileStorage domain.FileStorageService
+	// Frames is optional; the frame index goes with the artifact.
+	Frames domain.FrameRepository
+	// Outputs is optional; outputs also go with the artifact.
+	Outputs domain.OutputRepository
+}
+
+// Execute removes a video and its files. Videos being processed must be
+// failed first. The artifact is kept while deduplicated videos still use it.
+func (uc *DeleteVideoUseCase) Execute(ctx context.Context, actorID, videoID int) error {
+	video, err := uc.VideoRepo.FindByID(videoID)
+	if err != nil {
+		return err
+	}
+	if video.Status == domain.VideoStatusProcessing {
+		return domain.ErrVideoProcessing
+	}
+
+	if video.ProcessedFilePath != "" {
+		inUse, err := uc.VideoRepo.ProcessedFileInUse(video.ProcessedFilePath, videoID)
+		if err != nil {
+			return err
+		}
+		if !inUse {
+			if err := uc.deleteArtifact(video); err != nil {
+				slog.WarnContext(ctx, "could not remove processed file", "video_id", videoID, "error", err)
+			}
+			if uc.Frames != nil {
+				if err := uc.Frames.DeleteByArtifact(video.ProcessedFilePath); err != nil {
+					slog.WarnContext(ctx, "could not remove frame index", "video_id", videoID, "error", err)
+				}
+			}
+			if uc.Outputs != nil {
+				if err := uc.deleteOutputs(video.ProcessedFilePath); err != nil {
+					slog.WarnContext(ctx, "could not remove outputs", "video_id", videoID, "error", err)
+				}
+			}
+		}
+	}
+	// Completed videos only have a source left when sources are kept.
+	if video.SourcePath != "" {
+		if err := uc.FileStorage.DeleteFile(video.SourcePath); err != nil {
+			slog.WarnContext(ctx, "could not remove uploaded video", "video_id", videoID, "error", err)
+		}
+	}
+
+	if err := uc.VideoRepo.Delete(videoID); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "video deleted", "video_id", videoID, "actor_id", actorID)
+	return nil
+}
+
+func (uc *DeleteVideoUseCase) deleteArtifact(video *domain.Video) error {
+	if video.ArtifactLayout.OrDefault() != domain.ArtifactLayoutFrames {
+		if err := uc.FileStorage.DeleteFile(video.ProcessedFilePath); err != nil {
+			return err
+		}
+		return uc.deleteKeptFrames(video.ProcessedFilePath)
+	}
+	frames, err := uc.FileStorage.ListFrames(video.ProcessedFilePath)
+	if err != nil {
+		return err
+	}
+	for _, framePath := range frames {
+		if err := uc.FileStorage.DeleteFile(framePath); err != nil {
+			return err
+		}
+	}
+	return uc.FileStorage.DeleteFile(uc.FileStorage.GetManifestPath(video.ProcessedFilePath))
+}
+
+// deleteKeptFrames removes the frames kept next to a ZIP artifact. Frames
+// that only exist inside the ZIP have no path.
+func (uc *DeleteVideoUseCase) deleteKeptFrames(artifactPath string) error {
+	if uc.Frames == nil {
+		return nil
+	}
+	frames, err := uc.Frames.FindByArtifact(artifactPath)
+	if err != nil {
+		return err
+	}
+	for _, f := range frames {
+		if f.Path == "" {
+			continue
+		}
+		if err := uc.FileStorage.DeleteFile(f.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *DeleteVideoUseCase) deleteOutputs(artifactPath string) error {
+	outputs, err := uc.Outputs.FindByArtifact(artifactPath)
+	if err != nil {
+		return err
+	}
+	for _, output := range outputs {
+		if err := uc.FileStorage.DeleteFile(output.Path); err != nil {
+			return err
+		}
+	}
+	return uc.Outputs.DeleteByArtifact(artifactPath)
+}
@@ -0,0 +1,198 @@
+// usecase/download_shared_video.go
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/logging"
+)
+
+type DownloadSharedVideoInput struct {
+	LinkID int
+	// Expires and Signature come from the share URL.
+	Expires   int64
+	Signature string
+	Format    domain.ArchiveFormat
+	// RemoteAddr and UserAgent identify the requester in the access log.
+	RemoteAddr string
+	UserAgent  string
+	// Classify tells, once the artifact is open, how the request counts
+	// against the link's download limit. Nil starts a transfer with every
+	// request.
+	Classify func(*ArtifactDownload) ShareRequest
+}
+
+type ShareRequestKind int
+
+const (
+	// ShareTransferStart starts a transfer, which counts as a download.
+	ShareTransferStart ShareRequestKind = iota
+	// ShareTransferResume continues a counted transfer. It is served
+	// without counting, even once the link is exhausted, if the transfer
+	// can be resumed from Start; otherwise it starts a new one.
+	ShareTransferResume
+	// ShareProbe sends no content, like HEAD or a 304, and isn't counted.
+	ShareProbe
+)
+
+type ShareRequest struct {
+	Kind ShareRequestKind
+	// Transfer and Start name the transfer a resume continues and the
+	// offset it continues from.
+	Transfer string
+	Start    int64
+}
+
+// SharedDownload is an artifact served through a share link.
+type SharedDownload struct {
+	*ArtifactDownload
+	// Transfer is the transfer the request started or resumes. It is nil
+	// for probes and for downloads that can't be resumed: streamed archives
+	// and artifacts without a checksum.
+	Transfer *domain.ShareTransfer
+}
+
+type DownloadSharedVideoUseCase struct {
+	Repo        domain.ShareLinkRepository
+	VideoRepo   domain.VideoRepository
+	FileStorage domain.FileStorageService
+	Signer      *ShareLinkSigner
+}
+
+// Execute opens the artifact behind a share link and counts the download
+// unless input.Classify makes it a probe or the resume of a transfer. Every
+// attempt with a valid signature is recorded in the link's access log. The
+// caller is responsible for closing the returned download.
+func (uc *DownloadSharedVideoUseCase) Execute(ctx context.Context, input DownloadSharedVideoInput) (*SharedDownload, error) {
+	ctx = logging.With(ctx, slog.Int("share_link_id", input.LinkID))
+	if !uc.Signer.Verify(input.LinkID, input.Expires, input.Signature) {
+		slog.WarnContext(ctx, "share link signature rejected", "remote_addr", input.RemoteAddr)
+		return nil, domain.ErrInvalidShareSignature
+	}
+
+	download, err := uc.open(ctx, input.LinkID, input.Format)
+	var transfer *domain.ShareTransfer
+	if err == nil {
+		if transfer, err = uc.count(ctx, input, download); err != nil {
+			download.Close()
+		}
+	}
+	uc.logAccess(ctx, input, err)
+	if err != nil {
+		return nil, err
+	}
+	return &SharedDownload{ArtifactDownload: download, Transfer: transfer}, nil
+}
+
+// count charges the request to the link. A resume that doesn't continue a
+// transfer of this artifact starts a new transfer instead.
+func (uc *DownloadSharedVideoUseCase) count(ctx context.Context, input DownloadSharedVideoInput, download *ArtifactDownload) (*domain.ShareTransfer, error) {
+	request := ShareRequest{Kind: ShareTransferStart}
+	if input.Classify != nil {
+		request = input.Classify(download)
+	}
+	file := download.File
+	switch request.Kind {
+	case ShareProbe:
+		return nil, nil
+	case ShareTransferResume:
+		if file != nil {
+			transfer, err := uc.Repo.ResumeTransfer(input.LinkID, request.Transfer, file.Checksum, request.Start)
+			if !errors.Is(err, domain.ErrShareTransferNotFound) {
+				return transfer, err
+			}
+		}
+	}
+
+	// Checked again with the count, atomically, now that it matters.
+	if _, err := uc.Repo.ConsumeDownload(input.LinkID, time.Now()); err != nil {
+		return nil, err
+	}
+	if file == nil || file.Checksum == "" {
+		return nil, nil
+	}
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	transfer := &domain.ShareTransfer{
+		Token:       base64.RawURLEncoding.EncodeToString(raw),
+		ShareLinkID: input.LinkID,
+		Checksum:    file.Checksum,
+		Size:        file.Size,
+	}
+	if err := uc.Repo.CreateTransfer(transfer); err != nil {
+		// The download is counted already; serve it, just not resumably.
+		slog.ErrorContext(ctx, "failed to record share link transfer", "error", err)
+		return nil, nil
+	}
+	return transfer, nil
+}
+
+// RecordProgress notes that the bytes of transfer from start to end were
+// sent, so that it can be resumed from there.
+func (uc *DownloadSharedVideoUseCase) RecordProgress(ctx context.Context, transfer *domain.ShareTransfer, start, end int64) {
+	if err := uc.Repo.AdvanceTransfer(transfer.ShareLinkID, transfer.Token, start, end); err != nil {
+		slog.ErrorContext(ctx, "failed to record share link transfer progress", "error", err)
+	}
+}
+
+func (uc *DownloadSharedVideoUseCase) open(ctx context.Context, linkID int, format domain.ArchiveFormat) (*ArtifactDownload, error) {
+	link, err := uc.Repo.FindByID(linkID)
+	if err != nil {
+		return nil, err
+	}
+	if err := link.CheckResume(time.Now()); err != nil {
+		return nil, err
+	}
+	video, err := uc.VideoRepo.FindByID(link.VideoID)
+	if err != nil {
+		return nil, err
+	}
+	if video.Status != domain.VideoStatusCompleted || video.ProcessedFilePath == "" {
+		return nil, domain.ErrVideoNotCompleted
+	}
+	return openDownload(ctx, uc.VideoRepo, uc.FileStorage, video, format)
+}
+
+func (uc *DownloadSharedVideoUseCase) logAccess(ctx context.Context, input DownloadSharedVideoInput, err error) {
+	outcome := shareAccessOutcome(err)
+	slog.InfoContext(ctx, "share link accessed", "outcome", outcome, "remote_addr", input.RemoteAddr)
+	if errors.Is(err, domain.ErrShareLinkNotFound) {
+		return
+	}
+	access := &domain.ShareAccess{
+		ShareLinkID: input.LinkID,
+		RemoteAddr:  input.RemoteAddr,
+		UserAgent:   input.UserAgent,
+		Outcome:     outcome,
+	}
+	if err := uc.Repo.LogAccess(access); err != nil {
+		slog.ErrorContext(ctx, "failed to record share link access", "error", err)
+	}
+}
+
+func shareAccessOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, domain.ErrShareLinkRevoked):
+		return "revoked"
+	case errors.Is(err, domain.ErrShareLinkExpired):
+		return "expired"
+	case errors.Is(err, domain.ErrShareLinkExhausted):
+		return "exhausted"
+	case errors.Is(err, domain.ErrShareLinkNotFound):
+		return "not_found"
+	case errors.Is(err, domain.ErrVideoNotFound), errors.Is(err, domain.ErrVideoNotCompleted), errors.Is(err, domain.ErrFileNotFound):
+		return "unavailable"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,25 @@
+// usecase/download_video.go
+package usecase
+
+import (
+	"context"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type DownloadVideoUseCase struct {
+	VideoRepo   domain.VideoRepository
+	Workspaces  domain.WorkspaceRepository
+	FileStorage domain.FileStorageService
+}
+
+// Execute opens the processed artifact of a completed video that userID owns
+// or can view through a workspace, packed in format.
+// The caller is responsible for closing the returned download.
+func (uc *DownloadVideoUseCase) Execute(ctx context.Context, userID, videoID int, format domain.ArchiveFormat) (*ArtifactDownload, error) {
+	video, err := findViewableArtifact(uc.VideoRepo, uc.Workspaces, userID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	return openDownload(ctx, uc.VideoRepo, uc.FileStorage, video, format)
+}
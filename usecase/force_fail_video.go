@@ -0,0 +1,52 @@
+// usecase/force_fail_video.go
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type ForceFailVideoUseCase struct {
+	VideoRepo domain.VideoRepository
+	EventRepo domain.VideoEventRepository
+}
+
+// Execute marks a PENDING or PROCESSING video as FAILED on behalf of
+// actorID, typically to release a job stuck on a dead worker. A worker still
+// holding the job loses its claim, so none of its later writes land, even
+// after the video is requeued, and it discards the files it made in its own
+// output directory.
+func (uc *ForceFailVideoUseCase) Execute(ctx context.Context, actorID, videoID int, reason string) (*domain.Video, error) {
+	video, err := uc.VideoRepo.FindByID(videoID)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		reason = "Failed by an operator"
+	}
+	if err := uc.VideoRepo.TransitionStatus(videoID, video.Status, domain.VideoStatusFailed, "", reason); err != nil {
+		return nil, err
+	}
+	recordAdminEvent(ctx, uc.EventRepo, actorID, videoID, video.Status, domain.VideoStatusFailed, 0, reason)
+	slog.InfoContext(ctx, "video failed by operator", "video_id", videoID, "actor_id", actorID)
+	return uc.VideoRepo.FindByID(videoID)
+}
+
+// recordAdminEvent appends a history event for a manual action. The actor
+// is stored where a worker ID would be.
+func recordAdminEvent(ctx context.Context, repo domain.VideoEventRepository, actorID, videoID int, from, to domain.VideoStatus, attempt int, errorMessage string) {
+	event := &domain.VideoEvent{
+		VideoID:      videoID,
+		FromStatus:   from,
+		ToStatus:     to,
+		WorkerID:     fmt.Sprintf("user:%d", actorID),
+		Attempt:      max(attempt, 1),
+		ErrorMessage: errorMessage,
+	}
+	if err := repo.Append(event); err != nil {
+		slog.ErrorContext(ctx, "failed to record video event", "video_id", videoID, "error", err)
+	}
+}
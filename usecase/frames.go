@@ -0,0 +1,343 @@
+// usecase/frames.go
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log/slog"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// frameIndex reads the number a frame's filename ends with, as in
+// clip.mp4_0042.png. It falls back to the frame's position.
+func frameIndex(name string, position int) int {
+	base := strings.TrimSuffix(name, path.Ext(name))
+	if n, err := strconv.Atoi(base[strings.LastIndex(base, "_")+1:]); err == nil && n > 0 {
+		return n
+	}
+	return position
+}
+
+// newFrame describes a frame from its content, hashing all of it but only
+// decoding the image header.
+func newFrame(name, framePath string, size int64, r io.Reader, position int, fps float64) (domain.Frame, error) {
+	hasher := sha256.New()
+	r = io.TeeReader(r, hasher)
+	config, _, err := image.DecodeConfig(r)
+	if err == nil {
+		_, err = io.Copy(io.Discard, r)
+	}
+	if err != nil {
+		return domain.Frame{}, fmt.Errorf("failed to read frame %s: %w", name, err)
+	}
+	index := frameIndex(name, position)
+	return domain.Frame{
+		Index:     index,
+		Timestamp: time.Duration(float64(index-1) / fps * float64(time.Second)),
+		Name:      name,
+		Path:      framePath,
+		Size:      size,
+		Width:     config.Width,
+		Height:    config.Height,
+		Checksum:  hex.EncodeToString(hasher.Sum(nil)),
+	}, nil
+}
+
+// applyTimestamps replaces the nominal timestamps of freshly extracted
+// frames with the presentation times the processor reported for them.
+func applyTimestamps(frames []domain.Frame, timestamps []time.Duration) error {
+	if len(timestamps) != len(frames) {
+		return fmt.Errorf("extractor reported %d timestamps for %d frames", len(timestamps), len(frames))
+	}
+	for i := range frames {
+		if frames[i].Index < 1 || frames[i].Index > len(timestamps) {
+			return fmt.Errorf("frame %s is out of the extracted sequence", frames[i].Name)
+		}
+		frames[i].Timestamp = timestamps[frames[i].Index-1]
+	}
+	return nil
+}
+
+// indexFrames describes every frame of a video's artifact, reading only the
+// image headers.
+func indexFrames(storage domain.FileStorageService, video *domain.Video) ([]domain.Frame, error) {
+	fps := video.ExtractionSettings.OrDefault().FPS
+	var frames []domain.Frame
+	if video.ArtifactLayout.OrDefault() == domain.ArtifactLayoutFrames {
+		paths, err := storage.ListFrames(video.ProcessedFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list frames: %w", err)
+		}
+		for i, framePath := range paths {
+			file, err := storage.OpenFile(framePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open frame: %w", err)
+			}
+			frame, err := newFrame(file.Name, framePath, file.Size, file, i+1, fps)
+			file.Close()
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, frame)
+		}
+	} else {
+		file, err := storage.OpenFile(video.ProcessedFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open processed file: %w", err)
+		}
+		defer file.Close()
+		reader, err := openZip(file)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range reader.File {
+			if !isCompressedImage(f.Name) {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to open frame %s: %w", f.Name, err)
+			}
+			frame, err := newFrame(path.Base(f.Name), "", int64(f.UncompressedSize64), rc, i+1, fps)
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, frame)
+		}
+	}
+	sort.Slice(frames, func(i, j int) bool { return frames[i].Index < frames[j].Index })
+	return frames, nil
+}
+
+// loadFrames returns the recorded frames of a video's artifact. Artifacts
+// processed before frames were recorded are indexed once here.
+func loadFrames(ctx context.Context, repo domain.FrameRepository, storage domain.FileStorageService, video *domain.Video) ([]domain.Frame, error) {
+	frames, err := repo.FindByArtifact(video.ProcessedFilePath)
+	if err != nil || len(frames) > 0 {
+		return frames, err
+	}
+	if frames, err = indexFrames(storage, video); err != nil {
+		return nil, err
+	}
+	if err := repo.ReplaceFrames(video.ProcessedFilePath, frames); err != nil {
+		slog.WarnContext(ctx, "failed to record frames", "video_id", video.ID, "error", err)
+	}
+	return frames, nil
+}
+
+type ListFramesUseCase struct {
+	VideoRepo   domain.VideoRepository
+	Workspaces  domain.WorkspaceRepository
+	FileStorage domain.FileStorageService
+	Frames      domain.FrameRepository
+}
+
+func (uc *ListFramesUseCase) Execute(ctx context.Context, userID, videoID int) ([]domain.Frame, error) {
+	video, err := findViewableArtifact(uc.VideoRepo, uc.Workspaces, userID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	return loadFrames(ctx, uc.Frames, uc.FileStorage, video)
+}
+
+// FrameQuery picks one frame: the one at Index, or when Index is zero, the
+// one taken nearest to At.
+type FrameQuery struct {
+	Index int
+	At    time.Duration
+}
+
+type GetFrameUseCase struct {
+	VideoRepo   domain.VideoRepository
+	Workspaces  domain.WorkspaceRepository
+	FileStorage domain.FileStorageService
+	Frames      domain.FrameRepository
+}
+
+// Execute opens a single frame, without the rest of the artifact.
+// The caller is responsible for closing the returned file.
+func (uc *GetFrameUseCase) Execute(ctx context.Context, userID, videoID int, query FrameQuery) (*domain.Frame, *domain.StoredFile, error) {
+	video, err := findViewableArtifact(uc.VideoRepo, uc.Workspaces, userID, videoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	frames, err := loadFrames(ctx, uc.Frames, uc.FileStorage, video)
+	if err != nil {
+		return nil, nil, err
+	}
+	frame := pickFrame(frames, query)
+	if frame == nil {
+		return nil, nil, domain.ErrFrameNotFound
+	}
+	if frame.Path != "" {
+		file, err := uc.FileStorage.OpenFile(frame.Path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open frame: %w", err)
+		}
+		return frame, file, nil
+	}
+	file, err := uc.openZippedFrame(video, frame.Name)
+	return frame, file, err
+}
+
+// openZippedFrame reads one entry of a ZIP artifact into memory; frames are
+// small enough for that and it makes the result seekable.
+func (uc *GetFrameUseCase) openZippedFrame(video *domain.Video, name string) (*domain.StoredFile, error) {
+	artifact, err := uc.FileStorage.OpenFile(video.ProcessedFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open processed file: %w", err)
+	}
+	defer artifact.Close()
+	reader, err := openZip(artifact)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range reader.File {
+		if path.Base(f.Name) != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open frame: %w", err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read frame: %w", err)
+		}
+		return &domain.StoredFile{
+			ReadSeekCloser: nopCloser{bytes.NewReader(data)},
+			Name:           name,
+			Size:           int64(len(data)),
+			ModTime:        f.Modified,
+		}, nil
+	}
+	return nil, domain.ErrFileNotFound
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error { return nil }
+
+// pickFrame finds the frame a query asks for in frames sorted by index, and
+// so by timestamp. Ties on timestamp go to the earlier frame.
+func pickFrame(frames []domain.Frame, query FrameQuery) *domain.Frame {
+	if len(frames) == 0 {
+		return nil
+	}
+	if query.Index != 0 {
+		i, found := slices.BinarySearchFunc(frames, query.Index, func(f domain.Frame, index int) int { return f.Index - index })
+		if !found {
+			return nil
+		}
+		return &frames[i]
+	}
+	i := sort.Search(len(frames), func(i int) bool { return frames[i].Timestamp >= query.At })
+	if i == len(frames) || (i > 0 && query.At-frames[i-1].Timestamp <= frames[i].Timestamp-query.At) {
+		i--
+	}
+	return &frames[i]
+}
+
+type DownloadFramesUseCase struct {
+	VideoRepo   domain.VideoRepository
+	Workspaces  domain.WorkspaceRepository
+	FileStorage domain.FileStorageService
+	Frames      domain.FrameRepository
+}
+
+// Execute packs the selected frames of a video in format.
+// The caller is responsible for closing the returned download.
+func (uc *DownloadFramesUseCase) Execute(ctx context.Context, userID, videoID int, selection domain.FrameSelection, format domain.ArchiveFormat) (*ArtifactDownload, error) {
+	video, err := findViewableArtifact(uc.VideoRepo, uc.Workspaces, userID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	frames, err := loadFrames(ctx, uc.Frames, uc.FileStorage, video)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := selectFrames(frames, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &Archive{
+		Name:   fmt.Sprintf("%d_%s_frames_%d-%d.%s", video.UserID, path.Base(video.OriginalFilename), selected[0].Index, selected[len(selected)-1].Index, format),
+		Format: format,
+	}
+	if video.ArtifactLayout.OrDefault() == domain.ArtifactLayoutFrames {
+		paths := make([]string, len(selected))
+		for i, f := range selected {
+			paths[i] = f.Path
+		}
+		if archive.entries, archive.ModTime, err = frameEntries(uc.FileStorage, paths); err != nil {
+			return nil, err
+		}
+		return &ArtifactDownload{Archive: archive}, nil
+	}
+
+	file, err := uc.FileStorage.OpenFile(video.ProcessedFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open processed file: %w", err)
+	}
+	names := make(map[string]bool, len(selected))
+	for _, f := range selected {
+		names[f.Name] = true
+	}
+	if archive.entries, err = zipEntries(file, names); err != nil {
+		file.Close()
+		return nil, err
+	}
+	archive.ModTime = file.ModTime
+	return &ArtifactDownload{Archive: archive, source: file}, nil
+}
+
+// selectFrames applies a selection to frames sorted by index. Listed
+// indices must all exist; a range only has to overlap the frames.
+func selectFrames(frames []domain.Frame, selection domain.FrameSelection) ([]domain.Frame, error) {
+	var selected []domain.Frame
+	switch {
+	case len(selection.Indices) > 0 && (selection.From != 0 || selection.To != 0):
+		return nil, fmt.Errorf("%w: give either a range or a list of indices", domain.ErrInvalidFrameSelection)
+	case len(selection.Indices) > 0:
+		indices := slices.Clone(selection.Indices)
+		slices.Sort(indices)
+		for _, index := range slices.Compact(indices) {
+			frame := pickFrame(frames, FrameQuery{Index: index})
+			if frame == nil {
+				return nil, fmt.Errorf("%w: %d", domain.ErrFrameNotFound, index)
+			}
+			selected = append(selected, *frame)
+		}
+	case selection.From < 1 || (selection.To != 0 && selection.To < selection.From):
+		return nil, fmt.Errorf("%w: the range must start at 1 or later and not end before it starts", domain.ErrInvalidFrameSelection)
+	default:
+		for _, f := range frames {
+			if f.Index >= selection.From && (selection.To == 0 || f.Index <= selection.To) {
+				selected = append(selected, f)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, domain.ErrFrameNotFound
+		}
+	}
+	return selected, nil
+}
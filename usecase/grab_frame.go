@@ -0,0 +1,264 @@
+// usecase/grab_frame.go
+package usecase
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+const (
+	defaultGrabTimeout       = 10 * time.Second
+	defaultGrabsPerUser      = 2
+	defaultGrabCacheBytes    = 64 << 20
+	defaultMaxCachedGrabSize = 2 << 20
+	// MaxGrabWidth bounds the width a frame may be scaled to.
+	MaxGrabWidth = 4096
+)
+
+// GrabFrameQuery asks for the frame shown at At, scaled to Width pixels
+// (zero keeps the original size) and encoded as Format.
+type GrabFrameQuery struct {
+	At     time.Duration
+	Width  int
+	Format domain.ImageFormat
+}
+
+// GrabbedFrame is an image extracted from a video's source. Cached is set
+// when it was served without running the grabber.
+type GrabbedFrame struct {
+	Data   []byte
+	Format domain.ImageFormat
+	At     time.Duration
+	Cached bool
+}
+
+// GrabFrameUseCase extracts a frame from the stored upload while the client
+// waits, unlike the frames of the artifact, which are extracted at a fixed
+// rate by the worker. Each grab starts an ffmpeg process, so grabs are
+// bounded per user and in time, and their results are cached.
+type GrabFrameUseCase struct {
+	VideoRepo   domain.VideoRepository
+	Workspaces  domain.WorkspaceRepository
+	FileStorage domain.FileStorageService
+	Grabber     domain.FrameGrabber
+	// Outputs, when set, lets grabs fall back to the proxy rendition once
+	// the source is gone.
+	Outputs domain.OutputRepository
+	// Timeout bounds a single grab; it defaults to 10s.
+	Timeout time.Duration
+	// MaxPerUser bounds the grabs a user may have running; it defaults to 2.
+	MaxPerUser int
+	// CacheBytes bounds the total size of the cached frames; it defaults to
+	// 64 MiB.
+	CacheBytes int64
+	// MaxCachedFrameBytes is the largest frame that is cached; it defaults
+	// to 2 MiB. Bigger frames, such as full-width PNGs, are served but not
+	// kept.
+	MaxCachedFrameBytes int64
+
+	mu       sync.Mutex
+	inFlight map[int]int
+	cache    *frameCache
+}
+
+// Execute works on videos in any status, as long as their source or proxy
+// rendition is still stored. Results are cached by video, timestamp (to the millisecond),
+// width and format.
+func (uc *GrabFrameUseCase) Execute(ctx context.Context, userID, videoID int, query GrabFrameQuery) (*GrabbedFrame, error) {
+	if query.At < 0 || query.Width < 0 || query.Width > MaxGrabWidth {
+		return nil, fmt.Errorf("%w: t must be non-negative and w at most %d, or zero for the original width", domain.ErrInvalidFrameSelection, MaxGrabWidth)
+	}
+	video, err := findAccessibleVideo(uc.VideoRepo, uc.Workspaces, userID, videoID, domain.WorkspaceRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	query.At = query.At.Truncate(time.Millisecond)
+	key := frameCacheKey{videoID: videoID, at: query.At, width: query.Width, format: query.Format}
+	if data, ok := uc.cached(key); ok {
+		return &GrabbedFrame{Data: data, Format: query.Format, At: query.At, Cached: true}, nil
+	}
+
+	sourcePath, err := uc.sourcePath(video)
+	if err != nil {
+		return nil, err
+	}
+	if !uc.acquire(userID) {
+		return nil, domain.ErrTooManyFrameRequests
+	}
+	defer uc.release(userID)
+
+	timeout := uc.Timeout
+	if timeout <= 0 {
+		timeout = defaultGrabTimeout
+	}
+	grabCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	_, span := tracer.Start(grabCtx, "ffmpeg.grab_frame")
+	data, err := uc.Grabber.GrabFrame(grabCtx, sourcePath, query.At, query.Width, query.Format)
+	endSpan(span, err)
+	if err != nil {
+		if errors.Is(grabCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, domain.ErrFrameTimeout
+		}
+		return nil, err
+	}
+
+	uc.store(key, data)
+	return &GrabbedFrame{Data: data, Format: query.Format, At: query.At}, nil
+}
+
+// sourcePath finds the video to seek in: the upload, or failing that the
+// proxy rendition, which is smaller but has every frame. Deduplicated
+// videos have no upload of their own and use that of the video they were
+// reused from.
+func (uc *GrabFrameUseCase) sourcePath(video *domain.Video) (string, error) {
+	sourcePath := video.SourcePath
+	if sourcePath == "" && video.ReusedFromVideoID != 0 {
+		if source, err := uc.VideoRepo.FindByID(video.ReusedFromVideoID); err == nil {
+			sourcePath = source.SourcePath
+		}
+	}
+	candidates := []string{sourcePath}
+	if uc.Outputs != nil && video.ProcessedFilePath != "" {
+		outputs, err := uc.Outputs.FindByArtifact(video.ProcessedFilePath)
+		if err != nil {
+			return "", err
+		}
+		for _, output := range outputs {
+			if output.Kind == domain.OutputKindProxy {
+				candidates = append(candidates, output.Path)
+			}
+		}
+	}
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		file, err := uc.FileStorage.OpenFile(candidate)
+		if errors.Is(err, domain.ErrFileNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		file.Close()
+		return candidate, nil
+	}
+	return "", domain.ErrSourceUnavailable
+}
+
+func (uc *GrabFrameUseCase) acquire(userID int) bool {
+	limit := uc.MaxPerUser
+	if limit <= 0 {
+		limit = defaultGrabsPerUser
+	}
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.inFlight == nil {
+		uc.inFlight = make(map[int]int)
+	}
+	if uc.inFlight[userID] >= limit {
+		return false
+	}
+	uc.inFlight[userID]++
+	return true
+}
+
+func (uc *GrabFrameUseCase) release(userID int) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.inFlight[userID]--; uc.inFlight[userID] <= 0 {
+		delete(uc.inFlight, userID)
+	}
+}
+
+func (uc *GrabFrameUseCase) cached(key frameCacheKey) ([]byte, bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.cache == nil {
+		return nil, false
+	}
+	return uc.cache.get(key)
+}
+
+func (uc *GrabFrameUseCase) store(key frameCacheKey, data []byte) {
+	maxFrame := uc.MaxCachedFrameBytes
+	if maxFrame <= 0 {
+		maxFrame = defaultMaxCachedGrabSize
+	}
+	if int64(len(data)) > maxFrame {
+		return
+	}
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.cache == nil {
+		capacity := uc.CacheBytes
+		if capacity <= 0 {
+			capacity = defaultGrabCacheBytes
+		}
+		uc.cache = newFrameCache(capacity)
+	}
+	uc.cache.put(key, data)
+}
+
+type frameCacheKey struct {
+	videoID int
+	at      time.Duration
+	width   int
+	format  domain.ImageFormat
+}
+
+type frameCacheEntry struct {
+	key  frameCacheKey
+	data []byte
+}
+
+// frameCache is an LRU cache of grabbed frames holding at most capacity
+// bytes of image data. It is not safe for concurrent use.
+type frameCache struct {
+	capacity int64
+	size     int64
+	order    *list.List
+	entries  map[frameCacheKey]*list.Element
+}
+
+func newFrameCache(capacity int64) *frameCache {
+	return &frameCache{capacity: capacity, order: list.New(), entries: make(map[frameCacheKey]*list.Element)}
+}
+
+func (c *frameCache) get(key frameCacheKey) ([]byte, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*frameCacheEntry).data, true
+}
+
+func (c *frameCache) put(key frameCacheKey, data []byte) {
+	if int64(len(data)) > c.capacity {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*frameCacheEntry)
+		c.size += int64(len(data) - len(entry.data))
+		entry.data = data
+		c.order.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.order.PushFront(&frameCacheEntry{key: key, data: data})
+		c.size += int64(len(data))
+	}
+	for c.size > c.capacity {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*frameCacheEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= int64(len(entry.data))
+	}
+}
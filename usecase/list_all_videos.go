@@ -0,0 +1,29 @@
+// usecase/list_all_videos.go
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// MaxVideoPageSize caps how many videos one admin listing returns.
+const MaxVideoPageSize = 500
+
+type ListAllVideosUseCase struct {
+	VideoRepo domain.VideoRepository
+}
+
+// Execute lists videos across all users. Limit defaults to and is capped at
+// MaxVideoPageSize.
+func (uc *ListAllVideosUseCase) Execute(filter domain.VideoFilter) ([]domain.Video, error) {
+	if filter.Limit <= 0 || filter.Limit > MaxVideoPageSize {
+		filter.Limit = MaxVideoPageSize
+	}
+	filter.Offset = max(filter.Offset, 0)
+	videos, err := uc.VideoRepo.FindAll(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+	return videos, nil
+}
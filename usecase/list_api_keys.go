@@ -0,0 +1,21 @@
+// usecase/list_api_keys.go
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type ListAPIKeysUseCase struct {
+	Repo domain.APIKeyRepository
+}
+
+// Execute returns every key of userID, including revoked and expired ones.
+func (uc *ListAPIKeysUseCase) Execute(userID int) ([]domain.APIKey, error) {
+	keys, err := uc.Repo.FindByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
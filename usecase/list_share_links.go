@@ -0,0 +1,51 @@
+// usecase/list_share_links.go
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type ListShareLinksUseCase struct {
+	VideoRepo  domain.VideoRepository
+	Workspaces domain.WorkspaceRepository
+	Repo       domain.ShareLinkRepository
+}
+
+// Execute lists a video's share links, including expired and revoked ones.
+func (uc *ListShareLinksUseCase) Execute(userID, videoID int) ([]domain.ShareLink, error) {
+	if _, err := findAccessibleVideo(uc.VideoRepo, uc.Workspaces, userID, videoID, domain.WorkspaceRoleMember); err != nil {
+		return nil, err
+	}
+	links, err := uc.Repo.FindByVideoID(videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	return links, nil
+}
+
+type ListShareAccessesUseCase struct {
+	VideoRepo  domain.VideoRepository
+	Workspaces domain.WorkspaceRepository
+	Repo       domain.ShareLinkRepository
+}
+
+// Execute returns the access log of one of a video's share links.
+func (uc *ListShareAccessesUseCase) Execute(userID, videoID, linkID int) ([]domain.ShareAccess, error) {
+	if _, err := findAccessibleVideo(uc.VideoRepo, uc.Workspaces, userID, videoID, domain.WorkspaceRoleMember); err != nil {
+		return nil, err
+	}
+	link, err := uc.Repo.FindByID(linkID)
+	if err != nil {
+		return nil, err
+	}
+	if link.VideoID != videoID {
+		return nil, domain.ErrShareLinkNotFound
+	}
+	accesses, err := uc.Repo.FindAccesses(linkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share link accesses: %w", err)
+	}
+	return accesses, nil
+}
@@ -0,0 +1,27 @@
+// usecase/list_video_events.go
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type ListVideoEventsUseCase struct {
+	VideoRepo  domain.VideoRepository
+	EventRepo  domain.VideoEventRepository
+	Workspaces domain.WorkspaceRepository
+}
+
+// Execute returns the transition history of a video that userID owns or can
+// view through a workspace, oldest first.
+func (uc *ListVideoEventsUseCase) Execute(userID, videoID int) ([]domain.VideoEvent, error) {
+	if _, err := findAccessibleVideo(uc.VideoRepo, uc.Workspaces, userID, videoID, domain.WorkspaceRoleViewer); err != nil {
+		return nil, err
+	}
+	events, err := uc.EventRepo.FindByVideoID(videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list video events: %w", err)
+	}
+	return events, nil
+}
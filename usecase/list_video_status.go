@@ -0,0 +1,28 @@
+// usecase/list_video_status.go
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type ListVideoStatusUseCase struct {
+	VideoRepo domain.VideoRepository
+}
+
+// Execute lists the videos of workspaceID, whose membership the caller has
+// already checked, or the user's personal videos when workspaceID is zero.
+func (uc *ListVideoStatusUseCase) Execute(userID, workspaceID int) ([]domain.Video, error) {
+	var videos []domain.Video
+	var err error
+	if workspaceID != 0 {
+		videos, err = uc.VideoRepo.FindByWorkspaceID(workspaceID)
+	} else {
+		videos, err = uc.VideoRepo.FindByUserID(userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list video statuses: %w", err)
+	}
+	return videos, nil
+}
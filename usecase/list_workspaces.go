@@ -0,0 +1,22 @@
+// usecase/list_workspaces.go
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type ListWorkspacesUseCase struct {
+	Repo domain.WorkspaceRepository
+}
+
+// Execute returns the workspaces userID belongs to, with the user's role in
+// each.
+func (uc *ListWorkspacesUseCase) Execute(userID int) ([]domain.WorkspaceMembership, error) {
+	memberships, err := uc.Repo.FindByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	return memberships, nil
+}
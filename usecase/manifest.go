@@ -0,0 +1,89 @@
+// usecase/manifest.go
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+func saveManifest(storage domain.FileStorageService, outputDir string, manifest *domain.Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.SaveFile(storage.GetManifestPath(outputDir), data)
+}
+
+// readManifest reads the manifest stored with a video's artifact. It returns
+// domain.ErrFileNotFound for artifacts written before manifests existed.
+func readManifest(storage domain.FileStorageService, video *domain.Video) (*domain.Manifest, error) {
+	var r io.Reader
+	if video.ArtifactLayout.OrDefault() == domain.ArtifactLayoutFrames {
+		file, err := storage.OpenFile(storage.GetManifestPath(video.ProcessedFilePath))
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	} else {
+		artifact, err := storage.OpenFile(video.ProcessedFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open processed file: %w", err)
+		}
+		defer artifact.Close()
+		reader, err := openZip(artifact)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := reader.Open(domain.ManifestName)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, domain.ErrFileNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open manifest: %w", err)
+		}
+		defer entry.Close()
+		r = entry
+	}
+
+	var manifest domain.Manifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+type GetManifestUseCase struct {
+	VideoRepo   domain.VideoRepository
+	Workspaces  domain.WorkspaceRepository
+	FileStorage domain.FileStorageService
+}
+
+// Execute returns the manifest packed with a video's artifact. For artifacts
+// written before manifests existed, an equivalent one is built from the
+// frames, without the source size, which is no longer known.
+func (uc *GetManifestUseCase) Execute(ctx context.Context, userID, videoID int) (*domain.Manifest, error) {
+	video, err := findViewableArtifact(uc.VideoRepo, uc.Workspaces, userID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := readManifest(uc.FileStorage, video)
+	if !errors.Is(err, domain.ErrFileNotFound) {
+		return manifest, err
+	}
+
+	slog.InfoContext(ctx, "artifact has no manifest, building one", "video_id", videoID)
+	frames, err := indexFrames(uc.FileStorage, video)
+	if err != nil {
+		return nil, err
+	}
+	source := domain.ManifestSource{Filename: video.OriginalFilename, SHA256: video.ContentHash}
+	return domain.NewManifest(source, video.ExtractionSettings, frames, video.UpdatedAt), nil
+}
@@ -0,0 +1,36 @@
+// usecase/metrics.go
+package usecase
+
+import (
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type nopMetrics struct{}
+
+func (nopMetrics) UploadReceived(int64)           {}
+func (nopMetrics) JobStarted(time.Duration)       {}
+func (nopMetrics) JobFinished(domain.VideoStatus) {}
+func (nopMetrics) FFmpegDuration(time.Duration)   {}
+func (nopMetrics) ZipDuration(time.Duration)      {}
+func (nopMetrics) FramesExtracted(int)            {}
+func (nopMetrics) QueueReconnected()              {}
+
+// metricsOrNop lets use cases run without a metrics backend.
+func metricsOrNop(m domain.MetricsRecorder) domain.MetricsRecorder {
+	if m == nil {
+		return nopMetrics{}
+	}
+	return m
+}
+
+// countingWriter counts bytes written through it.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
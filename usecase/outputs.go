@@ -0,0 +1,102 @@
+// usecase/outputs.go
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// saveOutput stores data as a job's output called name.
+func saveOutput(storage domain.FileStorageService, outputDir string, kind domain.OutputKind, name, contentType string, data []byte) (domain.Output, error) {
+	outputPath := storage.GetOutputPath(outputDir, name)
+	if err := storage.SaveFile(outputPath, data); err != nil {
+		return domain.Output{}, fmt.Errorf("failed to save %s: %w", name, err)
+	}
+	sum := sha256.Sum256(data)
+	return domain.Output{
+		Kind:        kind,
+		Name:        name,
+		Path:        outputPath,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Checksum:    hex.EncodeToString(sum[:]),
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// recordOutput describes an output something else already wrote to
+// outputPath, such as ffmpeg.
+func recordOutput(storage domain.FileStorageService, kind domain.OutputKind, name, outputPath, contentType string) (domain.Output, error) {
+	file, err := storage.OpenFile(outputPath)
+	if err != nil {
+		return domain.Output{}, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	size := file.Size
+	file.Close()
+	checksum, err := checksumStoredFile(storage, outputPath)
+	if err != nil {
+		return domain.Output{}, fmt.Errorf("failed to checksum %s: %w", name, err)
+	}
+	return domain.Output{
+		Kind:        kind,
+		Name:        name,
+		Path:        outputPath,
+		ContentType: contentType,
+		Size:        size,
+		Checksum:    checksum,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+type ListOutputsUseCase struct {
+	VideoRepo  domain.VideoRepository
+	Workspaces domain.WorkspaceRepository
+	Outputs    domain.OutputRepository
+}
+
+// Execute lists the outputs produced next to a video's artifact; videos
+// processed without optional outputs have none.
+func (uc *ListOutputsUseCase) Execute(ctx context.Context, userID, videoID int) ([]domain.Output, error) {
+	video, err := findViewableArtifact(uc.VideoRepo, uc.Workspaces, userID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.Outputs.FindByArtifact(video.ProcessedFilePath)
+}
+
+type GetOutputUseCase struct {
+	VideoRepo   domain.VideoRepository
+	Workspaces  domain.WorkspaceRepository
+	FileStorage domain.FileStorageService
+	Outputs     domain.OutputRepository
+}
+
+// Execute opens one output of a video's artifact by name, with its checksum
+// set. The caller is responsible for closing the returned file.
+func (uc *GetOutputUseCase) Execute(ctx context.Context, userID, videoID int, name string) (*domain.Output, *domain.StoredFile, error) {
+	video, err := findViewableArtifact(uc.VideoRepo, uc.Workspaces, userID, videoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	outputs, err := uc.Outputs.FindByArtifact(video.ProcessedFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range outputs {
+		if outputs[i].Name != name {
+			continue
+		}
+		file, err := uc.FileStorage.OpenFile(outputs[i].Path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open output: %w", err)
+		}
+		file.Checksum = outputs[i].Checksum
+		return &outputs[i], file, nil
+	}
+	return nil, nil, domain.ErrOutputNotFound
+}
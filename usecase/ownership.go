@@ -0,0 +1,54 @@
+// usecase/ownership.go
+package usecase
+
+import (
+	"errors"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// findAccessibleVideo loads a video and checks that userID may act on it:
+// personal videos only by their uploader, workspace videos by members whose
+// role includes min. Every per-video use case goes through it so access is
+// checked before any other detail, such as the video's status, is revealed.
+// With no workspace repository only the uploader has access.
+func findAccessibleVideo(videos domain.VideoRepository, workspaces domain.WorkspaceRepository, userID, videoID int, min domain.WorkspaceRole) (*domain.Video, error) {
+	video, err := videos.FindByID(videoID)
+	if err != nil {
+		return nil, err
+	}
+	if video.WorkspaceID == 0 || workspaces == nil {
+		if video.UserID != userID {
+			return nil, domain.ErrAccessDenied
+		}
+		return video, nil
+	}
+
+	member, err := workspaces.FindMember(video.WorkspaceID, userID)
+	if errors.Is(err, domain.ErrNotWorkspaceMember) {
+		return nil, domain.ErrAccessDenied
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.Includes(min) {
+		return nil, domain.ErrInsufficientWorkspaceRole
+	}
+	return video, nil
+}
+
+// findViewableArtifact returns a video userID may view whose processing
+// finished with an artifact.
+func findViewableArtifact(videos domain.VideoRepository, workspaces domain.WorkspaceRepository, userID, videoID int) (*domain.Video, error) {
+	video, err := findAccessibleVideo(videos, workspaces, userID, videoID, domain.WorkspaceRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	if video.Status != domain.VideoStatusCompleted {
+		return nil, domain.ErrVideoNotCompleted
+	}
+	if video.ProcessedFilePath == "" {
+		return nil, domain.ErrProcessedFileEmpty
+	}
+	return video, nil
+}
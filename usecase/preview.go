@@ -0,0 +1,45 @@
+// usecase/preview.go
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+const (
+	// previewAttempts bounds how many times a preview is rendered to fit
+	// its byte budget.
+	previewAttempts = 4
+	minPreviewWidth = 64
+)
+
+// renderPreview renders a preview and, while it is over budget, renders it
+// again smaller: first narrower, scaled by the square root of the overshoot
+// since size grows with area, then at a lower frame rate once the width
+// reaches its minimum.
+func renderPreview(ctx context.Context, renderer domain.PreviewRenderer, videoPath string, settings domain.PreviewSettings) ([]byte, error) {
+	settings = settings.OrDefault()
+	for attempt := 1; ; attempt++ {
+		data, err := renderer.RenderPreview(ctx, videoPath, settings)
+		if err != nil {
+			return nil, err
+		}
+		if settings.MaxBytes == 0 || int64(len(data)) <= settings.MaxBytes {
+			return data, nil
+		}
+		if attempt == previewAttempts {
+			return nil, fmt.Errorf("%w: %d bytes over %d after %d attempts", domain.ErrPreviewTooLarge, len(data), settings.MaxBytes, attempt)
+		}
+
+		scale := math.Sqrt(float64(settings.MaxBytes)/float64(len(data))) * 0.9
+		if settings.Width > minPreviewWidth {
+			// Even widths keep chroma subsampling happy.
+			settings.Width = max(minPreviewWidth, int(float64(settings.Width)*scale)&^1)
+		} else {
+			settings.FPS = max(1, math.Floor(settings.FPS*scale*scale))
+		}
+	}
+}
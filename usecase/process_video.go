@@ -0,0 +1,392 @@
+// usecase/process_video.go
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/logging"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ProcessVideoUseCase is the worker side of the pipeline: it turns a queued
+// upload into a ZIP of extracted frames and records the outcome.
+type ProcessVideoUseCase struct {
+	VideoRepo    domain.VideoRepository
+	EventRepo    domain.VideoEventRepository
+	FileStorage  domain.FileStorageService
+	Processor    domain.VideoProcessor
+	Notification domain.NotificationService
+	Metrics      domain.MetricsRecorder
+	// WorkerID identifies this worker in the video's event history.
+	WorkerID string
+	// ArtifactLayout decides whether frames are zipped (the default) or kept
+	// in storage and packed when downloaded, which halves peak disk use.
+	ArtifactLayout domain.ArtifactLayout
+	// Frames is optional; when set the frames of each artifact are indexed
+	// as soon as it is stored.
+	Frames domain.FrameRepository
+	// Outputs records the optional outputs below; it is required when any
+	// of them is enabled.
+	Outputs domain.OutputRepository
+	// Sprites, when set, adds sprite sheets of the frames and a WebVTT track
+	// for hover previews.
+	Sprites *domain.SpriteSettings
+	// Preview, when set, adds an animated preview rendered from the upload
+	// by PreviewRenderer.
+	Preview         *domain.PreviewSettings
+	PreviewRenderer domain.PreviewRenderer
+	// Audio, when set, adds the audio track and its waveform as outputs
+	// and stores the track's loudness on the video, all by AudioProcessor.
+	Audio          *domain.AudioSettings
+	AudioProcessor domain.AudioProcessor
+	// Subtitles, when set, probes each upload for subtitle streams and
+	// records them on the video. With SubtitleFormat set, text streams are
+	// also extracted as outputs tagged with their language.
+	Subtitles      domain.SubtitleExtractor
+	SubtitleFormat domain.SubtitleFormat
+	// Proxy, when set, adds an H.264/AAC MP4 rendition of the upload for
+	// playback in browsers, made by Transcoder.
+	Proxy      *domain.ProxySettings
+	Transcoder domain.Transcoder
+	// KeepFrames keeps the extracted frames next to the ZIP artifact, which
+	// doubles the space a result takes. By default they are removed once
+	// zipped, and single frames are read out of the ZIP. It has no effect
+	// with the frames layout.
+	KeepFrames bool
+	// KeepSource keeps the upload after a successful job so frames can be
+	// grabbed from it on demand; it is then removed with the video. By
+	// default it is removed as soon as the job completes.
+	KeepSource bool
+}
+
+// job is one attempt at processing a video, holding the claim every write
+// to the video names and the output directory private to the attempt.
+type job struct {
+	domain.VideoProcessingMessage
+	claim     int
+	outputDir string
+}
+
+func (uc *ProcessVideoUseCase) Execute(ctx context.Context, message domain.VideoProcessingMessage) {
+	ctx, span := tracer.Start(ctx, "video.process")
+	span.SetAttributes(attribute.Int("video.id", message.VideoStatusID), attribute.Int("video.attempt", message.Attempt))
+	defer span.End()
+	ctx = logging.With(ctx,
+		slog.Int("video_id", message.VideoStatusID),
+		slog.Int("user_id", message.UserID),
+		slog.Int("attempt", max(message.Attempt, 1)),
+		slog.String("worker_id", uc.WorkerID),
+	)
+
+	metrics := metricsOrNop(uc.Metrics)
+	started := time.Now()
+	msg, err := uc.claim(ctx, message, started.Sub(message.ProcessingStarted))
+	if errors.Is(err, domain.ErrInvalidTransition) {
+		// Another delivery of the same job already claimed it.
+		slog.InfoContext(ctx, "skipping duplicate delivery", "error", err)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update video status", "error", err)
+		return
+	}
+	metrics.JobStarted(started.Sub(msg.ProcessingStarted))
+	uc.Notification.SendNotification(ctx, msg.UserID, msg.OriginalFilename, string(domain.VideoStatusProcessing), "Seu vídeo está sendo processado.")
+
+	outputDir, err := uc.FileStorage.OutputDir(msg.UserID, msg.VideoStatusID, msg.claim)
+	if err != nil {
+		uc.fail(ctx, msg, started, fmt.Sprintf("Failed to prepare output directory: %v", err), "Falha ao preparar diretório de saída.")
+		return
+	}
+	msg.outputDir = outputDir
+
+	framePattern := uc.FileStorage.GenerateFramePattern(outputDir, msg.OriginalFilename)
+	ffmpegStarted := time.Now()
+	_, ffmpegSpan := tracer.Start(ctx, "ffmpeg.extract_frames")
+	timestamps, err := uc.Processor.ExtractFrames(msg.VideoPath, framePattern, msg.Settings.OrDefault())
+	endSpan(ffmpegSpan, err)
+	metrics.FFmpegDuration(time.Since(ffmpegStarted))
+	if err != nil {
+		errorMessage := fmt.Sprintf("FFmpeg failed to extract frames: %v", err)
+		uc.fail(ctx, msg, started, errorMessage, fmt.Sprintf("Falha ao processar vídeo: %s", errorMessage))
+		return
+	}
+
+	layout := uc.ArtifactLayout.OrDefault()
+	extracted := &domain.Video{ProcessedFilePath: outputDir, ArtifactLayout: domain.ArtifactLayoutFrames, ExtractionSettings: msg.Settings}
+	frames, err := indexFrames(uc.FileStorage, extracted)
+	if err == nil && len(frames) == 0 {
+		err = errors.New("no frames extracted")
+	}
+	if err == nil {
+		err = applyTimestamps(frames, timestamps)
+	}
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed to read extracted frames: %v", err)
+		uc.fail(ctx, msg, started, errorMessage, fmt.Sprintf("Falha ao ler os frames extraídos: %s", errorMessage))
+		return
+	}
+	manifest := domain.NewManifest(uc.manifestSource(ctx, msg), msg.Settings, frames, time.Now())
+	if err := saveManifest(uc.FileStorage, outputDir, manifest); err != nil {
+		errorMessage := fmt.Sprintf("Failed to write manifest: %v", err)
+		uc.fail(ctx, msg, started, errorMessage, fmt.Sprintf("Falha ao gravar o manifesto: %s", errorMessage))
+		return
+	}
+
+	outputs := uc.renderOutputs(ctx, msg, outputDir, frames)
+
+	artifactPath := outputDir
+	frameCount := len(frames)
+	if layout == domain.ArtifactLayoutZip {
+		artifactPath = uc.FileStorage.GetProcessedFilePath(outputDir, msg.UserID, msg.OriginalFilename)
+		zipStarted := time.Now()
+		_, zipSpan := tracer.Start(ctx, "zip.frames")
+		frameCount, err = uc.FileStorage.ZipFrames(outputDir, msg.OriginalFilename, artifactPath)
+		zipSpan.SetAttributes(attribute.Int("video.frames", frameCount))
+		endSpan(zipSpan, err)
+		metrics.ZipDuration(time.Since(zipStarted))
+		if err != nil {
+			errorMessage := fmt.Sprintf("Failed to zip frames: %v", err)
+			uc.fail(ctx, msg, started, errorMessage, fmt.Sprintf("Falha ao compactar frames: %s", errorMessage))
+			return
+		}
+
+		// Without a checksum the first download computes it instead.
+		if checksum, err := checksumStoredFile(uc.FileStorage, artifactPath); err != nil {
+			slog.WarnContext(ctx, "could not checksum artifact", "error", err)
+		} else if err := uc.VideoRepo.SetProcessedFileChecksum(msg.VideoStatusID, msg.claim, checksum); err != nil {
+			slog.WarnContext(ctx, "could not store artifact checksum", "error", err)
+		}
+		if err := uc.FileStorage.DeleteFile(uc.FileStorage.GetManifestPath(outputDir)); err != nil {
+			slog.WarnContext(ctx, "could not remove manifest", "error", err)
+		}
+		if !uc.KeepFrames {
+			if err := uc.FileStorage.DeleteFrames(outputDir, msg.OriginalFilename); err != nil {
+				slog.WarnContext(ctx, "could not remove extracted frames", "error", err)
+			}
+			// The frames now only exist inside the ZIP.
+			for i := range frames {
+				frames[i].Path = ""
+			}
+		}
+	}
+	metrics.FramesExtracted(frameCount)
+
+	if err := uc.VideoRepo.SetArtifactLayout(msg.VideoStatusID, msg.claim, layout); err != nil {
+		uc.fail(ctx, msg, started, fmt.Sprintf("Failed to record artifact layout: %v", err), "Falha ao registrar o resultado do processamento.")
+		return
+	}
+	if uc.Frames != nil {
+		uc.recordFrames(ctx, artifactPath, frames)
+	}
+	if uc.Outputs != nil {
+		if err := uc.Outputs.ReplaceOutputs(artifactPath, outputs); err != nil {
+			slog.WarnContext(ctx, "could not record outputs", "error", err)
+		}
+	}
+
+	if err := uc.finish(ctx, msg, domain.VideoStatusCompleted, artifactPath, "", time.Since(started)); err != nil {
+		logFinishError(ctx, err)
+		if errors.Is(err, domain.ErrClaimLost) {
+			uc.discard(ctx, msg, artifactPath)
+		}
+		metrics.JobFinished(domain.VideoStatusFailed)
+		return
+	}
+	// Only now: a job that lost its claim must leave the source to the
+	// attempt that took over.
+	if !uc.KeepSource {
+		if err := uc.FileStorage.DeleteFile(msg.VideoPath); err != nil {
+			slog.WarnContext(ctx, "could not remove uploaded video", "error", err)
+		}
+	}
+	metrics.JobFinished(domain.VideoStatusCompleted)
+	slog.InfoContext(ctx, "video processed", "frames", frameCount, "duration_ms", time.Since(started).Milliseconds())
+	uc.Notification.SendNotification(ctx, msg.UserID, msg.OriginalFilename, string(domain.VideoStatusCompleted), completedNotification(msg.OriginalFilename, layout, frameCount))
+}
+
+// completedNotification tells the user what they can download, which
+// depends on how the frames were stored.
+func completedNotification(originalFilename string, layout domain.ArtifactLayout, frameCount int) string {
+	if layout == domain.ArtifactLayoutFrames {
+		return fmt.Sprintf("Seu vídeo '%s' foi processado com sucesso! Os %d frames estão disponíveis para download, avulsos ou em um arquivo ZIP ou TAR.", originalFilename, frameCount)
+	}
+	return fmt.Sprintf("Seu vídeo '%s' foi processado com sucesso! O arquivo ZIP com os %d frames está disponível para download.", originalFilename, frameCount)
+}
+
+// recordFrames indexes the frames of a new artifact. On failure the old
+// index is dropped so the first frame request indexes the artifact again.
+func (uc *ProcessVideoUseCase) recordFrames(ctx context.Context, artifactPath string, frames []domain.Frame) {
+	if err := uc.Frames.ReplaceFrames(artifactPath, frames); err != nil {
+		slog.WarnContext(ctx, "could not record frames", "error", err)
+		if err := uc.Frames.DeleteByArtifact(artifactPath); err != nil {
+			slog.WarnContext(ctx, "could not drop stale frames", "error", err)
+		}
+	}
+}
+
+// renderOutputs produces the optional outputs enabled on the worker from the
+// frames still in storage. They are extras next to the frames, so a failure
+// is logged and the job goes on without that output.
+func (uc *ProcessVideoUseCase) renderOutputs(ctx context.Context, msg job, outputDir string, frames []domain.Frame) []domain.Output {
+	var outputs []domain.Output
+	if uc.Sprites != nil {
+		_, span := tracer.Start(ctx, "sprites.render")
+		frameInterval := time.Duration(float64(time.Second) / msg.Settings.OrDefault().FPS)
+		sprites, err := renderSprites(uc.FileStorage, outputDir, frames, *uc.Sprites, frameInterval)
+		endSpan(span, err)
+		if err != nil {
+			slog.WarnContext(ctx, "could not render sprite sheets", "error", err)
+		}
+		outputs = append(outputs, sprites...)
+	}
+	if uc.Preview != nil {
+		previewCtx, span := tracer.Start(ctx, "ffmpeg.render_preview")
+		output, err := uc.renderPreview(previewCtx, msg, outputDir)
+		endSpan(span, err)
+		if err != nil {
+			slog.WarnContext(ctx, "could not render preview", "error", err)
+		} else {
+			outputs = append(outputs, output)
+		}
+	}
+	if uc.Audio != nil {
+		outputs = append(outputs, uc.processAudio(ctx, msg, outputDir)...)
+	}
+	if uc.Subtitles != nil {
+		outputs = append(outputs, uc.processSubtitles(ctx, msg, outputDir)...)
+	}
+	if uc.Proxy != nil {
+		proxyCtx, span := tracer.Start(ctx, "ffmpeg.transcode_proxy")
+		output, err := uc.transcodeProxy(proxyCtx, msg, outputDir)
+		endSpan(span, err)
+		if err != nil {
+			slog.WarnContext(ctx, "could not transcode proxy", "error", err)
+		} else {
+			outputs = append(outputs, output)
+		}
+	}
+	return outputs
+}
+
+func (uc *ProcessVideoUseCase) renderPreview(ctx context.Context, msg job, outputDir string) (domain.Output, error) {
+	settings := uc.Preview.OrDefault()
+	data, err := renderPreview(ctx, uc.PreviewRenderer, msg.VideoPath, settings)
+	if err != nil {
+		return domain.Output{}, err
+	}
+	name := "preview." + string(settings.Format)
+	return saveOutput(uc.FileStorage, outputDir, domain.OutputKindPreview, name, settings.Format.ContentType(), data)
+}
+
+// manifestSource describes the upload being processed. What cannot be
+// read is left out of the manifest rather than failing the job.
+func (uc *ProcessVideoUseCase) manifestSource(ctx context.Context, msg job) domain.ManifestSource {
+	source := domain.ManifestSource{Filename: msg.OriginalFilename}
+	if file, err := uc.FileStorage.OpenFile(msg.VideoPath); err != nil {
+		slog.WarnContext(ctx, "could not stat uploaded video", "error", err)
+	} else {
+		source.SizeBytes = file.Size
+		file.Close()
+	}
+	if video, err := uc.VideoRepo.FindByID(msg.VideoStatusID); err != nil {
+		slog.WarnContext(ctx, "could not load video", "error", err)
+	} else {
+		source.SHA256 = video.ContentHash
+	}
+	return source
+}
+
+func (uc *ProcessVideoUseCase) fail(ctx context.Context, msg job, started time.Time, errorMessage, notification string) {
+	slog.ErrorContext(ctx, "video processing failed", "error", errorMessage)
+	metricsOrNop(uc.Metrics).JobFinished(domain.VideoStatusFailed)
+	uc.discard(ctx, msg, "")
+	if err := uc.finish(ctx, msg, domain.VideoStatusFailed, "", errorMessage, time.Since(started)); err != nil {
+		logFinishError(ctx, err)
+		return
+	}
+	uc.Notification.SendNotification(ctx, msg.UserID, msg.OriginalFilename, string(domain.VideoStatusFailed), notification)
+}
+
+// discard removes what an attempt that won't complete produced, including
+// the index of its artifact when one was recorded. Its files are in a
+// directory of its own, so nothing another attempt made goes with them.
+func (uc *ProcessVideoUseCase) discard(ctx context.Context, msg job, artifactPath string) {
+	if msg.outputDir == "" {
+		return
+	}
+	if artifactPath != "" && uc.Frames != nil {
+		if err := uc.Frames.DeleteByArtifact(artifactPath); err != nil {
+			slog.WarnContext(ctx, "could not drop frame index", "error", err)
+		}
+	}
+	if artifactPath != "" && uc.Outputs != nil {
+		if err := uc.Outputs.DeleteByArtifact(artifactPath); err != nil {
+			slog.WarnContext(ctx, "could not drop outputs", "error", err)
+		}
+	}
+	if err := uc.FileStorage.DeleteOutputDir(msg.outputDir); err != nil {
+		slog.WarnContext(ctx, "could not remove output directory", "error", err)
+	}
+}
+
+// claim moves the video to PROCESSING for this worker. waited is how long
+// the video spent PENDING.
+func (uc *ProcessVideoUseCase) claim(ctx context.Context, message domain.VideoProcessingMessage, waited time.Duration) (job, error) {
+	_, span := tracer.Start(ctx, "db.update video_status")
+	span.SetAttributes(attribute.String("video.status.from", string(domain.VideoStatusPending)), attribute.String("video.status.to", string(domain.VideoStatusProcessing)))
+	claim, err := uc.VideoRepo.ClaimVideo(message.VideoStatusID)
+	endSpan(span, err)
+	msg := job{VideoProcessingMessage: message, claim: claim}
+	if err != nil {
+		return msg, err
+	}
+	uc.recordTransition(ctx, msg, domain.VideoStatusPending, domain.VideoStatusProcessing, "", waited)
+	return msg, nil
+}
+
+// finish moves the video out of PROCESSING unless the job lost its claim.
+// duration is how long the video spent PROCESSING.
+func (uc *ProcessVideoUseCase) finish(ctx context.Context, msg job, to domain.VideoStatus, processedFilePath, errorMessage string, duration time.Duration) error {
+	_, span := tracer.Start(ctx, "db.update video_status")
+	span.SetAttributes(attribute.String("video.status.from", string(domain.VideoStatusProcessing)), attribute.String("video.status.to", string(to)))
+	err := uc.VideoRepo.FinishClaim(msg.VideoStatusID, msg.claim, to, processedFilePath, errorMessage)
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+	uc.recordTransition(ctx, msg, domain.VideoStatusProcessing, to, errorMessage, duration)
+	return nil
+}
+
+func logFinishError(ctx context.Context, err error) {
+	if errors.Is(err, domain.ErrClaimLost) {
+		slog.WarnContext(ctx, "dropping the result of a superseded job", "error", err)
+		return
+	}
+	slog.ErrorContext(ctx, "failed to update video status", "error", err)
+}
+
+// recordTransition logs a status change and appends the matching history
+// event.
+func (uc *ProcessVideoUseCase) recordTransition(ctx context.Context, msg job, from, to domain.VideoStatus, errorMessage string, duration time.Duration) {
+	slog.InfoContext(ctx, "video status updated", "from", string(from), "to", string(to))
+
+	event := &domain.VideoEvent{
+		VideoID:      msg.VideoStatusID,
+		FromStatus:   from,
+		ToStatus:     to,
+		WorkerID:     uc.WorkerID,
+		Attempt:      max(msg.Attempt, 1),
+		Duration:     duration,
+		ErrorMessage: errorMessage,
+	}
+	if err := uc.EventRepo.Append(event); err != nil {
+		slog.ErrorContext(ctx, "failed to record video event", "error", err)
+	}
+}
@@ -0,0 +1,19 @@
+// usecase/proxy.go
+package usecase
+
+import (
+	"context"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// transcodeProxy renders the web-playable rendition of the upload. A failed
+// run's partial file is removed.
+func (uc *ProcessVideoUseCase) transcodeProxy(ctx context.Context, msg job, outputDir string) (domain.Output, error) {
+	outputPath := uc.FileStorage.GetOutputPath(outputDir, domain.ProxyName)
+	if err := uc.Transcoder.TranscodeProxy(ctx, msg.VideoPath, outputPath, uc.Proxy.OrDefault()); err != nil {
+		_ = uc.FileStorage.DeleteFile(outputPath)
+		return domain.Output{}, err
+	}
+	return recordOutput(uc.FileStorage, domain.OutputKindProxy, domain.ProxyName, outputPath, "video/mp4")
+}
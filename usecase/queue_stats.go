@@ -0,0 +1,16 @@
+// usecase/queue_stats.go
+package usecase
+
+import (
+	"context"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type GetQueueStatsUseCase struct {
+	Queue domain.QueueInspector
+}
+
+func (uc *GetQueueStatsUseCase) Execute(ctx context.Context) (domain.QueueStats, error) {
+	return uc.Queue.QueueStats(ctx)
+}
@@ -0,0 +1,169 @@
+// usecase/refresh_token.go
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// RefreshTokenPrefix starts every refresh token, telling them apart from
+// API keys and JWTs.
+const RefreshTokenPrefix = "vpr_"
+
+// DefaultRefreshTokenTTL is how long a session lasts when
+// IssueRefreshTokenUseCase.TTL is not set.
+const DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+type IssueRefreshTokenOutput struct {
+	Token *domain.RefreshToken
+	// Secret is the plaintext token. It is returned only here and cannot be
+	// recovered later.
+	Secret string
+}
+
+type IssueRefreshTokenUseCase struct {
+	Repo domain.RefreshTokenRepository
+	TTL  time.Duration
+}
+
+// Execute starts a session for subject. sessionID is that of the access
+// token making the request, if it was minted from a refresh token; such
+// tokens can't start another session, or a session could be extended
+// forever without the identity provider.
+func (uc *IssueRefreshTokenUseCase) Execute(subject domain.TokenSubject, sessionID string) (*IssueRefreshTokenOutput, error) {
+	if sessionID != "" {
+		return nil, domain.ErrRefreshTokenChained
+	}
+	ttl := uc.TTL
+	if ttl <= 0 {
+		ttl = DefaultRefreshTokenTTL
+	}
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token, secret, err := newRefreshToken(subject, hex.EncodeToString(raw), time.Now().Add(ttl))
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.Repo.Create(token); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return &IssueRefreshTokenOutput{Token: token, Secret: secret}, nil
+}
+
+func newRefreshToken(subject domain.TokenSubject, familyID string, expiresAt time.Time) (*domain.RefreshToken, string, error) {
+	secret, err := newSecret(RefreshTokenPrefix)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return &domain.RefreshToken{
+		FamilyID:     familyID,
+		TokenSubject: subject,
+		TokenHash:    hashSecret(secret),
+		ExpiresAt:    expiresAt,
+	}, secret, nil
+}
+
+type RefreshAccessTokenOutput struct {
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	// RefreshToken replaces the one exchanged, which no longer works.
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+type RefreshAccessTokenUseCase struct {
+	Repo   domain.RefreshTokenRepository
+	Signer domain.AccessTokenSigner
+}
+
+// Execute exchanges a refresh token for a new access token and rotates it.
+// Presenting a token that was already exchanged means two parties hold it,
+// so its whole family is revoked. Every refusal is ErrInvalidRefreshToken.
+func (uc *RefreshAccessTokenUseCase) Execute(ctx context.Context, secret string) (*RefreshAccessTokenOutput, error) {
+	if !strings.HasPrefix(secret, RefreshTokenPrefix) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	current, err := uc.Repo.FindByHash(hashSecret(secret))
+	if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if current.RotatedAt != nil && current.RevokedAt == nil {
+		uc.revokeReused(ctx, current)
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if !current.Usable(time.Now()) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	// Signed first: once rotated, the old token is spent whatever happens.
+	accessToken, accessExpiresAt, err := uc.Signer.SignAccessToken(current.TokenSubject, current.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+	next, nextSecret, err := newRefreshToken(current.TokenSubject, current.FamilyID, current.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.Repo.Rotate(current.ID, next); err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) {
+			// Another request exchanged it first.
+			uc.revokeReused(ctx, current)
+		}
+		return nil, err
+	}
+	return &RefreshAccessTokenOutput{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          nextSecret,
+		RefreshTokenExpiresAt: next.ExpiresAt,
+	}, nil
+}
+
+func (uc *RefreshAccessTokenUseCase) revokeReused(ctx context.Context, token *domain.RefreshToken) {
+	slog.WarnContext(ctx, "refresh token reused, revoking its session", "user_id", token.UserID, "refresh_token_id", token.ID)
+	if err := uc.Repo.RevokeFamily(token.FamilyID); err != nil {
+		slog.ErrorContext(ctx, "failed to revoke reused refresh token family", "user_id", token.UserID, "error", err)
+	}
+}
+
+type RevokeRefreshTokenUseCase struct {
+	Repo domain.RefreshTokenRepository
+}
+
+// Execute ends the session of a refresh token. Unknown tokens are ignored,
+// so callers can't probe which tokens exist.
+func (uc *RevokeRefreshTokenUseCase) Execute(secret string) error {
+	if !strings.HasPrefix(secret, RefreshTokenPrefix) {
+		return nil
+	}
+	token, err := uc.Repo.FindByHash(hashSecret(secret))
+	if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return uc.Repo.RevokeFamily(token.FamilyID)
+}
+
+type RevokeUserRefreshTokensUseCase struct {
+	Repo domain.RefreshTokenRepository
+}
+
+// Execute ends every session of userID.
+func (uc *RevokeUserRefreshTokensUseCase) Execute(userID int) error {
+	return uc.Repo.RevokeByUserID(userID)
+}
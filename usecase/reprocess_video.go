@@ -0,0 +1,23 @@
+// usecase/reprocess_video.go
+package usecase
+
+import (
+	"context"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// ReprocessVideoUseCase lets users requeue their own failed videos, or
+// those of a workspace where they are at least a member.
+type ReprocessVideoUseCase struct {
+	VideoRepo  domain.VideoRepository
+	Workspaces domain.WorkspaceRepository
+	Requeue    *RequeueVideoUseCase
+}
+
+func (uc *ReprocessVideoUseCase) Execute(ctx context.Context, userID, videoID int) (*domain.Video, error) {
+	if _, err := findAccessibleVideo(uc.VideoRepo, uc.Workspaces, userID, videoID, domain.WorkspaceRoleMember); err != nil {
+		return nil, err
+	}
+	return uc.Requeue.Execute(ctx, userID, videoID)
+}
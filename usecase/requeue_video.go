@@ -0,0 +1,74 @@
+// usecase/requeue_video.go
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type RequeueVideoUseCase struct {
+	VideoRepo    domain.VideoRepository
+	EventRepo    domain.VideoEventRepository
+	MessageQueue domain.MessageQueueService
+	// QuotaRepo is optional. The video's owner must be under the same
+	// active-jobs limit as for an upload; users without a stored quota get
+	// DefaultQuota.
+	QuotaRepo    domain.QuotaRepository
+	DefaultQuota domain.UserQuota
+}
+
+// Execute moves a FAILED video back to PENDING and publishes it again with
+// the next attempt number.
+func (uc *RequeueVideoUseCase) Execute(ctx context.Context, actorID, videoID int) (*domain.Video, error) {
+	video, err := uc.VideoRepo.FindByID(videoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.ValidateTransition(videoID, video.Status, domain.VideoStatusPending); err != nil {
+		return nil, err
+	}
+	if video.SourcePath == "" {
+		return nil, domain.ErrSourceUnavailable
+	}
+
+	events, err := uc.EventRepo.FindByVideoID(videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load video events: %w", err)
+	}
+	attempt := 1
+	for _, e := range events {
+		attempt = max(attempt, e.Attempt+1)
+	}
+
+	quota, err := quotaFor(uc.QuotaRepo, uc.DefaultQuota, video.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.VideoRepo.RequeueWithinQuota(videoID, quota.MaxActiveJobs); err != nil {
+		return nil, err
+	}
+	recordAdminEvent(ctx, uc.EventRepo, actorID, videoID, domain.VideoStatusFailed, domain.VideoStatusPending, attempt, "")
+
+	message := domain.VideoProcessingMessage{
+		UserID:            video.UserID,
+		VideoPath:         video.SourcePath,
+		OriginalFilename:  video.OriginalFilename,
+		ProcessingStarted: time.Now(),
+		VideoStatusID:     video.ID,
+		Attempt:           attempt,
+		Settings:          video.ExtractionSettings,
+	}
+	if err := uc.MessageQueue.PublishVideoProcessing(ctx, message); err != nil {
+		// Put the video back so it can be requeued again.
+		if rollbackErr := uc.VideoRepo.TransitionStatus(videoID, domain.VideoStatusPending, domain.VideoStatusFailed, "", "Requeue failed"); rollbackErr != nil {
+			slog.ErrorContext(ctx, "failed to roll back requeue", "video_id", videoID, "error", rollbackErr)
+		}
+		return nil, fmt.Errorf("failed to queue video for processing: %w", err)
+	}
+	slog.InfoContext(ctx, "video requeued", "video_id", videoID, "actor_id", actorID, "attempt", attempt)
+	return uc.VideoRepo.FindByID(videoID)
+}
@@ -0,0 +1,16 @@
+// usecase/resolve_workspace.go
+package usecase
+
+import (
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type ResolveWorkspaceUseCase struct {
+	Repo domain.WorkspaceRepository
+}
+
+// Execute returns userID's membership in the workspace a request selected,
+// or ErrNotWorkspaceMember.
+func (uc *ResolveWorkspaceUseCase) Execute(userID, workspaceID int) (*domain.WorkspaceMember, error) {
+	return uc.Repo.FindMember(workspaceID, userID)
+}
@@ -0,0 +1,14 @@
+// usecase/revoke_api_key.go
+package usecase
+
+import "github.com/vitovidale/video-processor-service/domain"
+
+type RevokeAPIKeyUseCase struct {
+	Repo domain.APIKeyRepository
+}
+
+// Execute revokes a key owned by userID. Keys of other users are reported as
+// not found so their IDs can't be probed.
+func (uc *RevokeAPIKeyUseCase) Execute(userID, keyID int) error {
+	return uc.Repo.Revoke(userID, keyID)
+}
@@ -0,0 +1,22 @@
+// usecase/revoke_share_link.go
+package usecase
+
+import (
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type RevokeShareLinkUseCase struct {
+	VideoRepo  domain.VideoRepository
+	Workspaces domain.WorkspaceRepository
+	Repo       domain.ShareLinkRepository
+}
+
+// Execute revokes a share link of videoID. Revoking twice is not an error.
+func (uc *RevokeShareLinkUseCase) Execute(userID, videoID, linkID int) error {
+	if _, err := findAccessibleVideo(uc.VideoRepo, uc.Workspaces, userID, videoID, domain.WorkspaceRoleMember); err != nil {
+		return err
+	}
+	return uc.Repo.Revoke(videoID, linkID, time.Now())
+}
@@ -0,0 +1,151 @@
+// usecase/sprites.go
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"strings"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// ThumbnailTrackName is the WebVTT track that maps time ranges to regions of
+// the sprite sheets.
+const ThumbnailTrackName = "thumbnails.vtt"
+
+// sampleFrames picks one frame per interval from frames sorted by
+// timestamp; a zero interval keeps them all.
+func sampleFrames(frames []domain.Frame, interval time.Duration) []domain.Frame {
+	if interval <= 0 {
+		return frames
+	}
+	var sampled []domain.Frame
+	var next time.Duration
+	for _, f := range frames {
+		if f.Timestamp >= next {
+			sampled = append(sampled, f)
+			next = f.Timestamp.Truncate(interval) + interval
+		}
+	}
+	return sampled
+}
+
+// renderSprites tiles the frames of a job, still in storage, into JPEG
+// sprite sheets and writes the WebVTT track players use to show them while
+// scrubbing. frameInterval is the time between extracted frames, used to end
+// the last cue.
+func renderSprites(storage domain.FileStorageService, outputDir string, frames []domain.Frame, settings domain.SpriteSettings, frameInterval time.Duration) ([]domain.Output, error) {
+	settings = settings.OrDefault()
+	sampled := sampleFrames(frames, settings.Interval)
+	if len(sampled) == 0 {
+		return nil, fmt.Errorf("no frames to tile")
+	}
+	perSheet := settings.Columns * settings.Rows
+
+	var outputs []domain.Output
+	var track strings.Builder
+	track.WriteString("WEBVTT\n")
+	var sheet *image.RGBA
+	var sheetName string
+	width, height := settings.Width, settings.Height
+	for i, f := range sampled {
+		img, err := decodeStoredImage(storage, f.Path)
+		if err != nil {
+			return nil, err
+		}
+		if height == 0 {
+			bounds := img.Bounds()
+			height = max(1, (width*bounds.Dy()+bounds.Dx()/2)/bounds.Dx())
+		}
+
+		slot := i % perSheet
+		if slot == 0 {
+			rows := min(settings.Rows, (len(sampled)-i+settings.Columns-1)/settings.Columns)
+			columns := min(settings.Columns, len(sampled)-i)
+			sheet = image.NewRGBA(image.Rect(0, 0, columns*width, rows*height))
+			sheetName = fmt.Sprintf("sprites_%03d.jpg", i/perSheet+1)
+		}
+		x, y := slot%settings.Columns*width, slot/settings.Columns*height
+		drawScaled(sheet, image.Rect(x, y, x+width, y+height), img)
+
+		end := f.Timestamp + max(settings.Interval, frameInterval)
+		if i+1 < len(sampled) {
+			end = sampled[i+1].Timestamp
+		}
+		fmt.Fprintf(&track, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n", vttTimestamp(f.Timestamp), vttTimestamp(end), sheetName, x, y, width, height)
+
+		if slot == perSheet-1 || i == len(sampled)-1 {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, sheet, &jpeg.Options{Quality: 80}); err != nil {
+				return nil, fmt.Errorf("failed to encode %s: %w", sheetName, err)
+			}
+			output, err := saveOutput(storage, outputDir, domain.OutputKindSpriteSheet, sheetName, "image/jpeg", buf.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, output)
+		}
+	}
+
+	output, err := saveOutput(storage, outputDir, domain.OutputKindThumbnailTrack, ThumbnailTrackName, "text/vtt; charset=utf-8", []byte(track.String()))
+	if err != nil {
+		return nil, err
+	}
+	return append(outputs, output), nil
+}
+
+func decodeStoredImage(storage domain.FileStorageService, imagePath string) (image.Image, error) {
+	file, err := storage.OpenFile(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open frame: %w", err)
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode frame %s: %w", file.Name, err)
+	}
+	return img, nil
+}
+
+// drawScaled draws src scaled into r of dst, averaging the source pixels
+// each destination pixel covers.
+func drawScaled(dst *image.RGBA, r image.Rectangle, src image.Image) {
+	sb := src.Bounds()
+	rgba, ok := src.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(sb)
+		draw.Draw(rgba, sb, src, sb.Min, draw.Src)
+	}
+	for y := 0; y < r.Dy(); y++ {
+		y0 := sb.Min.Y + y*sb.Dy()/r.Dy()
+		y1 := max(sb.Min.Y+(y+1)*sb.Dy()/r.Dy(), y0+1)
+		for x := 0; x < r.Dx(); x++ {
+			x0 := sb.Min.X + x*sb.Dx()/r.Dx()
+			x1 := max(sb.Min.X+(x+1)*sb.Dx()/r.Dx(), x0+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					p := rgba.Pix[rgba.PixOffset(sx, sy):]
+					for c := range sum {
+						sum[c] += int(p[c])
+					}
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			d := dst.Pix[dst.PixOffset(r.Min.X+x, r.Min.Y+y):]
+			for c := range sum {
+				d[c] = uint8(sum[c] / n)
+			}
+		}
+	}
+}
+
+// vttTimestamp formats d as hh:mm:ss.ttt.
+func vttTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
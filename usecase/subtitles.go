@@ -0,0 +1,66 @@
+// usecase/subtitles.go
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+// languageTag matches the language tags safe to put in an output name,
+// such as "por" or "pt-BR".
+var languageTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+// subtitleName names the output of a subtitle stream after its index and,
+// when it has a usable one, its language: "subtitles_1.por.vtt".
+func subtitleName(stream domain.SubtitleStream, format domain.SubtitleFormat) string {
+	if languageTag.MatchString(stream.Language) {
+		return fmt.Sprintf("subtitles_%d.%s.%s", stream.Index, stream.Language, format)
+	}
+	return fmt.Sprintf("subtitles_%d.%s", stream.Index, format)
+}
+
+// processSubtitles records the subtitle streams of the upload on the video
+// and, when a format is set, extracts each text stream as an output.
+// Streams stored as images are listed but not extracted.
+func (uc *ProcessVideoUseCase) processSubtitles(ctx context.Context, msg job, outputDir string) []domain.Output {
+	probeCtx, span := tracer.Start(ctx, "ffprobe.subtitles")
+	streams, err := uc.Subtitles.ProbeSubtitles(probeCtx, msg.VideoPath)
+	endSpan(span, err)
+	if err != nil {
+		slog.WarnContext(ctx, "could not probe subtitle streams", "error", err)
+		return nil
+	}
+	if err := uc.VideoRepo.SetSubtitleStreams(msg.VideoStatusID, msg.claim, streams); err != nil {
+		slog.WarnContext(ctx, "could not record subtitle streams", "error", err)
+	}
+	if uc.SubtitleFormat == "" {
+		return nil
+	}
+
+	var outputs []domain.Output
+	for _, stream := range streams {
+		if !stream.IsText() {
+			slog.InfoContext(ctx, "skipping subtitle stream that is not text", "index", stream.Index, "codec", stream.Codec)
+			continue
+		}
+		extractCtx, span := tracer.Start(ctx, "ffmpeg.extract_subtitles")
+		data, err := uc.Subtitles.ExtractSubtitles(extractCtx, msg.VideoPath, stream.Index, uc.SubtitleFormat)
+		endSpan(span, err)
+		if err != nil {
+			slog.WarnContext(ctx, "could not extract subtitles", "index", stream.Index, "error", err)
+			continue
+		}
+		output, err := saveOutput(uc.FileStorage, outputDir, domain.OutputKindSubtitles, subtitleName(stream, uc.SubtitleFormat), uc.SubtitleFormat.ContentType(), data)
+		if err != nil {
+			slog.WarnContext(ctx, "could not save subtitles", "index", stream.Index, "error", err)
+			continue
+		}
+		output.Language = stream.Language
+		outputs = append(outputs, output)
+	}
+	return outputs
+}
@@ -2,71 +2,353 @@
 package usecase
 
 import (
-    "fmt"
-    "io"
-    "time"
-    "log" // Temporário, para logs
-    "your_project/domain"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/vitovidale/video-processor-service/domain"
+	"github.com/vitovidale/video-processor-service/logging"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DedupScope controls which earlier jobs an upload may reuse when the same
+// content was already processed with the same settings.
+type DedupScope string
+
+const (
+	DedupScopeOff    DedupScope = "off"
+	DedupScopeUser   DedupScope = "user"
+	DedupScopeGlobal DedupScope = "global"
 )
 
+// DefaultIdempotencyWindow is how long an Idempotency-Key is remembered when
+// UploadVideoUseCase.IdempotencyWindow is not set.
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// DefaultIdempotencyClaimTimeout is how long a request may hold an
+// Idempotency-Key without recording a video when
+// UploadVideoUseCase.IdempotencyClaimTimeout is not set. The key is claimed
+// once the upload is received, so only the insert and publish run under it.
+const DefaultIdempotencyClaimTimeout = time.Minute
+
 type UploadVideoInput struct {
-    UserID         int
-    FileContent    io.Reader
-    OriginalFilename string
+	UserID int
+	// WorkspaceID is the active workspace, whose membership the caller has
+	// already checked, or zero for a personal upload.
+	WorkspaceID      int
+	FileContent      io.Reader
+	OriginalFilename string
+	// IdempotencyKey is optional; retries carrying the same key replay the
+	// original response instead of creating a new job.
+	IdempotencyKey string
 }
 
 type UploadVideoOutput struct {
-    Message       string
-    Filename      string
-    VideoStatusID int
+	Message       string
+	Filename      string
+	VideoStatusID int
+	ContentHash   string
+	// Replayed is true when the output comes from an earlier request with
+	// the same idempotency key.
+	Replayed bool
+	// Deduplicated is true when an earlier job's artifact was reused and no
+	// processing was queued.
+	Deduplicated bool
 }
 
 type UploadVideoUseCase struct {
-    VideoRepo      domain.VideoRepository
-    MessageQueue   domain.MessageQueueService
-    FileStorage    domain.FileStorageService
+	VideoRepo         domain.VideoRepository
+	EventRepo         domain.VideoEventRepository
+	IdempotencyRepo   domain.IdempotencyRepository
+	MessageQueue      domain.MessageQueueService
+	FileStorage       domain.FileStorageService
+	IdempotencyWindow time.Duration
+	// IdempotencyClaimTimeout bounds how long retries are answered with
+	// ErrIdempotencyKeyInProgress; after it the key of a request that
+	// crashed is taken over.
+	IdempotencyClaimTimeout time.Duration
+	// DedupScope defaults to DedupScopeOff when empty.
+	DedupScope DedupScope
+	Metrics    domain.MetricsRecorder
+	// QuotaRepo is optional. Users without a stored quota get DefaultQuota.
+	QuotaRepo    domain.QuotaRepository
+	DefaultQuota domain.UserQuota
 }
 
-func (uc *UploadVideoUseCase) Execute(input UploadVideoInput) (*UploadVideoOutput, error) {
-    // 1. Salvar o arquivo recebido
-    uploadDir := "./uploads" // Esta lógica deve estar no FileStorageService
-    if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
-        os.Mkdir(uploadDir, 0755)
-    }
-    uniqueFilename := fmt.Sprintf("%d_%s_%d%s", input.UserID, time.Now().Format("20060102150405"), time.Now().UnixNano(), filepath.Ext(input.OriginalFilename))
-    filePath := filepath.Join(uploadDir, uniqueFilename)
-
-    if err := c.SaveUploadedFile(file, filePath); err != nil { // C.SaveUploadedFile não pode ser aqui
-        return nil, fmt.Errorf("failed to save video file: %w", err)
-    }
-
-    // 2. Criar status inicial no DB
-    video := &domain.Video{
-        UserID:           input.UserID,
-        OriginalFilename: input.OriginalFilename,
-        Status:           domain.VideoStatusPending,
-    }
-    if err := uc.VideoRepo.Save(video); err != nil {
-        return nil, fmt.Errorf("failed to record video status: %w", err)
-    }
-
-    // 3. Publicar mensagem na fila
-    message := domain.VideoProcessingMessage{
-        UserID:            input.UserID,
-        VideoPath:         filePath, // Caminho do arquivo salvo
-        OriginalFilename:  input.OriginalFilename,
-        ProcessingStarted: time.Now(),
-        VideoStatusID:     video.ID,
-    }
-    if err := uc.MessageQueue.PublishVideoProcessing(message); err != nil {
-        return nil, fmt.Errorf("failed to queue video for processing: %w", err)
-    }
-
-    log.Printf(" [x] Sent message for video: %s (Status ID: %d)", input.OriginalFilename, video.ID)
-
-    return &UploadVideoOutput{
-        Message:       "Video uploaded and queued for processing",
-        Filename:      input.OriginalFilename,
-        VideoStatusID: video.ID,
-    }, nil
+func (uc *UploadVideoUseCase) Execute(ctx context.Context, input UploadVideoInput) (*UploadVideoOutput, error) {
+	ctx = logging.With(ctx, slog.Int("user_id", input.UserID))
+
+	quota, err := uc.loadQuota(input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// Without a key there is nothing to replay, so an upload over the
+	// active-jobs limit is refused before it is received. Retries with a
+	// key must reach the replay first; the limit is enforced for good when
+	// the video is saved.
+	if input.IdempotencyKey == "" {
+		if err := uc.checkActiveJobs(input.UserID, quota); err != nil {
+			return nil, err
+		}
+	}
+
+	// 1. Salvar o arquivo recebido
+	content := input.FileContent
+	if quota.MaxUploadBytes > 0 {
+		// One byte over the limit is enough to tell it was exceeded.
+		content = io.LimitReader(content, quota.MaxUploadBytes+1)
+	}
+	hasher := sha256.New()
+	counter := &countingWriter{}
+	uniqueFilename := fmt.Sprintf("%d_%s_%d%s", input.UserID, time.Now().Format("20060102150405"), time.Now().UnixNano(), filepath.Ext(input.OriginalFilename))
+	filePath, err := uc.FileStorage.SaveUploadedFile(io.TeeReader(content, io.MultiWriter(hasher, counter)), uniqueFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save video file: %w", err)
+	}
+	if quota.MaxUploadBytes > 0 && counter.n > quota.MaxUploadBytes {
+		uc.FileStorage.DeleteFile(filePath)
+		return nil, domain.ErrUploadTooLarge
+	}
+	contentHash := hex.EncodeToString(hasher.Sum(nil))
+	metricsOrNop(uc.Metrics).UploadReceived(counter.n)
+
+	if input.IdempotencyKey != "" {
+		replay, err := uc.reserveIdempotencyKey(input, contentHash)
+		if replay != nil || err != nil {
+			uc.FileStorage.DeleteFile(filePath)
+			return replay, err
+		}
+	}
+
+	output, err := uc.enqueue(ctx, input, filePath, contentHash, quota.MaxActiveJobs)
+	if err != nil {
+		uc.FileStorage.DeleteFile(filePath)
+	}
+	if input.IdempotencyKey != "" {
+		if err != nil {
+			uc.IdempotencyRepo.Release(input.UserID, input.IdempotencyKey)
+		} else if err := uc.IdempotencyRepo.Complete(&domain.IdempotencyRecord{
+			UserID:        input.UserID,
+			Key:           input.IdempotencyKey,
+			VideoStatusID: output.VideoStatusID,
+			Message:       output.Message,
+			Deduplicated:  output.Deduplicated,
+		}); err != nil {
+			slog.WarnContext(ctx, "failed to store idempotency key", "video_id", output.VideoStatusID, "error", err)
+		}
+	}
+	return output, err
+}
+
+// loadQuota returns the quota that applies to userID.
+func (uc *UploadVideoUseCase) loadQuota(userID int) (domain.UserQuota, error) {
+	return quotaFor(uc.QuotaRepo, uc.DefaultQuota, userID)
+}
+
+// checkActiveJobs fails with ErrQuotaExceeded when the user already has too
+// many unfinished videos. It is only an early answer: uploads racing it are
+// stopped by VideoRepository.SaveWithinQuota.
+func (uc *UploadVideoUseCase) checkActiveJobs(userID int, quota domain.UserQuota) error {
+	if quota.MaxActiveJobs <= 0 {
+		return nil
+	}
+	active, err := uc.VideoRepo.CountActiveByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if active >= quota.MaxActiveJobs {
+		return domain.ErrQuotaExceeded
+	}
+	return nil
+}
+
+// reserveIdempotencyKey claims the input's key. It returns a replayed output
+// when an earlier request with the same payload already succeeded.
+func (uc *UploadVideoUseCase) reserveIdempotencyKey(input UploadVideoInput, contentHash string) (*UploadVideoOutput, error) {
+	window := uc.IdempotencyWindow
+	if window <= 0 {
+		window = DefaultIdempotencyWindow
+	}
+	// Keys are scoped per user, not per workspace, so the workspace is part
+	// of the payload: the same upload into another workspace is a different
+	// request.
+	payload := input.OriginalFilename + "\n" + contentHash
+	if input.WorkspaceID != 0 {
+		payload = fmt.Sprintf("workspace %d\n%s", input.WorkspaceID, payload)
+	}
+	requestHash := sha256.Sum256([]byte(payload))
+	record := &domain.IdempotencyRecord{
+		UserID:      input.UserID,
+		Key:         input.IdempotencyKey,
+		RequestHash: hex.EncodeToString(requestHash[:]),
+		Filename:    input.OriginalFilename,
+	}
+
+	claimTimeout := uc.IdempotencyClaimTimeout
+	if claimTimeout <= 0 {
+		claimTimeout = DefaultIdempotencyClaimTimeout
+	}
+	existing, err := uc.IdempotencyRepo.Reserve(record, window, claimTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check idempotency key: %w", err)
+	}
+	if existing == nil {
+		return nil, nil
+	}
+	if existing.RequestHash != record.RequestHash {
+		return nil, domain.ErrIdempotencyKeyReused
+	}
+	if existing.VideoStatusID == 0 {
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+	message := existing.Message
+	if message == "" {
+		// Recorded before responses were stored, when only queued uploads
+		// were.
+		message = "Video uploaded and queued for processing"
+	}
+	return &UploadVideoOutput{
+		Message:       message,
+		Filename:      existing.Filename,
+		VideoStatusID: existing.VideoStatusID,
+		ContentHash:   contentHash,
+		Replayed:      true,
+		Deduplicated:  existing.Deduplicated,
+	}, nil
+}
+
+// findReusable returns a completed video whose artifact can stand in for a
+// new job over the same content, or nil.
+func (uc *UploadVideoUseCase) findReusable(ctx context.Context, userID int, contentHash string, settings domain.ExtractionSettings) *domain.Video {
+	var scopeUserID int
+	switch uc.DedupScope {
+	case DedupScopeUser:
+		scopeUserID = userID
+	case DedupScopeGlobal:
+		scopeUserID = 0
+	default:
+		return nil
+	}
+	video, err := uc.VideoRepo.FindCompletedByContentHash(contentHash, settings, scopeUserID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrVideoNotFound) {
+			slog.WarnContext(ctx, "deduplication lookup failed, processing normally", "error", err)
+		}
+		return nil
+	}
+	return video
+}
+
+// enqueue records and queues a job for the upload, unless the user has
+// maxActive unfinished videos, or reuses an earlier artifact, which does not
+// count against the limit.
+func (uc *UploadVideoUseCase) enqueue(ctx context.Context, input UploadVideoInput, filePath, contentHash string, maxActive int) (*UploadVideoOutput, error) {
+	settings := domain.DefaultExtractionSettings
+	if reusable := uc.findReusable(ctx, input.UserID, contentHash, settings); reusable != nil {
+		return uc.reuse(ctx, input, filePath, contentHash, reusable)
+	}
+
+	// 2. Criar status inicial no DB
+	video := &domain.Video{
+		UserID:             input.UserID,
+		WorkspaceID:        input.WorkspaceID,
+		OriginalFilename:   input.OriginalFilename,
+		Status:             domain.VideoStatusPending,
+		ContentHash:        contentHash,
+		ExtractionSettings: settings,
+		SourcePath:         filePath,
+	}
+	_, span := tracer.Start(ctx, "db.insert video")
+	err := uc.VideoRepo.SaveWithinQuota(video, maxActive)
+	span.SetAttributes(attribute.Int("video.id", video.ID))
+	endSpan(span, err)
+	if errors.Is(err, domain.ErrQuotaExceeded) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record video status: %w", err)
+	}
+	if err := uc.EventRepo.Append(&domain.VideoEvent{VideoID: video.ID, ToStatus: domain.VideoStatusPending, Attempt: 1}); err != nil {
+		slog.WarnContext(ctx, "failed to record upload event", "video_id", video.ID, "error", err)
+	}
+
+	// 3. Publicar mensagem na fila
+	message := domain.VideoProcessingMessage{
+		UserID:            input.UserID,
+		VideoPath:         filePath,
+		OriginalFilename:  input.OriginalFilename,
+		ProcessingStarted: time.Now(),
+		VideoStatusID:     video.ID,
+		Attempt:           1,
+		Settings:          settings,
+	}
+	publishCtx, span := tracer.Start(ctx, "queue.publish video_processing")
+	span.SetAttributes(attribute.Int("video.id", video.ID))
+	err = uc.MessageQueue.PublishVideoProcessing(publishCtx, message)
+	endSpan(span, err)
+	if err != nil {
+		// No job will ever pick the video up; fail it so it stops counting
+		// against the active-jobs limit. Its upload is deleted by Execute.
+		if failErr := uc.VideoRepo.TransitionStatus(video.ID, domain.VideoStatusPending, domain.VideoStatusFailed, "", "Failed to queue for processing"); failErr != nil {
+			slog.ErrorContext(ctx, "failed to fail unqueued video", "video_id", video.ID, "error", failErr)
+		}
+		return nil, fmt.Errorf("failed to queue video for processing: %w", err)
+	}
+
+	slog.InfoContext(ctx, "video queued for processing", "video_id", video.ID)
+
+	return &UploadVideoOutput{
+		Message:       "Video uploaded and queued for processing",
+		Filename:      input.OriginalFilename,
+		VideoStatusID: video.ID,
+		ContentHash:   contentHash,
+	}, nil
+}
+
+// reuse records a new job that is completed from the start, pointing at the
+// artifact of source, and discards the upload.
+func (uc *UploadVideoUseCase) reuse(ctx context.Context, input UploadVideoInput, filePath, contentHash string, source *domain.Video) (*UploadVideoOutput, error) {
+	video := &domain.Video{
+		UserID:                input.UserID,
+		WorkspaceID:           input.WorkspaceID,
+		OriginalFilename:      input.OriginalFilename,
+		Status:                domain.VideoStatusCompleted,
+		ProcessedFilePath:     source.ProcessedFilePath,
+		ProcessedFileChecksum: source.ProcessedFileChecksum,
+		ArtifactLayout:        source.ArtifactLayout,
+		ContentHash:           contentHash,
+		ExtractionSettings:    source.ExtractionSettings,
+		ReusedFromVideoID:     source.ID,
+		Audio:                 source.Audio,
+		SubtitleStreams:       source.SubtitleStreams,
+	}
+	_, span := tracer.Start(ctx, "db.insert video")
+	span.SetAttributes(attribute.Int("video.reused_from", source.ID))
+	err := uc.VideoRepo.Save(video)
+	endSpan(span, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record video status: %w", err)
+	}
+	if err := uc.EventRepo.Append(&domain.VideoEvent{VideoID: video.ID, ToStatus: domain.VideoStatusCompleted, Attempt: 1}); err != nil {
+		slog.WarnContext(ctx, "failed to record upload event", "video_id", video.ID, "error", err)
+	}
+	if err := uc.FileStorage.DeleteFile(filePath); err != nil {
+		slog.WarnContext(ctx, "could not remove duplicate upload", "video_id", video.ID, "error", err)
+	}
+
+	slog.InfoContext(ctx, "identical video already processed, reusing result", "video_id", video.ID, "reused_from_video_id", source.ID)
+
+	return &UploadVideoOutput{
+		Message:       "Identical video already processed; reusing existing result",
+		Filename:      input.OriginalFilename,
+		VideoStatusID: video.ID,
+		ContentHash:   contentHash,
+		Deduplicated:  true,
+	}, nil
 }
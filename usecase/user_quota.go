@@ -0,0 +1,56 @@
+// usecase/user_quota.go
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type GetUserQuotaUseCase struct {
+	QuotaRepo    domain.QuotaRepository
+	DefaultQuota domain.UserQuota
+}
+
+// Execute returns the quota in effect for userID, which is DefaultQuota
+// unless one was stored.
+func (uc *GetUserQuotaUseCase) Execute(userID int) (*domain.UserQuota, error) {
+	quota, err := uc.QuotaRepo.Get(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quota: %w", err)
+	}
+	if quota == nil {
+		quota = &domain.UserQuota{UserID: userID, MaxActiveJobs: uc.DefaultQuota.MaxActiveJobs, MaxUploadBytes: uc.DefaultQuota.MaxUploadBytes}
+	}
+	return quota, nil
+}
+
+type SetUserQuotaUseCase struct {
+	QuotaRepo domain.QuotaRepository
+}
+
+func (uc *SetUserQuotaUseCase) Execute(quota domain.UserQuota) (*domain.UserQuota, error) {
+	if quota.MaxActiveJobs < 0 || quota.MaxUploadBytes < 0 {
+		return nil, domain.ErrInvalidQuota
+	}
+	if err := uc.QuotaRepo.Set(&quota); err != nil {
+		return nil, fmt.Errorf("failed to store quota: %w", err)
+	}
+	return &quota, nil
+}
+
+// quotaFor returns the quota stored for userID, or fallback when there is
+// none or no repository.
+func quotaFor(repo domain.QuotaRepository, fallback domain.UserQuota, userID int) (domain.UserQuota, error) {
+	if repo == nil {
+		return fallback, nil
+	}
+	stored, err := repo.Get(userID)
+	if err != nil {
+		return fallback, fmt.Errorf("failed to load quota: %w", err)
+	}
+	if stored != nil {
+		return *stored, nil
+	}
+	return fallback, nil
+}
@@ -0,0 +1,107 @@
+// usecase/workspace_members.go
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/vitovidale/video-processor-service/domain"
+)
+
+type ListWorkspaceMembersUseCase struct {
+	Repo domain.WorkspaceRepository
+}
+
+// Execute lists the members of workspaceID, which only its members may see.
+func (uc *ListWorkspaceMembersUseCase) Execute(actorID, workspaceID int) ([]domain.WorkspaceMember, error) {
+	if _, err := uc.Repo.FindMember(workspaceID, actorID); err != nil {
+		return nil, err
+	}
+	members, err := uc.Repo.ListMembers(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace members: %w", err)
+	}
+	return members, nil
+}
+
+type SetWorkspaceMemberUseCase struct {
+	Repo domain.WorkspaceRepository
+}
+
+// Execute adds userID to workspaceID with role, or changes the role of an
+// existing member. Only owners may do it, and the last owner can't be
+// demoted.
+func (uc *SetWorkspaceMemberUseCase) Execute(actorID, workspaceID, userID int, role domain.WorkspaceRole) (*domain.WorkspaceMember, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("%w: invalid user ID", domain.ErrInvalidWorkspaceRequest)
+	}
+	if _, err := domain.ParseWorkspaceRole(string(role)); err != nil {
+		return nil, err
+	}
+	if err := requireWorkspaceOwner(uc.Repo, workspaceID, actorID); err != nil {
+		return nil, err
+	}
+	if role != domain.WorkspaceRoleOwner {
+		if err := ensureAnotherOwner(uc.Repo, workspaceID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	member := &domain.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: role}
+	if err := uc.Repo.SetMember(member); err != nil {
+		return nil, fmt.Errorf("failed to store workspace member: %w", err)
+	}
+	return member, nil
+}
+
+type RemoveWorkspaceMemberUseCase struct {
+	Repo domain.WorkspaceRepository
+}
+
+// Execute removes userID from workspaceID. Owners may remove anyone and
+// members may leave, as long as an owner remains.
+func (uc *RemoveWorkspaceMemberUseCase) Execute(actorID, workspaceID, userID int) error {
+	if actorID != userID {
+		if err := requireWorkspaceOwner(uc.Repo, workspaceID, actorID); err != nil {
+			return err
+		}
+	}
+	if err := ensureAnotherOwner(uc.Repo, workspaceID, userID); err != nil {
+		return err
+	}
+	return uc.Repo.RemoveMember(workspaceID, userID)
+}
+
+func requireWorkspaceOwner(repo domain.WorkspaceRepository, workspaceID, userID int) error {
+	member, err := repo.FindMember(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role != domain.WorkspaceRoleOwner {
+		return domain.ErrInsufficientWorkspaceRole
+	}
+	return nil
+}
+
+// ensureAnotherOwner fails with ErrLastWorkspaceOwner when userID is the
+// only owner of workspaceID.
+func ensureAnotherOwner(repo domain.WorkspaceRepository, workspaceID, userID int) error {
+	members, err := repo.ListMembers(workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to list workspace members: %w", err)
+	}
+	isOwner, others := false, 0
+	for _, m := range members {
+		if m.Role != domain.WorkspaceRoleOwner {
+			continue
+		}
+		if m.UserID == userID {
+			isOwner = true
+		} else {
+			others++
+		}
+	}
+	if isOwner && others == 0 {
+		return domain.ErrLastWorkspaceOwner
+	}
+	return nil
+}